@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// MembershipConfig holds the membership settings, read from the environment.
+type MembershipConfig struct {
+	// RenewInterval is how often memberships that ran out are renewed or expired.
+	RenewInterval time.Duration
+}
+
+func LoadMembershipConfig() MembershipConfig {
+	return MembershipConfig{
+		RenewInterval: getEnvDuration("MEMBERSHIP_RENEW_INTERVAL", time.Hour),
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// PaymentConfig holds the payment gateway settings, read from the environment.
+type PaymentConfig struct {
+	// Gateway picks the payment provider: "midtrans", or "fake" for local development.
+	Gateway string
+	// ServerKey signs and verifies payment notifications. Midtrans also uses it to authenticate API calls.
+	ServerKey string
+	// Production switches Midtrans from the sandbox to the production environment.
+	Production bool
+	// MidtransAPIURL overrides the base URL of the Midtrans status API, for a local stub.
+	MidtransAPIURL string
+	// ReconcileInterval is how often pending deposits are checked with the gateway.
+	ReconcileInterval time.Duration
+	// ReconcileAfter is how old a pending deposit must be before the gateway is asked about it.
+	ReconcileAfter time.Duration
+	// ExpireAfter is how old a deposit unknown to the gateway must be before it is expired.
+	ExpireAfter time.Duration
+	// FakeBaseURL is where the fake gateway serves its payment pages.
+	FakeBaseURL string
+	// FakeWebhookURL is where the fake gateway posts its notifications.
+	FakeWebhookURL string
+}
+
+func LoadPaymentConfig() PaymentConfig {
+	return PaymentConfig{
+		Gateway:           getEnvString("PAYMENT_GATEWAY", "midtrans"),
+		ServerKey:         os.Getenv("MIDTRANS_SERVER_KEY"),
+		Production:        getEnvString("MIDTRANS_ENVIRONMENT", "sandbox") == "production",
+		MidtransAPIURL:    os.Getenv("MIDTRANS_API_URL"),
+		ReconcileInterval: getEnvDuration("PAYMENT_RECONCILE_INTERVAL", 10*time.Minute),
+		ReconcileAfter:    getEnvDuration("PAYMENT_RECONCILE_AFTER", 15*time.Minute),
+		ExpireAfter:       getEnvDuration("PAYMENT_EXPIRE_AFTER", 24*time.Hour),
+		FakeBaseURL:       getEnvString("FAKE_PAYMENT_BASE_URL", "http://localhost:8080"),
+		FakeWebhookURL:    getEnvString("FAKE_PAYMENT_WEBHOOK_URL", "http://localhost:8080/webhook/deposit"),
+	}
+}
+
+func getEnvString(key string, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
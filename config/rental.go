@@ -0,0 +1,79 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RentalConfig holds the rental policy, read from the environment.
+type RentalConfig struct {
+	// LateFeePerDay is the flat fee charged for every day a book is kept past its due date.
+	LateFeePerDay int
+	// LateFeePercent, when set, replaces LateFeePerDay with a percentage per day of the rental's
+	// price on its plan, or of the book rental cost for a rental covered by a membership.
+	LateFeePercent int
+	// OverdueCheckInterval is how often borrowed rentals are checked for passing their due date.
+	OverdueCheckInterval time.Duration
+	// ExtensionDays is how many days one renewal adds to the due date.
+	ExtensionDays int
+	// ExtensionCostPercent is the renewal price as a percentage of what the rental's terms
+	// cost for ExtensionDays.
+	ExtensionCostPercent int
+	// MaxRenewals caps how many times a single rental can be extended.
+	MaxRenewals int
+	// HoldDuration is how long a returned copy is kept for the next reservation in line.
+	HoldDuration time.Duration
+	// HoldCheckInterval is how often expired holds are released.
+	HoldCheckInterval time.Duration
+	// RepairFee is charged when a copy comes back damaged.
+	RepairFee int
+	// ReplacementFee is charged when a copy is lost.
+	ReplacementFee int
+}
+
+func LoadRentalConfig() RentalConfig {
+	return RentalConfig{
+		LateFeePerDay:        getEnvInt("LATE_FEE_PER_DAY", 2000),
+		LateFeePercent:       getEnvInt("LATE_FEE_PERCENT", 0),
+		OverdueCheckInterval: getEnvDuration("OVERDUE_CHECK_INTERVAL", time.Hour),
+		ExtensionDays:        getEnvInt("RENTAL_EXTENSION_DAYS", 7),
+		ExtensionCostPercent: getEnvInt("RENTAL_EXTENSION_COST_PERCENT", 100),
+		MaxRenewals:          getEnvInt("RENTAL_MAX_RENEWALS", 2),
+		HoldDuration:         getEnvDuration("RESERVATION_HOLD_DURATION", 48*time.Hour),
+		HoldCheckInterval:    getEnvDuration("RESERVATION_HOLD_CHECK_INTERVAL", 15*time.Minute),
+		RepairFee:            getEnvInt("RENTAL_REPAIR_FEE", 25000),
+		ReplacementFee:       getEnvInt("RENTAL_REPLACEMENT_FEE", 100000),
+	}
+}
+
+// getEnvInt reads a whole number. Every number of the rental policy is an amount, a
+// percentage or a count, so a negative one falls back like a malformed one.
+func getEnvInt(key string, fallback int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s %q, using %d", key, val, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getEnvDuration reads a duration such as "30m". One that is not positive would make a
+// ticker panic or hold nothing, so it falls back like a malformed one.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, val, fallback)
+		return fallback
+	}
+	return d
+}
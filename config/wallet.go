@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// WalletConfig holds the wallet settings, read from the environment.
+type WalletConfig struct {
+	// ReconcileInterval is how often cached deposits are checked against the ledger.
+	ReconcileInterval time.Duration
+}
+
+func LoadWalletConfig() WalletConfig {
+	return WalletConfig{
+		ReconcileInterval: getEnvDuration("WALLET_RECONCILE_INTERVAL", 24*time.Hour),
+	}
+}
@@ -23,40 +23,72 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products": {
+        "/admin/books/export": {
             "get": {
-                "description": "Retrieve all available books with stock and rental info",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Streams every book as a CSV with a header line, or as one JSON object per line, in the format the import takes.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "tags": [
                     "Books"
                 ],
-                "summary": "Get all books",
+                "summary": "Export the catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv (default) or jsonl",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BookResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.BookTransferRecord"
+                            }
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/books/import": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Only admin users can create books",
+                "description": "Admin only. Takes a CSV with a header line, or one JSON object per line, with the fields of dto.BookTransferRecord; in a CSV authors and categories are separated by \";\". A row whose ISBN belongs to a stored book updates it and adds copies until it has at least stok available ones, any other row creates a book. The whole file is imported in one transaction: when any row is invalid nothing is imported and every invalid row is reported. With dry_run nothing is imported either.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "produces": [
                     "application/json"
@@ -64,23 +96,35 @@ const docTemplate = `{
                 "tags": [
                     "Books"
                 ],
-                "summary": "Create a new book",
+                "summary": "Import books in bulk",
                 "parameters": [
                     {
-                        "description": "Book creation request",
-                        "name": "request",
+                        "type": "string",
+                        "description": "csv or jsonl, defaults from the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only check the rows",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Books",
+                        "name": "file",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.GetBookData"
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateBookResponse"
+                            "$ref": "#/definitions/dto.BookImportResponse"
                         }
                     },
                     "400": {
@@ -95,6 +139,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookImportResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -104,9 +160,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}": {
-            "get": {
-                "description": "Get a single book using its ID",
+        "/admin/categories": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. The slug defaults to one made from the name; names and slugs are unique.",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,23 +175,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Categories"
                 ],
-                "summary": "Get a book by its ID",
+                "summary": "Create a category",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Book ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Category",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CategoryRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.BookSwaggerByIDResponse"
+                            "$ref": "#/definitions/dto.CategoryResponse"
                         }
                     },
                     "400": {
@@ -139,21 +202,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/categories/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Only admin can update a book's information",
+                "description": "Admin only. Renaming a category renames it on its books and pricing plans too. A category cannot be moved below itself.",
                 "consumes": [
                     "application/json"
                 ],
@@ -161,24 +238,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Categories"
                 ],
-                "summary": "Update a book by its ID",
+                "summary": "Update a category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update Book Request Body",
+                        "description": "Category",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateBookRequest"
+                            "$ref": "#/definitions/dto.CategoryRequest"
                         }
                     }
                 ],
@@ -186,7 +263,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateBookByIDResponse"
+                            "$ref": "#/definitions/dto.CategoryResponse"
                         }
                     },
                     "400": {
@@ -201,6 +278,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -215,24 +304,27 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Only admin can delete a book by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Admin only. Subcategories move up to the deleted category's parent. A category with books can only be deleted by merging its books into another category with merge_into.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Categories"
                 ],
-                "summary": "Delete a book by its ID",
+                "summary": "Delete a category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the category the books move to",
+                        "name": "merge_into",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -251,6 +343,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -260,26 +364,97 @@ const docTemplate = `{
                 }
             }
         },
-        "/rentals": {
+        "/admin/rentals": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a list of rental data for the authenticated user",
+                "description": "Admin only. Lists the rentals of every user with filters, pagination and sorting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Admin Rentals"
+                ],
+                "summary": "List all rentals",
+                "parameters": [
+                    {
+                        "enum": [
+                            "Borrowed",
+                            "Overdue",
+                            "Returned",
+                            "Lost",
+                            "Cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Rental status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rented on or after (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rented on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only rentals past their due date",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, rent_date, return_date, status or price, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get rental history by logged-in user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.RentalUserResponse"
+                            "$ref": "#/definitions/dto.AdminRentalListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
@@ -295,14 +470,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/rentals/{id}/cancel": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new book rental by user. Requires login.",
+                "description": "Admin only. Voids a rental made in error. The rental price is refunded to the deposit and the copy goes back into circulation. The reason is recorded.",
                 "consumes": [
                     "application/json"
                 ],
@@ -310,25 +487,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Admin Rentals"
                 ],
-                "summary": "Create a rental",
+                "summary": "Cancel a rental",
                 "parameters": [
                     {
-                        "description": "Rental request payload",
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the action",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.RentalRequest"
+                            "$ref": "#/definitions/dto.AdminRentalActionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.RentalResponse"
+                            "$ref": "#/definitions/dto.AdminRentalActionResponse"
                         }
                     },
                     "400": {
@@ -343,6 +527,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -352,9 +548,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/login": {
+        "/admin/rentals/{id}/close": {
             "post": {
-                "description": "Authenticate user and get JWT token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Returns a borrowed or overdue rental on behalf of its user. The late fee is charged and the copy goes back into circulation. The reason is recorded.",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,17 +563,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin Rentals"
                 ],
-                "summary": "User login",
+                "summary": "Force-close a rental",
                 "parameters": [
                     {
-                        "description": "Login Request",
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the action",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
+                            "$ref": "#/definitions/dto.AdminRentalActionRequest"
                         }
                     }
                 ],
@@ -380,7 +588,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginSuccessResponse"
+                            "$ref": "#/definitions/dto.AdminRentalActionResponse"
                         }
                     },
                     "400": {
@@ -395,6 +603,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -404,14 +624,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/profile": {
-            "get": {
+        "/admin/rentals/{id}/damage": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve user profile based on JWT token",
+                "description": "Admin only. Closes a borrowed or overdue rental whose copy came back damaged. The late fee and the repair fee are charged, even when the deposit goes below zero. The copy gets the recorded condition and is taken out of circulation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -419,14 +639,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin Rentals"
+                ],
+                "summary": "Report a returned copy as damaged",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Condition of the copy and notes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportDamageRequest"
+                        }
+                    }
                 ],
-                "summary": "Get current logged-in user data",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.AdminRentalActionResponse"
                         }
                     },
                     "400": {
@@ -440,13 +678,36 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/user/register": {
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rentals/{id}/lost": {
             "post": {
-                "description": "Create a new user account (name, email, password required)",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Closes a borrowed or overdue rental whose copy will not come back. The replacement fee is charged, even when the deposit goes below zero, and stock is not restored. The reason is recorded.",
                 "consumes": [
                     "application/json"
                 ],
@@ -454,17 +715,100 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin Rentals"
                 ],
-                "summary": "Register a new user",
+                "summary": "Mark a rental as lost",
                 "parameters": [
                     {
-                        "description": "User registration request",
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the action",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterRequest"
+                            "$ref": "#/definitions/dto.AdminRentalActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminRentalActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/deposit/adjustments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Credits (positive amount) or debits a user's deposit by hand with a reason code and a note, recorded with the acting admin. A debit that would make the balance negative is refused unless allow_negative is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Wallets"
+                ],
+                "summary": "Adjust a user's deposit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Adjustment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BalanceAdjustmentRequest"
                         }
                     }
                 ],
@@ -472,7 +816,133 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterSuccessResponse"
+                            "$ref": "#/definitions/dto.BalanceAdjustmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/vouchers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Every voucher, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Vouchers"
+                ],
+                "summary": "List vouchers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VoucherListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. A percentage voucher takes value percent off a rental checkout or adds it as bonus credit to a top-up, a fixed voucher takes value off a checkout and a bonus_credit voucher adds value to a top-up. Limits of zero mean no limit, no categories means every category.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Vouchers"
+                ],
+                "summary": "Create a voucher",
+                "parameters": [
+                    {
+                        "description": "Voucher",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateVoucherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VoucherResponse"
                         }
                     },
                     "400": {
@@ -481,6 +951,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -495,10 +971,4117 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.BookResponse": {
+        },
+        "/admin/vouchers/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. The code can no longer be redeemed. Past redemptions, including top-up bonuses still waiting for payment, are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Vouchers"
+                ],
+                "summary": "Deactivate a voucher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Voucher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VoucherResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/withdrawals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Withdrawals of every user, newest first, optionally in one status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Withdrawals"
+                ],
+                "summary": "List all withdrawals",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "paid",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Only withdrawals in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/withdrawals/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Reserves the amount: it leaves the user's balance and is held until the payout is recorded. Fails when the balance no longer covers it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Withdrawals"
+                ],
+                "summary": "Approve a withdrawal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Withdrawal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/withdrawals/{id}/paid": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Records that an approved withdrawal was paid out by hand, with the transfer reference.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Withdrawals"
+                ],
+                "summary": "Record a withdrawal payout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Withdrawal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payout reference",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalPaidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/withdrawals/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Turns down a pending or approved withdrawal. A held amount goes back to the user's balance. The reason is recorded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Withdrawals"
+                ],
+                "summary": "Reject a withdrawal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Withdrawal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "List every category by name with the number of books filed directly under it. Subcategories point at their parent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List categories",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CategoryListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/copies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Lists every physical copy of a book with its barcode, condition, shelf location and status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Book Copies"
+                ],
+                "summary": "List the copies of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookCopyListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Registers a new physical copy. A barcode is generated when none is given. The copy goes to the next reservation in line or becomes available.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Book Copies"
+                ],
+                "summary": "Add a copy of a book",
+                "parameters": [
+                    {
+                        "description": "Book copy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateBookCopyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookCopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/copies/barcode/{barcode}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Front desk lookup of a copy by the barcode on its label, with the rental it is out on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Book Copies"
+                ],
+                "summary": "Scan a copy by barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookCopyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/copies/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Changes the condition, shelf location or status of a copy. The status of a copy on loan or on hold cannot be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Book Copies"
+                ],
+                "summary": "Update a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book copy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book copy changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateBookCopyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookCopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. Removes a copy that is neither on loan nor on hold.",
+                "tags": [
+                    "Book Copies"
+                ],
+                "summary": "Delete a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book copy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/membership-plans": {
+            "get": {
+                "description": "Membership plans that can be bought, cheapest first. A rental quota of zero is unlimited.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Memberships"
+                ],
+                "summary": "List membership plans",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipPlanListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin users can create membership plans. The rental quota is per period, zero for unlimited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Memberships"
+                ],
+                "summary": "Create a membership plan",
+                "parameters": [
+                    {
+                        "description": "Membership plan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMembershipPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipPlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing-plans": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin users can create pricing plans. A plan belongs to one book (book_id) or to a whole existing category, named by its name or slug.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pricing Plans"
+                ],
+                "summary": "Create a pricing plan",
+                "parameters": [
+                    {
+                        "description": "Pricing plan request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePricingPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PricingPlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing-plans/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin can delete a pricing plan. Existing rentals keep their terms.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pricing Plans"
+                ],
+                "summary": "Delete a pricing plan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pricing plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Search the catalog with stock and rental info, one page at a time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Get all books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword searched in the book name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category name or slug, also matching its subcategories",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only books with stock left",
+                        "name": "available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lowest rental cost",
+                        "name": "min_cost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Highest rental cost",
+                        "name": "max_cost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name, cost or newest, prefix with - for the reverse order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin users can create books. One copy with a generated barcode is created per unit of stok. The book is filed under category_ids, the first being its primary category, or else under the category named by category, which is created if it does not exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Create a new book",
+                "parameters": [
+                    {
+                        "description": "Book creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetBookDataResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Full-text search over book names and categories, best matches first, with the matching words highlighted. When nothing matches word for word, books with similar names are returned and fuzzy is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Search books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search words",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a single book using its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Get a book by its ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookByIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin can update a book's information. Bibliographic details left out of the request keep their stored values, and those sent empty are cleared. Stock follows the book copies and cannot be set here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Update a book by its ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Book Request Body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateBookByIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admin can delete a book by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Delete a book by its ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/plans": {
+            "get": {
+                "description": "List the rental plans that can be chosen for a book, its own plans and those of its category. Without a plan the book rents for 7 days at its rental cost.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pricing Plans"
+                ],
+                "summary": "Get pricing plans of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PricingPlanListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new book rental by user. Requires login. The price of the chosen pricing plan, or the book rental cost for the standard 7 days, is debited from the deposit. A copy held for the user's ready reservation is used when the book is out of stock.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Create a rental",
+                "parameters": [
+                    {
+                        "description": "Rental request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RentalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RentalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rent several books at once. Availability and total cost are checked for the whole cart and all rentals are created together with one deposit debit. An optional voucher code takes its discount off the books it applies to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Checkout a cart of books",
+                "parameters": [
+                    {
+                        "description": "Checkout request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CheckoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a list of rental data, including late fees, damage charges and the history of every rental, for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get rental history by logged-in user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RentalUserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/extend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Push the due date of a borrowed rental by the configured period. The extension cost is debited from the deposit. Refused when another user is waiting for the book.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Extend a rental",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExtendRentalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/return": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return a borrowed or overdue book owned by the logged-in user. The book stock is restored and any late fee is debited from the deposit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Return a rented book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReturnRentalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the reservations of the authenticated user with their queue position",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get reservations of logged-in user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReservationListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Join the FIFO queue of a book with no stock. When a copy is returned the first user in line gets a time-limited hold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Reserve an out-of-stock book",
+                "parameters": [
+                    {
+                        "description": "Reservation request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReservationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a waiting or ready reservation of the logged-in user. A held copy is passed to the next user in line.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Cancel a reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/deposits": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deposit orders of the logged-in user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deposits"
+                ],
+                "summary": "List my deposits",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "capture",
+                            "settlement",
+                            "deny",
+                            "cancel",
+                            "expire",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Only deposits in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DepositTransactionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/deposits/{order_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Amount, status, payment time and payment reference of a deposit order of the logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Deposits"
+                ],
+                "summary": "Get one of my deposits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DepositTransactionDetailResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "Authenticate user and get JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/membership": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The running membership of the logged-in user with the remaining rental quota",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Memberships"
+                ],
+                "summary": "Get my membership",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Buys a period of a membership plan, paid from the deposit. Rentals on the standard terms are free while the quota lasts. With auto_renew the next period is paid from the deposit when this one ends.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Memberships"
+                ],
+                "summary": "Buy a membership",
+                "parameters": [
+                    {
+                        "description": "Plan to buy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscribeMembershipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/membership/auto-renew": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Whether the next period is bought from the deposit when the running one ends",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Memberships"
+                ],
+                "summary": "Turn membership auto-renewal on or off",
+                "parameters": [
+                    {
+                        "description": "Auto-renewal",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipAutoRenewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MembershipResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve user profile based on JWT token, with the active membership and its remaining rental quota",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get current logged-in user data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "Create a new user account (name, email, password required)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/wallet/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ledger entries of the logged-in user's wallet, newest first, each with the balance right after it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List wallet transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletTransactionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/withdrawals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Withdrawals of the logged-in user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Withdrawals"
+                ],
+                "summary": "List my withdrawals",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ask for part or all of the deposit back. Nothing leaves the balance until an admin approves it. Only one withdrawal can be open at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Withdrawals"
+                ],
+                "summary": "Request a withdrawal",
+                "parameters": [
+                    {
+                        "description": "Withdrawal request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/withdrawals/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "A withdrawal of the logged-in user with its full status history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Withdrawals"
+                ],
+                "summary": "Get one of my withdrawals",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Withdrawal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AdminRentalActionRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Book returned at the front desk"
+                }
+            }
+        },
+        "dto.AdminRentalActionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.RentalDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Cancel Rental"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.AdminRentalDataResponse": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "book_title": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "late_fee": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "rent_date": {
+                    "type": "string",
+                    "example": "2025-07-03"
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "return_date": {
+                    "type": "string",
+                    "example": "2025-07-10"
+                },
+                "returned_at": {
+                    "type": "string",
+                    "example": "2025-07-09"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Borrowed"
+                },
+                "user_email": {
+                    "type": "string",
+                    "example": "reader@mail.com"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.AdminRentalListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AdminRentalDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Rentals"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.AuthorData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "James Clear"
+                }
+            }
+        },
+        "dto.BalanceAdjustmentDataResponse": {
+            "type": "object",
+            "properties": {
+                "adjustment_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "admin_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "allow_negative": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "amount": {
+                    "type": "integer",
+                    "example": -15000
+                },
+                "balance_after": {
+                    "type": "integer",
+                    "example": 30000
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00+07:00"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Late fee charged twice for rental 8"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "example": "correction"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.BalanceAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "note",
+                "reason_code"
+            ],
+            "properties": {
+                "allow_negative": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "amount": {
+                    "type": "integer",
+                    "example": -15000
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Late fee charged twice for rental 8"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "example": "correction"
+                }
+            }
+        },
+        "dto.BalanceAdjustmentResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.BalanceAdjustmentDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Adjust Balance"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookByIDResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.GetBookData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookCopyDataResponse": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "PB0000010004"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "book_title": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "condition": {
+                    "type": "string",
+                    "example": "Good"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "rental": {
+                    "$ref": "#/definitions/dto.BookCopyRentalResponse"
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "A-3"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "OnLoan"
+                }
+            }
+        },
+        "dto.BookCopyListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookCopyDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Book Copies"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookCopyRentalResponse": {
+            "type": "object",
+            "properties": {
+                "rent_date": {
+                    "type": "string",
+                    "example": "2025-07-03"
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "return_date": {
+                    "type": "string",
+                    "example": "2025-07-10"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Borrowed"
+                },
+                "user_email": {
+                    "type": "string",
+                    "example": "reader@mail.com"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.BookCopyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.BookCopyDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Book Copy"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookImportData": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookImportRowError"
+                    }
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "dto.BookImportResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.BookImportData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Import Books"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "isbn must be a valid ISBN-10 or ISBN-13"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "dto.BookResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GetAllBooksResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/dto.PageLinks"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookSearchData": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.6079
+                },
+                "rental_cost": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "Atomic \u003cmark\u003eHabits\u003c/mark\u003e - Self Development"
+                },
+                "stok": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "dto.BookSearchResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookSearchData"
+                    }
+                },
+                "fuzzy": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "links": {
+                    "$ref": "#/definitions/dto.PageLinks"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Search Books"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.BookTransferRecord": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "James Clear"
+                    ]
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Self Development"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "cover_url": {
+                    "type": "string",
+                    "example": "https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "978-0-7352-1129-2"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2018
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "Avery"
+                },
+                "rental_cost": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "stok": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "synopsis": {
+                    "type": "string",
+                    "example": "An easy and proven way to build good habits and break bad ones."
+                }
+            }
+        },
+        "dto.CategoryDataResponse": {
+            "type": "object",
+            "properties": {
+                "book_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "self-development"
+                }
+            }
+        },
+        "dto.CategoryListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CategoryDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Categories"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.CategoryRef": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "self-development"
+                }
+            }
+        },
+        "dto.CategoryRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "self-development"
+                }
+            }
+        },
+        "dto.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.CategoryDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Create Category"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.CheckoutDataResponse": {
+            "type": "object",
+            "properties": {
+                "checkout_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "discount": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "rentals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RentalDataResponse"
+                    }
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 45000
+                }
+            }
+        },
+        "dto.CheckoutItemRequest": {
+            "type": "object",
+            "required": [
+                "book_id",
+                "quantity"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "plan_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "dto.CheckoutRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.CheckoutItemRequest"
+                    }
+                },
+                "voucher_code": {
+                    "type": "string",
+                    "example": "FIRSTFREE"
+                }
+            }
+        },
+        "dto.CheckoutResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.CheckoutDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Checkout"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Created"
+                }
+            }
+        },
+        "dto.CreateBookCopyRequest": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "PB0000010004"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "condition": {
+                    "type": "string",
+                    "enum": [
+                        "Good",
+                        "Fair",
+                        "Poor",
+                        "Damaged"
+                    ],
+                    "example": "Good"
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "A-3"
+                }
+            }
+        },
+        "dto.CreateBookRequest": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "James Clear"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "programming"
+                },
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3
+                    ]
+                },
+                "cover_url": {
+                    "type": "string",
+                    "example": "https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "978-0-7352-1129-2"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "johndoe"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2018
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "Avery"
+                },
+                "rental_cost": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "stok": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "synopsis": {
+                    "type": "string",
+                    "example": "An easy and proven way to build good habits and break bad ones."
+                }
+            }
+        },
+        "dto.CreateMembershipPlanRequest": {
+            "type": "object",
+            "required": [
+                "duration_days",
+                "name",
+                "price"
+            ],
+            "properties": {
+                "duration_days": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 30
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Basic"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 50000
+                },
+                "rental_quota": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
+                }
+            }
+        },
+        "dto.CreatePricingPlanRequest": {
+            "type": "object",
+            "required": [
+                "duration_days",
+                "name"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "category": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "duration_days": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 14
+                },
+                "name": {
+                    "type": "string",
+                    "example": "14 days"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 35000
+                }
+            }
+        },
+        "dto.CreateVoucherRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "scope",
+                "type",
+                "value"
+            ],
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Novel"
+                    ]
+                },
+                "code": {
+                    "type": "string",
+                    "example": "TOPUP10"
+                },
+                "max_discount": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "max_uses_per_user": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "min_amount": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "rental",
+                        "top_up"
+                    ],
+                    "example": "top_up"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed",
+                        "bonus_credit"
+                    ],
+                    "example": "percentage"
+                },
+                "valid_from": {
+                    "type": "string",
+                    "example": "2025-08-01T00:00:00+07:00"
+                },
+                "valid_until": {
+                    "type": "string",
+                    "example": "2025-09-01T00:00:00+07:00"
+                },
+                "value": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 10
+                }
+            }
+        },
+        "dto.DepositTransactionDataResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "bonus": {
+                    "type": "integer",
+                    "example": 5000
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-06-10T08:00:00Z"
+                },
+                "order_id": {
+                    "type": "string",
+                    "example": "ORDER-3-1718000000000000000"
+                },
+                "paid_at": {
+                    "type": "string",
+                    "example": "2024-06-10T08:05:00Z"
+                },
+                "payment_ref": {
+                    "type": "string",
+                    "example": "66e4fa55-fdac-4ef9-91b5-733b97d1b862"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "settlement"
+                }
+            }
+        },
+        "dto.DepositTransactionDetailResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.DepositTransactionDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Deposit"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.DepositTransactionListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DepositTransactionDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Deposits"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "details": {},
+                "message": {
+                    "type": "string",
+                    "example": "something went wrong"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error"
+                }
+            }
+        },
+        "dto.ExtendRentalDataResponse": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "extension_cost": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "renewal_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "return_date": {
+                    "type": "string",
+                    "example": "2025-07-17"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Borrowed"
+                }
+            }
+        },
+        "dto.ExtendRentalResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.ExtendRentalDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Extend Rental"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.GetAllBooksResponse": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "programming"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "johndoe"
+                },
+                "rental_cost": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "stok": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.GetBookData": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AuthorData"
+                    }
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CategoryRef"
+                    }
+                },
+                "category": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "cover_url": {
+                    "type": "string",
+                    "example": "https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780735211292"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2018
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "Avery"
+                },
+                "rental_cost": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "stok": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "synopsis": {
+                    "type": "string",
+                    "example": "An easy and proven way to build good habits and break bad ones."
+                }
+            }
+        },
+        "dto.GetBookDataResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.GetBookData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success create book"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.LoginSuccessResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "your-jwt-token"
+                }
+            }
+        },
+        "dto.MembershipAutoRenewRequest": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.MembershipDataResponse": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-07-31T10:00:00+07:00"
+                },
+                "membership_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "plan_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "plan_name": {
+                    "type": "string",
+                    "example": "Basic"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "remaining_quota": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rental_quota": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "rentals_used": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2025-07-01T10:00:00+07:00"
+                },
+                "unlimited": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.MembershipPlanDataResponse": {
+            "type": "object",
+            "properties": {
+                "duration_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Basic"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "rental_quota": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "unlimited": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.MembershipPlanListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MembershipPlanDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Membership Plans"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.MembershipPlanResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.MembershipPlanDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Create Membership Plan"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.MembershipResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.MembershipDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Membership"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.PageLinks": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string",
+                    "example": "/api/products?page=3\u0026limit=20"
+                },
+                "prev": {
+                    "type": "string",
+                    "example": "/api/products?page=1\u0026limit=20"
+                }
+            }
+        },
+        "dto.PaginationMeta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 57
+                }
+            }
+        },
+        "dto.PricingPlanDataResponse": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "category": {
+                    "type": "string",
+                    "example": "Self Development"
+                },
+                "duration_days": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "14 days"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 35000
+                }
+            }
+        },
+        "dto.PricingPlanListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PricingPlanDataResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Get Pricing Plans"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.PricingPlanResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.PricingPlanDataResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success Create Pricing Plan"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
+        "dto.RegisterSuccessResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.RegisterResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "dto.RentalDataResponse": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "book_copy_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "damage_fee": {
+                    "type": "integer",
+                    "example": 25000
+                },
+                "discount": {
+                    "type": "integer",
+                    "example": 5000
+                },
+                "duration_days": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "late_fee": {
+                    "type": "integer",
+                    "example": 4000
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 35000
+                },
+                "pricing_plan_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rent_date": {
+                    "type": "string",
+                    "example": "2020-01-01"
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "return_date": {
+                    "type": "string",
+                    "example": "2020-01-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "borrowed"
+                }
+            }
+        },
+        "dto.RentalEventResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": -25000
+                },
+                "condition": {
+                    "type": "string",
+                    "example": "Damaged"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-09T10:00:00+07:00"
+                },
+                "from_status": {
+                    "type": "string",
+                    "example": "Borrowed"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Water damage on the cover"
+                },
+                "to_status": {
+                    "type": "string",
+                    "example": "Returned"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "damaged"
+                }
+            }
+        },
+        "dto.RentalRequest": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "plan_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.RentalResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -506,10 +5089,7 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.GetAllBooksResponse"
-                    }
+                    "$ref": "#/definitions/dto.RentalDataResponse"
                 },
                 "message": {
                     "type": "string",
@@ -521,32 +5101,54 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.BookSwagger": {
+        "dto.RentalUserDataResponse": {
             "type": "object",
             "properties": {
-                "category": {
-                    "type": "string",
-                    "example": "Self Development"
-                },
-                "id": {
+                "book_id": {
                     "type": "integer",
-                    "example": 1
+                    "example": 2
                 },
-                "name": {
+                "book_title": {
                     "type": "string",
                     "example": "Atomic Habits"
                 },
-                "rental_cost": {
+                "damage_fee": {
                     "type": "integer",
-                    "example": 20000
+                    "example": 0
                 },
-                "stok": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RentalEventResponse"
+                    }
+                },
+                "late_fee": {
                     "type": "integer",
-                    "example": 5
+                    "example": 0
+                },
+                "rent_date": {
+                    "type": "string",
+                    "example": "2025-07-03"
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "return_date": {
+                    "type": "string",
+                    "example": "2025-07-10"
+                },
+                "returned_at": {
+                    "type": "string",
+                    "example": "2025-07-09"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Borrowed"
                 }
             }
         },
-        "dto.BookSwaggerByIDResponse": {
+        "dto.RentalUserResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -554,11 +5156,14 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "$ref": "#/definitions/dto.BookSwagger"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RentalUserDataResponse"
+                    }
                 },
                 "message": {
                     "type": "string",
-                    "example": "success"
+                    "example": "Rental list"
                 },
                 "status": {
                     "type": "string",
@@ -566,16 +5171,77 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.CreateBookResponse": {
+        "dto.ReportDamageRequest": {
+            "type": "object",
+            "required": [
+                "condition",
+                "notes"
+            ],
+            "properties": {
+                "condition": {
+                    "type": "string",
+                    "enum": [
+                        "Fair",
+                        "Poor",
+                        "Damaged"
+                    ],
+                    "example": "Damaged"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Water damage on the cover"
+                }
+            }
+        },
+        "dto.ReservationDataResponse": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "book_title": {
+                    "type": "string",
+                    "example": "Atomic Habits"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-03 10:00:00"
+                },
+                "hold_expires_at": {
+                    "type": "string",
+                    "example": "2025-07-05 10:00:00"
+                },
+                "queue_position": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "reservation_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Waiting"
+                }
+            }
+        },
+        "dto.ReservationListResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
                     "example": 200
                 },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReservationDataResponse"
+                    }
+                },
                 "message": {
                     "type": "string",
-                    "example": "success create book"
+                    "example": "Success Get Reservations"
                 },
                 "status": {
                     "type": "string",
@@ -583,140 +5249,243 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.ErrorResponse": {
+        "dto.ReservationRequest": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.ReservationResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 400
+                    "example": 201
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.ReservationDataResponse"
                 },
-                "details": {},
                 "message": {
                     "type": "string",
-                    "example": "something went wrong"
+                    "example": "Success Create Reservation"
                 },
                 "status": {
                     "type": "string",
-                    "example": "error"
+                    "example": "success"
                 }
             }
         },
-        "dto.GetAllBooksResponse": {
+        "dto.ReturnRentalDataResponse": {
             "type": "object",
             "properties": {
-                "category": {
-                    "type": "string",
-                    "example": "programming"
+                "book_id": {
+                    "type": "integer",
+                    "example": 2
                 },
-                "id": {
+                "late_fee": {
                     "type": "integer",
-                    "example": 0
+                    "example": 4000
                 },
-                "name": {
+                "rent_date": {
                     "type": "string",
-                    "example": "johndoe"
+                    "example": "2025-07-03"
                 },
-                "rental_cost": {
+                "rental_id": {
                     "type": "integer",
                     "example": 1
                 },
-                "stok": {
-                    "type": "integer",
-                    "example": 1
+                "return_date": {
+                    "type": "string",
+                    "example": "2025-07-10"
+                },
+                "returned_at": {
+                    "type": "string",
+                    "example": "2025-07-09"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Returned"
                 }
             }
         },
-        "dto.GetBookData": {
+        "dto.ReturnRentalResponse": {
             "type": "object",
             "properties": {
-                "category": {
-                    "type": "string",
-                    "example": "Self Development"
-                },
-                "id": {
+                "code": {
                     "type": "integer",
-                    "example": 1
+                    "example": 200
                 },
-                "name": {
-                    "type": "string",
-                    "example": "Atomic Habits"
+                "data": {
+                    "$ref": "#/definitions/dto.ReturnRentalDataResponse"
                 },
-                "rental_cost": {
-                    "type": "integer",
-                    "example": 20000
+                "message": {
+                    "type": "string",
+                    "example": "Success Return Rental"
                 },
-                "stok": {
-                    "type": "integer",
-                    "example": 5
+                "status": {
+                    "type": "string",
+                    "example": "success"
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.SubscribeMembershipRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "plan_id"
             ],
             "properties": {
-                "email": {
+                "auto_renew": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "plan_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.UpdateBookByIDResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.GetBookData"
+                },
+                "message": {
                     "type": "string",
-                    "example": "johndoe@gmail.com"
+                    "example": "success"
                 },
-                "password": {
+                "status": {
                     "type": "string",
-                    "example": "123456"
+                    "example": "success"
                 }
             }
         },
-        "dto.LoginSuccessResponse": {
+        "dto.UpdateBookCopyRequest": {
             "type": "object",
             "properties": {
-                "token": {
+                "condition": {
                     "type": "string",
-                    "example": "your-jwt-token"
+                    "enum": [
+                        "Good",
+                        "Fair",
+                        "Poor",
+                        "Damaged"
+                    ],
+                    "example": "Fair"
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "B-1"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Available",
+                        "Damaged",
+                        "Lost",
+                        "Retired"
+                    ],
+                    "example": "Damaged"
                 }
             }
         },
-        "dto.RegisterRequest": {
+        "dto.UpdateBookRequest": {
             "type": "object",
             "required": [
-                "email",
+                "category",
                 "name",
-                "password"
+                "rental_cost"
             ],
             "properties": {
-                "email": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "James Clear"
+                    ]
+                },
+                "category": {
+                    "type": "string"
+                },
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3
+                    ]
+                },
+                "cover_url": {
                     "type": "string",
-                    "example": "johndoe@gmail.com"
+                    "example": "https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "978-0-7352-1129-2"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
                 },
                 "name": {
+                    "type": "string"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2018
+                },
+                "publisher": {
                     "type": "string",
-                    "example": "John Doe"
+                    "example": "Avery"
                 },
-                "password": {
+                "rental_cost": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "synopsis": {
                     "type": "string",
-                    "example": "123456"
+                    "example": "An easy and proven way to build good habits and break bad ones."
                 }
             }
         },
-        "dto.RegisterResponse": {
+        "dto.UserDataResponse": {
             "type": "object",
             "properties": {
+                "deposit": {
+                    "type": "integer",
+                    "example": 4
+                },
                 "email": {
                     "type": "string",
-                    "example": "johndoe@gmail.com"
+                    "example": "johndoe@example.com"
                 },
-                "name": {
-                    "type": "string",
-                    "example": "John Doe"
+                "membership": {
+                    "$ref": "#/definitions/dto.MembershipDataResponse"
                 },
-                "role": {
+                "name": {
                     "type": "string",
-                    "example": "user"
+                    "example": "John doe"
                 }
             }
         },
-        "dto.RegisterSuccessResponse": {
+        "dto.UserResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -724,55 +5493,81 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "$ref": "#/definitions/dto.RegisterResponse"
+                    "$ref": "#/definitions/dto.UserDataResponse"
                 },
                 "message": {
                     "type": "string",
-                    "example": "success"
+                    "example": "Success Get User"
                 },
                 "status": {
                     "type": "string",
-                    "example": "success"
+                    "example": "Success"
                 }
             }
         },
-        "dto.RentalDataResponse": {
+        "dto.VoucherDataResponse": {
             "type": "object",
-            "required": [
-                "book_id"
-            ],
             "properties": {
-                "book_id": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Novel"
+                    ]
+                },
+                "code": {
+                    "type": "string",
+                    "example": "TOPUP10"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "max_discount": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "max_uses_per_user": {
                     "type": "integer",
                     "example": 1
                 },
-                "rent_date": {
+                "min_amount": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "scope": {
                     "type": "string",
-                    "example": "2020-01-01"
+                    "example": "top_up"
                 },
-                "return_date": {
+                "type": {
                     "type": "string",
-                    "example": "2020-01-01"
+                    "example": "percentage"
                 },
-                "status": {
+                "valid_from": {
                     "type": "string",
-                    "example": "borrowed"
-                }
-            }
-        },
-        "dto.RentalRequest": {
-            "type": "object",
-            "required": [
-                "book_id"
-            ],
-            "properties": {
-                "book_id": {
+                    "example": "2025-08-01T00:00:00+07:00"
+                },
+                "valid_until": {
+                    "type": "string",
+                    "example": "2025-09-01T00:00:00+07:00"
+                },
+                "value": {
                     "type": "integer",
-                    "example": 1
+                    "example": 10
                 }
             }
         },
-        "dto.RentalResponse": {
+        "dto.VoucherListResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -780,11 +5575,17 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "$ref": "#/definitions/dto.RentalDataResponse"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.VoucherDataResponse"
+                    }
                 },
                 "message": {
                     "type": "string",
-                    "example": "success"
+                    "example": "Success Get Vouchers"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
                 },
                 "status": {
                     "type": "string",
@@ -792,38 +5593,33 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.RentalUserDataResponse": {
+        "dto.VoucherResponse": {
             "type": "object",
             "properties": {
-                "book_id": {
+                "code": {
                     "type": "integer",
-                    "example": 2
-                },
-                "book_title": {
-                    "type": "string",
-                    "example": "Atomic Habits"
-                },
-                "rent_date": {
-                    "type": "string",
-                    "example": "2025-07-03"
+                    "example": 201
                 },
-                "rental_id": {
-                    "type": "integer",
-                    "example": 1
+                "data": {
+                    "$ref": "#/definitions/dto.VoucherDataResponse"
                 },
-                "return_date": {
+                "message": {
                     "type": "string",
-                    "example": "2025-07-10"
+                    "example": "Success Create Voucher"
                 },
                 "status": {
                     "type": "string",
-                    "example": "Borrowed"
+                    "example": "success"
                 }
             }
         },
-        "dto.RentalUserResponse": {
+        "dto.WalletTransactionListResponse": {
             "type": "object",
             "properties": {
+                "balance": {
+                    "type": "integer",
+                    "example": 45000
+                },
                 "code": {
                     "type": "integer",
                     "example": 200
@@ -831,12 +5627,15 @@ const docTemplate = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.RentalUserDataResponse"
+                        "$ref": "#/definitions/dto.WalletTransactionResponse"
                     }
                 },
                 "message": {
                     "type": "string",
-                    "example": "Rental list"
+                    "example": "Success Get Wallet Transactions"
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
                 },
                 "status": {
                     "type": "string",
@@ -844,94 +5643,175 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.UpdateBookByIDResponse": {
+        "dto.WalletTransactionResponse": {
             "type": "object",
             "properties": {
-                "code": {
+                "amount": {
                     "type": "integer",
-                    "example": 200
+                    "example": -15000
                 },
-                "data": {
-                    "$ref": "#/definitions/dto.UpdateResoponse"
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-06-10T08:00:00Z"
                 },
-                "message": {
+                "deposit_order_id": {
                     "type": "string",
-                    "example": "success"
+                    "example": "ORDER-3-1718000000"
                 },
-                "status": {
+                "description": {
                     "type": "string",
-                    "example": "success"
+                    "example": "Rental of Atomic Habits"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "rental_id": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "running_balance": {
+                    "type": "integer",
+                    "example": 45000
+                },
+                "type": {
+                    "type": "string",
+                    "example": "rental_charge"
                 }
             }
         },
-        "dto.UpdateBookRequest": {
+        "dto.WithdrawalActionRequest": {
             "type": "object",
-            "required": [
-                "category",
-                "name",
-                "rental_cost",
-                "stok"
-            ],
             "properties": {
-                "category": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "example": "Bank details verified"
+                }
+            }
+        },
+        "dto.WithdrawalDataResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 40000
                 },
-                "name": {
-                    "type": "string"
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-09T10:00:00+07:00"
                 },
-                "rental_cost": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WithdrawalEventResponse"
+                    }
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Moving out of town, please transfer to BCA 1234567890"
+                },
+                "payout_ref": {
+                    "type": "string",
+                    "example": "TRF-20250709-0012"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "approved"
+                },
+                "user_email": {
+                    "type": "string",
+                    "example": "reader@mail.com"
+                },
+                "user_id": {
                     "type": "integer",
-                    "minimum": 0
+                    "example": 3
                 },
-                "stok": {
+                "withdrawal_id": {
                     "type": "integer",
-                    "minimum": 0
+                    "example": 4
                 }
             }
         },
-        "dto.UpdateResoponse": {
+        "dto.WithdrawalEventResponse": {
             "type": "object",
             "properties": {
-                "category": {
-                    "type": "string",
-                    "example": "programming"
-                },
-                "id": {
+                "actor_id": {
                     "type": "integer",
-                    "example": 2
+                    "example": 1
                 },
-                "name": {
+                "created_at": {
                     "type": "string",
-                    "example": "johndoe"
+                    "example": "2025-07-09T11:00:00+07:00"
                 },
-                "rental_cost": {
-                    "type": "integer",
-                    "example": 1
+                "from_status": {
+                    "type": "string",
+                    "example": "pending"
                 },
-                "stok": {
-                    "type": "integer",
-                    "example": 1
+                "reason": {
+                    "type": "string",
+                    "example": "Bank details verified"
+                },
+                "to_status": {
+                    "type": "string",
+                    "example": "approved"
                 }
             }
         },
-        "dto.UserDataResponse": {
+        "dto.WithdrawalListResponse": {
             "type": "object",
             "properties": {
-                "deposit": {
+                "code": {
                     "type": "integer",
-                    "example": 4
+                    "example": 200
                 },
-                "email": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WithdrawalDataResponse"
+                    }
+                },
+                "message": {
                     "type": "string",
-                    "example": "johndoe@example.com"
+                    "example": "Success Get Withdrawals"
                 },
-                "name": {
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                },
+                "status": {
                     "type": "string",
-                    "example": "John doe"
+                    "example": "success"
                 }
             }
         },
-        "dto.UserResponse": {
+        "dto.WithdrawalPaidRequest": {
+            "type": "object",
+            "required": [
+                "payout_ref"
+            ],
+            "properties": {
+                "payout_ref": {
+                    "type": "string",
+                    "example": "TRF-20250709-0012"
+                }
+            }
+        },
+        "dto.WithdrawalRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 40000
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Moving out of town, please transfer to BCA 1234567890"
+                }
+            }
+        },
+        "dto.WithdrawalResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -939,15 +5819,15 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "$ref": "#/definitions/dto.UserDataResponse"
+                    "$ref": "#/definitions/dto.WithdrawalDataResponse"
                 },
                 "message": {
                     "type": "string",
-                    "example": "Success Get User"
+                    "example": "Success Get Withdrawal"
                 },
                 "status": {
                     "type": "string",
-                    "example": "Success"
+                    "example": "success"
                 }
             }
         }
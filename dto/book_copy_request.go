@@ -0,0 +1,14 @@
+package dto
+
+type CreateBookCopyRequest struct {
+	BookID        uint   `json:"book_id" example:"1" validate:"required"`
+	Barcode       string `json:"barcode,omitempty" example:"PB0000010004"`
+	Condition     string `json:"condition,omitempty" example:"Good" enums:"Good,Fair,Poor,Damaged"`
+	ShelfLocation string `json:"shelf_location,omitempty" example:"A-3"`
+}
+
+type UpdateBookCopyRequest struct {
+	Condition     string `json:"condition,omitempty" example:"Fair" enums:"Good,Fair,Poor,Damaged"`
+	ShelfLocation string `json:"shelf_location,omitempty" example:"B-1"`
+	Status        string `json:"status,omitempty" example:"Damaged" enums:"Available,Damaged,Lost,Retired"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+type BookCopyResponse struct {
+	Status  string               `json:"status" example:"success"`
+	Code    int                  `json:"code" example:"200"`
+	Message string               `json:"message" example:"Success Get Book Copy"`
+	Data    BookCopyDataResponse `json:"data"`
+}
+
+type BookCopyListResponse struct {
+	Status  string                 `json:"status" example:"success"`
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Success Get Book Copies"`
+	Data    []BookCopyDataResponse `json:"data"`
+}
+
+type BookCopyDataResponse struct {
+	ID            uint                    `json:"id" example:"4"`
+	BookID        uint                    `json:"book_id" example:"1"`
+	BookTitle     string                  `json:"book_title,omitempty" example:"Atomic Habits"`
+	Barcode       string                  `json:"barcode" example:"PB0000010004"`
+	Condition     string                  `json:"condition" example:"Good"`
+	ShelfLocation string                  `json:"shelf_location" example:"A-3"`
+	Status        string                  `json:"status" example:"OnLoan"`
+	Rental        *BookCopyRentalResponse `json:"rental,omitempty"`
+}
+
+type BookCopyRentalResponse struct {
+	RentalID   uint   `json:"rental_id" example:"12"`
+	UserID     uint   `json:"user_id" example:"3"`
+	UserEmail  string `json:"user_email" example:"reader@mail.com"`
+	RentDate   string `json:"rent_date" example:"2025-07-03"`
+	ReturnDate string `json:"return_date" example:"2025-07-10"`
+	Status     string `json:"status" example:"Borrowed"`
+}
@@ -1,10 +1,39 @@
 package dto
 
+// BookMetadataRequest carries the bibliographic details of a book. All of them are
+// optional; an ISBN may be written with or without hyphens.
+type BookMetadataRequest struct {
+	ISBN            string   `json:"isbn" example:"978-0-7352-1129-2"`
+	Authors         []string `json:"authors" example:"James Clear"`
+	Publisher       string   `json:"publisher" example:"Avery"`
+	PublicationYear int      `json:"publication_year" example:"2018"`
+	Language        string   `json:"language" example:"en"`
+	PageCount       int      `json:"page_count" example:"320"`
+	Synopsis        string   `json:"synopsis" example:"An easy and proven way to build good habits and break bad ones."`
+	CoverURL        string   `json:"cover_url" example:"https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"`
+}
+
+// UpdateBookMetadataRequest carries the bibliographic details to change on a book. A
+// detail left out keeps its stored value, while one sent empty clears it.
+type UpdateBookMetadataRequest struct {
+	ISBN            *string  `json:"isbn" example:"978-0-7352-1129-2"`
+	Authors         []string `json:"authors" example:"James Clear"`
+	Publisher       *string  `json:"publisher" example:"Avery"`
+	PublicationYear *int     `json:"publication_year" example:"2018"`
+	Language        *string  `json:"language" example:"en"`
+	PageCount       *int     `json:"page_count" example:"320"`
+	Synopsis        *string  `json:"synopsis" example:"An easy and proven way to build good habits and break bad ones."`
+	CoverURL        *string  `json:"cover_url" example:"https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"`
+}
+
+// UpdateBookRequest files the book under the categories in CategoryIDs, the first one
+// being its primary category, or else under the category named by Category.
 type UpdateBookRequest struct {
-	Name       string `json:"name" validate:"required"`
-	Stok       int    `json:"stok" validate:"required,gte=0"`
-	RentalCost int    `json:"rental_cost" validate:"required,gte=0"`
-	Category   string `json:"category" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	RentalCost  int    `json:"rental_cost" validate:"required,gte=0"`
+	Category    string `json:"category" validate:"required"`
+	CategoryIDs []uint `json:"category_ids" example:"3"`
+	UpdateBookMetadataRequest
 }
 
 type CreateBookResponse struct {
@@ -14,11 +43,31 @@ type CreateBookResponse struct {
 }
 
 type GetBookData struct {
-	ID         uint   `json:"id" example:"1"`
-	Name       string `json:"name" example:"Atomic Habits"`
-	Stok       int    `json:"stok" example:"5"`
-	Category   string `json:"category" example:"Self Development"`
-	RentalCost int    `json:"rental_cost" example:"20000"`
+	ID              uint          `json:"id" example:"1"`
+	Name            string        `json:"name" example:"Atomic Habits"`
+	Stok            int           `json:"stok" example:"5"`
+	Category        string        `json:"category" example:"Self Development"`
+	RentalCost      int           `json:"rental_cost" example:"20000"`
+	Categories      []CategoryRef `json:"categories"`
+	ISBN            string        `json:"isbn" example:"9780735211292"`
+	Authors         []AuthorData  `json:"authors"`
+	Publisher       string        `json:"publisher" example:"Avery"`
+	PublicationYear int           `json:"publication_year" example:"2018"`
+	Language        string        `json:"language" example:"en"`
+	PageCount       int           `json:"page_count" example:"320"`
+	Synopsis        string        `json:"synopsis" example:"An easy and proven way to build good habits and break bad ones."`
+	CoverURL        string        `json:"cover_url" example:"https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"`
+}
+
+type CategoryRef struct {
+	ID   uint   `json:"id" example:"3"`
+	Slug string `json:"slug" example:"self-development"`
+	Name string `json:"name" example:"Self Development"`
+}
+
+type AuthorData struct {
+	ID   uint   `json:"id" example:"1"`
+	Name string `json:"name" example:"James Clear"`
 }
 
 type GetBookDataResponse struct {
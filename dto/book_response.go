@@ -1,12 +1,19 @@
 package dto
 
-import "pojok-baca-api/model"
-
 type BookResponse struct {
 	Status  string                `json:"status" example:"success"`
 	Code    int                   `json:"code" example:"200"`
 	Message string                `json:"message" example:"success"`
 	Data    []GetAllBooksResponse `json:"data"`
+	Meta    PaginationMeta        `json:"meta"`
+	Links   PageLinks             `json:"links"`
+}
+
+// PageLinks point at the neighbouring pages of a list, with the same query. A link is
+// left out on the first or last page.
+type PageLinks struct {
+	Next string `json:"next,omitempty" example:"/api/products?page=3&limit=20"`
+	Prev string `json:"prev,omitempty" example:"/api/products?page=1&limit=20"`
 }
 
 type GetAllBooksResponse struct {
@@ -17,31 +24,47 @@ type GetAllBooksResponse struct {
 	Category   string `json:"category" example:"programming"`
 }
 
+// CreateBookRequest files the book under the categories in CategoryIDs, the first one
+// being its primary category, or else under the category named by Category.
 type CreateBookRequest struct {
-	Name       string `json:"name" example:"johndoe"`
-	Stok       int    `json:"stok" example:"1"`
-	RentalCost int    `json:"rental_cost" example:"1"`
-	Category   string `json:"category" example:"programming"`
+	Name        string `json:"name" example:"johndoe"`
+	Stok        int    `json:"stok" example:"1"`
+	RentalCost  int    `json:"rental_cost" example:"1"`
+	Category    string `json:"category" example:"programming"`
+	CategoryIDs []uint `json:"category_ids" example:"3"`
+	BookMetadataRequest
 }
 
 type BookByIDResponse struct {
-	Status  string     `json:"status" example:"success"`
-	Code    int        `json:"code" example:"200"`
-	Message string     `json:"message" example:"success"`
-	Data    model.Book `json:"data"`
+	Status  string      `json:"status" example:"success"`
+	Code    int         `json:"code" example:"200"`
+	Message string      `json:"message" example:"success"`
+	Data    GetBookData `json:"data"`
 }
 
 type UpdateBookByIDResponse struct {
-	Status  string          `json:"status" example:"success"`
-	Code    int             `json:"code" example:"200"`
-	Message string          `json:"message" example:"success"`
-	Data    UpdateResoponse `json:"data"`
+	Status  string      `json:"status" example:"success"`
+	Code    int         `json:"code" example:"200"`
+	Message string      `json:"message" example:"success"`
+	Data    GetBookData `json:"data"`
 }
 
-type UpdateResoponse struct {
-	ID         uint   `json:"id" example:"2"`
-	Name       string `json:"name" example:"johndoe"`
-	Stok       int    `json:"stok" example:"1"`
-	RentalCost int    `json:"rental_cost" example:"1"`
-	Category   string `json:"category" example:"programming"`
+type BookSearchResponse struct {
+	Status  string           `json:"status" example:"success"`
+	Code    int              `json:"code" example:"200"`
+	Message string           `json:"message" example:"Success Search Books"`
+	Data    []BookSearchData `json:"data"`
+	Fuzzy   bool             `json:"fuzzy" example:"false"`
+	Meta    PaginationMeta   `json:"meta"`
+	Links   PageLinks        `json:"links"`
+}
+
+type BookSearchData struct {
+	ID         uint    `json:"id" example:"1"`
+	Name       string  `json:"name" example:"Atomic Habits"`
+	Stok       int     `json:"stok" example:"5"`
+	RentalCost int     `json:"rental_cost" example:"20000"`
+	Category   string  `json:"category" example:"Self Development"`
+	Rank       float64 `json:"rank" example:"0.6079"`
+	Snippet    string  `json:"snippet" example:"Atomic <mark>Habits</mark> - Self Development"`
 }
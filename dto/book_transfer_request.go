@@ -0,0 +1,23 @@
+package dto
+
+// BookTransferRecord is one book in a bulk import or export, a CSV row or a JSON line.
+// Rows are matched to stored books by ISBN. ID is only written on export and ignored
+// on import; Stok is the number of available copies the book should have at least.
+// Category is the primary category and Categories lists every category of the book,
+// all by name.
+type BookTransferRecord struct {
+	ID              uint     `json:"id,omitempty" example:"1"`
+	ISBN            string   `json:"isbn" example:"978-0-7352-1129-2"`
+	Name            string   `json:"name" example:"Atomic Habits"`
+	Category        string   `json:"category" example:"Self Development"`
+	Categories      []string `json:"categories" example:"Self Development"`
+	RentalCost      int      `json:"rental_cost" example:"20000"`
+	Stok            int      `json:"stok" example:"5"`
+	Authors         []string `json:"authors" example:"James Clear"`
+	Publisher       string   `json:"publisher" example:"Avery"`
+	PublicationYear int      `json:"publication_year" example:"2018"`
+	Language        string   `json:"language" example:"en"`
+	PageCount       int      `json:"page_count" example:"320"`
+	Synopsis        string   `json:"synopsis" example:"An easy and proven way to build good habits and break bad ones."`
+	CoverURL        string   `json:"cover_url" example:"https://covers.openlibrary.org/b/isbn/9780735211292-L.jpg"`
+}
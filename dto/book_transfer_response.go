@@ -0,0 +1,23 @@
+package dto
+
+type BookImportResponse struct {
+	Status  string         `json:"status" example:"success"`
+	Code    int            `json:"code" example:"200"`
+	Message string         `json:"message" example:"Success Import Books"`
+	Data    BookImportData `json:"data"`
+}
+
+type BookImportData struct {
+	DryRun  bool                 `json:"dry_run" example:"false"`
+	Rows    int                  `json:"rows" example:"120"`
+	Created int                  `json:"created" example:"100"`
+	Updated int                  `json:"updated" example:"20"`
+	Errors  []BookImportRowError `json:"errors"`
+}
+
+// BookImportRowError explains why a row of an import was rejected. Line is the line of
+// the file the row starts on.
+type BookImportRowError struct {
+	Line  int    `json:"line" example:"7"`
+	Error string `json:"error" example:"isbn must be a valid ISBN-10 or ISBN-13"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// CategoryRequest creates or changes a category. The slug defaults to one made from
+// the name.
+type CategoryRequest struct {
+	Name     string `json:"name" example:"Self Development" validate:"required"`
+	Slug     string `json:"slug,omitempty" example:"self-development"`
+	ParentID *uint  `json:"parent_id,omitempty" example:"1"`
+}
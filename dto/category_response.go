@@ -0,0 +1,23 @@
+package dto
+
+type CategoryResponse struct {
+	Status  string               `json:"status" example:"success"`
+	Code    int                  `json:"code" example:"201"`
+	Message string               `json:"message" example:"Success Create Category"`
+	Data    CategoryDataResponse `json:"data"`
+}
+
+type CategoryListResponse struct {
+	Status  string                 `json:"status" example:"success"`
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Success Get Categories"`
+	Data    []CategoryDataResponse `json:"data"`
+}
+
+type CategoryDataResponse struct {
+	ID        uint   `json:"id" example:"3"`
+	Slug      string `json:"slug" example:"self-development"`
+	Name      string `json:"name" example:"Self Development"`
+	ParentID  *uint  `json:"parent_id,omitempty" example:"1"`
+	BookCount int64  `json:"book_count" example:"12"`
+}
@@ -1,5 +1,16 @@
 package dto
 
 type CreateDepositoryRequest struct {
-	Amount int `json:"amount"`
+	Amount      int    `json:"amount"`
+	VoucherCode string `json:"voucher_code,omitempty"`
+}
+
+// DepositNotificationRequest is the payment notification Midtrans posts to the webhook.
+type DepositNotificationRequest struct {
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
 }
@@ -7,3 +7,28 @@ type DepositResponse struct {
 	OrderID string `json:"order_id"`
 	PayURL  string `json:"payment_url"`
 }
+
+type DepositTransactionListResponse struct {
+	Status  string                           `json:"status" example:"success"`
+	Code    int                              `json:"code" example:"200"`
+	Message string                           `json:"message" example:"Success Get Deposits"`
+	Data    []DepositTransactionDataResponse `json:"data"`
+	Meta    PaginationMeta                   `json:"meta"`
+}
+
+type DepositTransactionDetailResponse struct {
+	Status  string                         `json:"status" example:"success"`
+	Code    int                            `json:"code" example:"200"`
+	Message string                         `json:"message" example:"Success Get Deposit"`
+	Data    DepositTransactionDataResponse `json:"data"`
+}
+
+type DepositTransactionDataResponse struct {
+	OrderID    string `json:"order_id" example:"ORDER-3-1718000000000000000"`
+	Amount     int    `json:"amount" example:"50000"`
+	Bonus      int    `json:"bonus,omitempty" example:"5000"`
+	Status     string `json:"status" example:"settlement"`
+	PaidAt     string `json:"paid_at,omitempty" example:"2024-06-10T08:05:00Z"`
+	PaymentRef string `json:"payment_ref" example:"66e4fa55-fdac-4ef9-91b5-733b97d1b862"`
+	CreatedAt  string `json:"created_at" example:"2024-06-10T08:00:00Z"`
+}
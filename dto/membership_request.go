@@ -0,0 +1,17 @@
+package dto
+
+type CreateMembershipPlanRequest struct {
+	Name         string `json:"name" example:"Basic" validate:"required"`
+	Price        int    `json:"price" example:"50000" validate:"required,gte=1"`
+	DurationDays int    `json:"duration_days" example:"30" validate:"required,gte=1"`
+	RentalQuota  int    `json:"rental_quota" example:"3" validate:"gte=0"`
+}
+
+type SubscribeMembershipRequest struct {
+	PlanID    uint `json:"plan_id" example:"1" validate:"required"`
+	AutoRenew bool `json:"auto_renew" example:"true"`
+}
+
+type MembershipAutoRenewRequest struct {
+	AutoRenew bool `json:"auto_renew" example:"false"`
+}
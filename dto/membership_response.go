@@ -0,0 +1,47 @@
+package dto
+
+type MembershipPlanResponse struct {
+	Status  string                     `json:"status" example:"success"`
+	Code    int                        `json:"code" example:"201"`
+	Message string                     `json:"message" example:"Success Create Membership Plan"`
+	Data    MembershipPlanDataResponse `json:"data"`
+}
+
+type MembershipPlanListResponse struct {
+	Status  string                       `json:"status" example:"success"`
+	Code    int                          `json:"code" example:"200"`
+	Message string                       `json:"message" example:"Success Get Membership Plans"`
+	Data    []MembershipPlanDataResponse `json:"data"`
+}
+
+type MembershipPlanDataResponse struct {
+	ID           uint   `json:"id" example:"1"`
+	Name         string `json:"name" example:"Basic"`
+	Price        int    `json:"price" example:"50000"`
+	DurationDays int    `json:"duration_days" example:"30"`
+	RentalQuota  int    `json:"rental_quota" example:"3"`
+	Unlimited    bool   `json:"unlimited" example:"false"`
+}
+
+type MembershipResponse struct {
+	Status  string                 `json:"status" example:"success"`
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Success Get Membership"`
+	Data    MembershipDataResponse `json:"data"`
+}
+
+// MembershipDataResponse is a running membership. RemainingQuota is left out for an
+// unlimited plan.
+type MembershipDataResponse struct {
+	MembershipID   uint   `json:"membership_id" example:"7"`
+	PlanID         uint   `json:"plan_id" example:"1"`
+	PlanName       string `json:"plan_name" example:"Basic"`
+	Price          int    `json:"price" example:"50000"`
+	StartsAt       string `json:"starts_at" example:"2025-07-01T10:00:00+07:00"`
+	ExpiresAt      string `json:"expires_at" example:"2025-07-31T10:00:00+07:00"`
+	AutoRenew      bool   `json:"auto_renew" example:"true"`
+	Unlimited      bool   `json:"unlimited" example:"false"`
+	RentalQuota    int    `json:"rental_quota" example:"3"`
+	RentalsUsed    int    `json:"rentals_used" example:"1"`
+	RemainingQuota *int   `json:"remaining_quota,omitempty" example:"2"`
+}
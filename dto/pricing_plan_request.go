@@ -0,0 +1,9 @@
+package dto
+
+type CreatePricingPlanRequest struct {
+	BookID       *uint  `json:"book_id,omitempty" example:"1"`
+	Category     string `json:"category,omitempty" example:"Self Development"`
+	Name         string `json:"name" example:"14 days" validate:"required"`
+	DurationDays int    `json:"duration_days" example:"14" validate:"required,gte=1"`
+	Price        int    `json:"price" example:"35000" validate:"gte=0"`
+}
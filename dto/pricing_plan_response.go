@@ -0,0 +1,24 @@
+package dto
+
+type PricingPlanResponse struct {
+	Status  string                  `json:"status" example:"success"`
+	Code    int                     `json:"code" example:"201"`
+	Message string                  `json:"message" example:"Success Create Pricing Plan"`
+	Data    PricingPlanDataResponse `json:"data"`
+}
+
+type PricingPlanListResponse struct {
+	Status  string                    `json:"status" example:"success"`
+	Code    int                       `json:"code" example:"200"`
+	Message string                    `json:"message" example:"Success Get Pricing Plans"`
+	Data    []PricingPlanDataResponse `json:"data"`
+}
+
+type PricingPlanDataResponse struct {
+	ID           uint   `json:"id" example:"1"`
+	BookID       *uint  `json:"book_id,omitempty" example:"1"`
+	Category     string `json:"category,omitempty" example:"Self Development"`
+	Name         string `json:"name" example:"14 days"`
+	DurationDays int    `json:"duration_days" example:"14"`
+	Price        int    `json:"price" example:"35000"`
+}
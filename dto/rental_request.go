@@ -1,5 +1,26 @@
 package dto
 
 type RentalRequest struct {
-	BookID uint `json:"book_id" example:"1" validate:"required"`
+	BookID uint  `json:"book_id" example:"1" validate:"required"`
+	PlanID *uint `json:"plan_id,omitempty" example:"2"`
+}
+
+type CheckoutRequest struct {
+	Items       []CheckoutItemRequest `json:"items" validate:"required,min=1"`
+	VoucherCode string                `json:"voucher_code,omitempty" example:"FIRSTFREE"`
+}
+
+type CheckoutItemRequest struct {
+	BookID   uint  `json:"book_id" example:"1" validate:"required"`
+	PlanID   *uint `json:"plan_id,omitempty" example:"2"`
+	Quantity int   `json:"quantity" example:"1" validate:"required,gte=1"`
+}
+
+type AdminRentalActionRequest struct {
+	Reason string `json:"reason" example:"Book returned at the front desk" validate:"required"`
+}
+
+type ReportDamageRequest struct {
+	Condition string `json:"condition" example:"Damaged" enums:"Fair,Poor,Damaged" validate:"required"`
+	Notes     string `json:"notes" example:"Water damage on the cover" validate:"required"`
 }
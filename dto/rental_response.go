@@ -8,10 +8,18 @@ type RentalResponse struct {
 }
 
 type RentalDataResponse struct {
-	BookID     uint   `json:"book_id" example:"1" validate:"required"`
-	RentDate   string `json:"rent_date" example:"2020-01-01"`
-	ReturnDate string `json:"return_date" example:"2020-01-01"`
-	Status     string `json:"status" example:"borrowed"`
+	RentalID      uint   `json:"rental_id,omitempty" example:"1"`
+	BookID        uint   `json:"book_id" example:"1" validate:"required"`
+	BookCopyID    *uint  `json:"book_copy_id,omitempty" example:"4"`
+	PricingPlanID *uint  `json:"pricing_plan_id,omitempty" example:"2"`
+	DurationDays  int    `json:"duration_days" example:"14"`
+	Price         int    `json:"price" example:"35000"`
+	Discount      int    `json:"discount,omitempty" example:"5000"`
+	RentDate      string `json:"rent_date" example:"2020-01-01"`
+	ReturnDate    string `json:"return_date" example:"2020-01-01"`
+	LateFee       int    `json:"late_fee,omitempty" example:"4000"`
+	DamageFee     int    `json:"damage_fee,omitempty" example:"25000"`
+	Status        string `json:"status" example:"borrowed"`
 }
 
 type RentalUserResponse struct {
@@ -22,10 +30,106 @@ type RentalUserResponse struct {
 }
 
 type RentalUserDataResponse struct {
+	RentalID   uint                  `json:"rental_id" example:"1"`
+	BookID     uint                  `json:"book_id" example:"2"`
+	BookTitle  string                `json:"book_title" example:"Atomic Habits"`
+	RentDate   string                `json:"rent_date" example:"2025-07-03"`
+	ReturnDate string                `json:"return_date" example:"2025-07-10"`
+	ReturnedAt string                `json:"returned_at" example:"2025-07-09"`
+	LateFee    int                   `json:"late_fee" example:"0"`
+	DamageFee  int                   `json:"damage_fee" example:"0"`
+	Status     string                `json:"status" example:"Borrowed"`
+	Events     []RentalEventResponse `json:"events"`
+}
+
+type RentalEventResponse struct {
+	Type       string `json:"type" example:"damaged"`
+	FromStatus string `json:"from_status" example:"Borrowed"`
+	ToStatus   string `json:"to_status" example:"Returned"`
+	Reason     string `json:"reason,omitempty" example:"Water damage on the cover"`
+	Condition  string `json:"condition,omitempty" example:"Damaged"`
+	Amount     int    `json:"amount" example:"-25000"`
+	CreatedAt  string `json:"created_at" example:"2025-07-09T10:00:00+07:00"`
+}
+
+type ReturnRentalResponse struct {
+	Status  string                   `json:"status" example:"success"`
+	Code    int                      `json:"code" example:"200"`
+	Message string                   `json:"message" example:"Success Return Rental"`
+	Data    ReturnRentalDataResponse `json:"data"`
+}
+
+type ReturnRentalDataResponse struct {
+	RentalID   uint   `json:"rental_id" example:"1"`
+	BookID     uint   `json:"book_id" example:"2"`
+	RentDate   string `json:"rent_date" example:"2025-07-03"`
+	ReturnDate string `json:"return_date" example:"2025-07-10"`
+	ReturnedAt string `json:"returned_at" example:"2025-07-09"`
+	LateFee    int    `json:"late_fee" example:"4000"`
+	Status     string `json:"status" example:"Returned"`
+}
+
+type ExtendRentalResponse struct {
+	Status  string                   `json:"status" example:"success"`
+	Code    int                      `json:"code" example:"200"`
+	Message string                   `json:"message" example:"Success Extend Rental"`
+	Data    ExtendRentalDataResponse `json:"data"`
+}
+
+type ExtendRentalDataResponse struct {
+	RentalID      uint   `json:"rental_id" example:"1"`
+	BookID        uint   `json:"book_id" example:"2"`
+	ReturnDate    string `json:"return_date" example:"2025-07-17"`
+	RenewalCount  int    `json:"renewal_count" example:"1"`
+	ExtensionCost int    `json:"extension_cost" example:"20000"`
+	Status        string `json:"status" example:"Borrowed"`
+}
+
+type CheckoutResponse struct {
+	Status  string               `json:"status" example:"Created"`
+	Code    int                  `json:"code" example:"201"`
+	Message string               `json:"message" example:"Success Checkout"`
+	Data    CheckoutDataResponse `json:"data"`
+}
+
+type CheckoutDataResponse struct {
+	CheckoutID uint                 `json:"checkout_id" example:"1"`
+	TotalCost  int                  `json:"total_cost" example:"45000"`
+	Discount   int                  `json:"discount,omitempty" example:"10000"`
+	Rentals    []RentalDataResponse `json:"rentals"`
+}
+
+type PaginationMeta struct {
+	Page  int   `json:"page" example:"1"`
+	Limit int   `json:"limit" example:"20"`
+	Total int64 `json:"total" example:"57"`
+}
+
+type AdminRentalListResponse struct {
+	Status  string                    `json:"status" example:"success"`
+	Code    int                       `json:"code" example:"200"`
+	Message string                    `json:"message" example:"Success Get Rentals"`
+	Data    []AdminRentalDataResponse `json:"data"`
+	Meta    PaginationMeta            `json:"meta"`
+}
+
+type AdminRentalDataResponse struct {
 	RentalID   uint   `json:"rental_id" example:"1"`
+	UserID     uint   `json:"user_id" example:"3"`
+	UserEmail  string `json:"user_email" example:"reader@mail.com"`
 	BookID     uint   `json:"book_id" example:"2"`
 	BookTitle  string `json:"book_title" example:"Atomic Habits"`
+	Price      int    `json:"price" example:"20000"`
 	RentDate   string `json:"rent_date" example:"2025-07-03"`
 	ReturnDate string `json:"return_date" example:"2025-07-10"`
+	ReturnedAt string `json:"returned_at" example:"2025-07-09"`
+	LateFee    int    `json:"late_fee" example:"0"`
 	Status     string `json:"status" example:"Borrowed"`
 }
+
+type AdminRentalActionResponse struct {
+	Status  string             `json:"status" example:"success"`
+	Code    int                `json:"code" example:"200"`
+	Message string             `json:"message" example:"Success Cancel Rental"`
+	Data    RentalDataResponse `json:"data"`
+}
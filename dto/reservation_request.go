@@ -0,0 +1,5 @@
+package dto
+
+type ReservationRequest struct {
+	BookID uint `json:"book_id" example:"1" validate:"required"`
+}
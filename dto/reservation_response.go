@@ -0,0 +1,25 @@
+package dto
+
+type ReservationResponse struct {
+	Status  string                  `json:"status" example:"success"`
+	Code    int                     `json:"code" example:"201"`
+	Message string                  `json:"message" example:"Success Create Reservation"`
+	Data    ReservationDataResponse `json:"data"`
+}
+
+type ReservationListResponse struct {
+	Status  string                    `json:"status" example:"success"`
+	Code    int                       `json:"code" example:"200"`
+	Message string                    `json:"message" example:"Success Get Reservations"`
+	Data    []ReservationDataResponse `json:"data"`
+}
+
+type ReservationDataResponse struct {
+	ReservationID uint   `json:"reservation_id" example:"1"`
+	BookID        uint   `json:"book_id" example:"2"`
+	BookTitle     string `json:"book_title,omitempty" example:"Atomic Habits"`
+	Status        string `json:"status" example:"Waiting"`
+	QueuePosition int64  `json:"queue_position,omitempty" example:"3"`
+	HoldExpiresAt string `json:"hold_expires_at,omitempty" example:"2025-07-05 10:00:00"`
+	CreatedAt     string `json:"created_at" example:"2025-07-03 10:00:00"`
+}
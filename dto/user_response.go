@@ -8,7 +8,8 @@ type UserResponse struct {
 }
 
 type UserDataResponse struct {
-	Name    string `json:"name" example:"John doe"`
-	Email   string `json:"email" example:"johndoe@example.com"`
-	Deposit int    `json:"deposit" example:"4"`
+	Name       string                  `json:"name" example:"John doe"`
+	Email      string                  `json:"email" example:"johndoe@example.com"`
+	Deposit    int                     `json:"deposit" example:"4"`
+	Membership *MembershipDataResponse `json:"membership"`
 }
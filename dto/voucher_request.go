@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type CreateVoucherRequest struct {
+	Code           string     `json:"code" example:"TOPUP10" validate:"required"`
+	Type           string     `json:"type" example:"percentage" enums:"percentage,fixed,bonus_credit" validate:"required"`
+	Scope          string     `json:"scope" example:"top_up" enums:"rental,top_up" validate:"required"`
+	Value          int        `json:"value" example:"10" validate:"required,gte=1"`
+	MaxDiscount    int        `json:"max_discount,omitempty" example:"50000"`
+	MinAmount      int        `json:"min_amount,omitempty" example:"100000"`
+	Categories     []string   `json:"categories,omitempty" example:"Novel"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty" example:"2025-08-01T00:00:00+07:00"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty" example:"2025-09-01T00:00:00+07:00"`
+	MaxUses        int        `json:"max_uses,omitempty" example:"500"`
+	MaxUsesPerUser int        `json:"max_uses_per_user,omitempty" example:"1"`
+}
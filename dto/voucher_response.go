@@ -0,0 +1,32 @@
+package dto
+
+type VoucherResponse struct {
+	Status  string              `json:"status" example:"success"`
+	Code    int                 `json:"code" example:"201"`
+	Message string              `json:"message" example:"Success Create Voucher"`
+	Data    VoucherDataResponse `json:"data"`
+}
+
+type VoucherListResponse struct {
+	Status  string                `json:"status" example:"success"`
+	Code    int                   `json:"code" example:"200"`
+	Message string                `json:"message" example:"Success Get Vouchers"`
+	Data    []VoucherDataResponse `json:"data"`
+	Meta    PaginationMeta        `json:"meta"`
+}
+
+type VoucherDataResponse struct {
+	ID             uint     `json:"id" example:"3"`
+	Code           string   `json:"code" example:"TOPUP10"`
+	Type           string   `json:"type" example:"percentage"`
+	Scope          string   `json:"scope" example:"top_up"`
+	Value          int      `json:"value" example:"10"`
+	MaxDiscount    int      `json:"max_discount,omitempty" example:"50000"`
+	MinAmount      int      `json:"min_amount,omitempty" example:"100000"`
+	Categories     []string `json:"categories,omitempty" example:"Novel"`
+	ValidFrom      string   `json:"valid_from,omitempty" example:"2025-08-01T00:00:00+07:00"`
+	ValidUntil     string   `json:"valid_until,omitempty" example:"2025-09-01T00:00:00+07:00"`
+	MaxUses        int      `json:"max_uses,omitempty" example:"500"`
+	MaxUsesPerUser int      `json:"max_uses_per_user,omitempty" example:"1"`
+	Active         bool     `json:"active" example:"true"`
+}
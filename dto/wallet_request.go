@@ -0,0 +1,8 @@
+package dto
+
+type BalanceAdjustmentRequest struct {
+	Amount        int    `json:"amount" example:"-15000" validate:"required"`
+	ReasonCode    string `json:"reason_code" example:"correction" validate:"required"`
+	Note          string `json:"note" example:"Late fee charged twice for rental 8" validate:"required"`
+	AllowNegative bool   `json:"allow_negative" example:"false"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+type WalletTransactionListResponse struct {
+	Status  string                      `json:"status" example:"success"`
+	Code    int                         `json:"code" example:"200"`
+	Message string                      `json:"message" example:"Success Get Wallet Transactions"`
+	Balance int                         `json:"balance" example:"45000"`
+	Data    []WalletTransactionResponse `json:"data"`
+	Meta    PaginationMeta              `json:"meta"`
+}
+
+type WalletTransactionResponse struct {
+	ID             uint    `json:"id" example:"12"`
+	Type           string  `json:"type" example:"rental_charge"`
+	Amount         int     `json:"amount" example:"-15000"`
+	RunningBalance int     `json:"running_balance" example:"45000"`
+	DepositOrderID *string `json:"deposit_order_id,omitempty" example:"ORDER-3-1718000000"`
+	RentalID       *uint   `json:"rental_id,omitempty" example:"8"`
+	Description    string  `json:"description" example:"Rental of Atomic Habits"`
+	CreatedAt      string  `json:"created_at" example:"2024-06-10T08:00:00Z"`
+}
+
+type BalanceAdjustmentResponse struct {
+	Status  string                        `json:"status" example:"success"`
+	Code    int                           `json:"code" example:"201"`
+	Message string                        `json:"message" example:"Success Adjust Balance"`
+	Data    BalanceAdjustmentDataResponse `json:"data"`
+}
+
+type BalanceAdjustmentDataResponse struct {
+	AdjustmentID  uint   `json:"adjustment_id" example:"5"`
+	UserID        uint   `json:"user_id" example:"3"`
+	AdminID       uint   `json:"admin_id" example:"1"`
+	Amount        int    `json:"amount" example:"-15000"`
+	ReasonCode    string `json:"reason_code" example:"correction"`
+	Note          string `json:"note" example:"Late fee charged twice for rental 8"`
+	AllowNegative bool   `json:"allow_negative" example:"false"`
+	BalanceAfter  int    `json:"balance_after" example:"30000"`
+	CreatedAt     string `json:"created_at" example:"2025-07-10T09:00:00+07:00"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+type WithdrawalRequest struct {
+	Amount int    `json:"amount" example:"40000" validate:"required"`
+	Note   string `json:"note" example:"Moving out of town, please transfer to BCA 1234567890"`
+}
+
+type WithdrawalActionRequest struct {
+	Reason string `json:"reason" example:"Bank details verified"`
+}
+
+type WithdrawalPaidRequest struct {
+	PayoutRef string `json:"payout_ref" example:"TRF-20250709-0012" validate:"required"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+type WithdrawalResponse struct {
+	Status  string                 `json:"status" example:"success"`
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Success Get Withdrawal"`
+	Data    WithdrawalDataResponse `json:"data"`
+}
+
+type WithdrawalListResponse struct {
+	Status  string                   `json:"status" example:"success"`
+	Code    int                      `json:"code" example:"200"`
+	Message string                   `json:"message" example:"Success Get Withdrawals"`
+	Data    []WithdrawalDataResponse `json:"data"`
+	Meta    PaginationMeta           `json:"meta"`
+}
+
+type WithdrawalDataResponse struct {
+	WithdrawalID uint                      `json:"withdrawal_id" example:"4"`
+	UserID       uint                      `json:"user_id" example:"3"`
+	UserEmail    string                    `json:"user_email,omitempty" example:"reader@mail.com"`
+	Amount       int                       `json:"amount" example:"40000"`
+	Status       string                    `json:"status" example:"approved"`
+	Note         string                    `json:"note,omitempty" example:"Moving out of town, please transfer to BCA 1234567890"`
+	PayoutRef    string                    `json:"payout_ref,omitempty" example:"TRF-20250709-0012"`
+	CreatedAt    string                    `json:"created_at" example:"2025-07-09T10:00:00+07:00"`
+	Events       []WithdrawalEventResponse `json:"events,omitempty"`
+}
+
+type WithdrawalEventResponse struct {
+	FromStatus string `json:"from_status,omitempty" example:"pending"`
+	ToStatus   string `json:"to_status" example:"approved"`
+	Reason     string `json:"reason,omitempty" example:"Bank details verified"`
+	ActorID    uint   `json:"actor_id" example:"1"`
+	CreatedAt  string `json:"created_at" example:"2025-07-09T11:00:00+07:00"`
+}
@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnknownCharge = errors.New("no charge for this order")
+
+// fakeStatusCodes is the status_code Midtrans sends along with each transaction_status.
+var fakeStatusCodes = map[string]string{
+	"pending":    "201",
+	"capture":    "200",
+	"settlement": "200",
+	"deny":       "202",
+	"cancel":     "200",
+	"expire":     "407",
+	"failure":    "202",
+}
+
+// fakeFraudStatus is the fraud_status sent along with transactionStatus. Card payments
+// of the fake gateway always pass the fraud check.
+func fakeFraudStatus(transactionStatus string) string {
+	if transactionStatus == "capture" {
+		return "accept"
+	}
+	return ""
+}
+
+// FakeGateway is an in-process payment gateway for local development and end-to-end
+// tests. No money moves: its payment page, or a direct call to Notify, posts the
+// signed notification Midtrans would send to the deposit webhook.
+type FakeGateway struct {
+	baseURL    string
+	webhookURL string
+	serverKey  string
+	client     *http.Client
+
+	mu       sync.Mutex
+	charges  map[string]int
+	statuses map[string]string
+}
+
+func NewFakeGateway(baseURL, webhookURL, serverKey string) *FakeGateway {
+	return &FakeGateway{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		webhookURL: webhookURL,
+		serverKey:  serverKey,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		charges:    map[string]int{},
+		statuses:   map[string]string{},
+	}
+}
+
+func (g *FakeGateway) CreateCharge(orderID string, amount int) (Charge, error) {
+	g.mu.Lock()
+	g.charges[orderID] = amount
+	g.statuses[orderID] = "pending"
+	g.mu.Unlock()
+
+	return Charge{
+		OrderID:     orderID,
+		Token:       "fake-" + orderID,
+		RedirectURL: g.baseURL + "/fake-pay/" + url.PathEscape(orderID),
+	}, nil
+}
+
+// GetStatus reports the last status fired for a charge, pending until then.
+func (g *FakeGateway) GetStatus(orderID string) (TransactionStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	amount, ok := g.charges[orderID]
+	if !ok {
+		return TransactionStatus{}, ErrTransactionNotFound
+	}
+	status := g.statuses[orderID]
+	return TransactionStatus{
+		OrderID:           orderID,
+		StatusCode:        fakeStatusCodes[status],
+		GrossAmount:       fmt.Sprintf("%d.00", amount),
+		TransactionStatus: status,
+		FraudStatus:       fakeFraudStatus(status),
+	}, nil
+}
+
+// Settle fires a settlement notification, as when the user pays.
+func (g *FakeGateway) Settle(orderID string) error {
+	return g.Notify(orderID, "settlement")
+}
+
+// Expire fires an expiry notification, as when the user never pays.
+func (g *FakeGateway) Expire(orderID string) error {
+	return g.Notify(orderID, "expire")
+}
+
+// Notify posts a signed notification with transactionStatus for the charge of orderID
+// to the webhook, and fails unless the webhook answers with a 2xx status. The status
+// is kept for GetStatus either way, as a lost notification would be at Midtrans.
+func (g *FakeGateway) Notify(orderID string, transactionStatus string) error {
+	g.mu.Lock()
+	amount, ok := g.charges[orderID]
+	g.mu.Unlock()
+	if !ok {
+		return ErrUnknownCharge
+	}
+
+	statusCode, ok := fakeStatusCodes[transactionStatus]
+	if !ok {
+		return fmt.Errorf("unsupported transaction status %q", transactionStatus)
+	}
+	g.mu.Lock()
+	g.statuses[orderID] = transactionStatus
+	g.mu.Unlock()
+	grossAmount := fmt.Sprintf("%d.00", amount)
+
+	payload := map[string]string{
+		"order_id":           orderID,
+		"status_code":        statusCode,
+		"gross_amount":       grossAmount,
+		"signature_key":      Signature(orderID, statusCode, grossAmount, g.serverKey),
+		"transaction_status": transactionStatus,
+		"transaction_time":   time.Now().Format("2006-01-02 15:04:05"),
+		"payment_type":       "fake",
+	}
+	if fraudStatus := fakeFraudStatus(transactionStatus); fraudStatus != "" {
+		payload["fraud_status"] = fraudStatus
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	res, err := g.client.Post(g.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %d", res.StatusCode)
+	}
+	return nil
+}
+
+// ServeHTTP is the fake payment page behind RedirectURL. Opening
+// /fake-pay/{order_id}?status=expire fires that notification; without status the
+// order is settled.
+func (g *FakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "settlement"
+	}
+
+	err := g.Notify(orderID, status)
+	switch {
+	case errors.Is(err, ErrUnknownCharge):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	default:
+		fmt.Fprintf(w, "Order %s is now %s\n", orderID, status)
+	}
+}
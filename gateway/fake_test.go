@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeGateway_SettleSendsSignedNotification(t *testing.T) {
+	var received map[string]string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	fake := NewFakeGateway("http://localhost:8080/", webhook.URL, "test-key")
+	charge, err := fake.CreateCharge("ORDER-3-1", 50000)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/fake-pay/ORDER-3-1", charge.RedirectURL)
+
+	status, err := fake.GetStatus("ORDER-3-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", status.TransactionStatus)
+
+	assert.NoError(t, fake.Settle("ORDER-3-1"))
+	assert.Equal(t, "ORDER-3-1", received["order_id"])
+	assert.Equal(t, "settlement", received["transaction_status"])
+	assert.Equal(t, "50000.00", received["gross_amount"])
+	assert.Equal(t, Signature("ORDER-3-1", "200", "50000.00", "test-key"), received["signature_key"])
+
+	assert.NoError(t, fake.Expire("ORDER-3-1"))
+	assert.Equal(t, "expire", received["transaction_status"])
+	assert.Equal(t, "407", received["status_code"])
+}
+
+func TestFakeGateway_UnknownOrder(t *testing.T) {
+	fake := NewFakeGateway("http://localhost:8080", "http://127.0.0.1:0", "test-key")
+	assert.ErrorIs(t, fake.Settle("ORDER-404"), ErrUnknownCharge)
+	_, err := fake.GetStatus("ORDER-404")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	rec := httptest.NewRecorder()
+	fake.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fake-pay/ORDER-404", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestFakeGateway_WebhookRejects(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer webhook.Close()
+
+	fake := NewFakeGateway("http://localhost:8080", webhook.URL, "wrong-key")
+	_, err := fake.CreateCharge("ORDER-3-1", 50000)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	fake.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fake-pay/ORDER-3-1?status=settlement", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+)
+
+var ErrTransactionNotFound = errors.New("transaction not found at the gateway")
+
+// Charge is a payment created at the gateway for a deposit order, that the user
+// completes by following RedirectURL.
+type Charge struct {
+	OrderID     string
+	Token       string
+	RedirectURL string
+}
+
+// TransactionStatus is what the gateway currently knows about the payment of an order,
+// in the same terms as a notification.
+type TransactionStatus struct {
+	OrderID           string
+	StatusCode        string
+	GrossAmount       string
+	TransactionStatus string
+	FraudStatus       string
+}
+
+// PaymentGateway creates payments for deposit orders. The outcome of a payment
+// arrives later as a notification on the deposit webhook, and can be asked for with
+// GetStatus when a notification never arrives.
+type PaymentGateway interface {
+	CreateCharge(orderID string, amount int) (Charge, error)
+	GetStatus(orderID string) (TransactionStatus, error)
+}
+
+// Signature is the signature_key of a payment notification: the SHA512 hex digest of
+// order_id, status_code, gross_amount and the server key.
+func Signature(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
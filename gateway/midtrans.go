@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/snap"
+)
+
+type midtransGateway struct {
+	client     snap.Client
+	serverKey  string
+	apiURL     string
+	httpClient *http.Client
+}
+
+// NewMidtransGateway returns a gateway backed by Midtrans Snap, in the production
+// environment when production is set and in the sandbox otherwise. apiURL overrides
+// the base URL of the transaction status API, for a local stub; empty uses the one of
+// the environment.
+func NewMidtransGateway(serverKey string, production bool, apiURL string) PaymentGateway {
+	env := midtrans.Sandbox
+	if production {
+		env = midtrans.Production
+	}
+	if apiURL == "" {
+		apiURL = env.BaseUrl()
+	}
+
+	var client snap.Client
+	client.New(serverKey, env)
+	return &midtransGateway{
+		client:     client,
+		serverKey:  serverKey,
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *midtransGateway) CreateCharge(orderID string, amount int) (Charge, error) {
+	res, midErr := g.client.CreateTransaction(&snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  orderID,
+			GrossAmt: int64(amount),
+		},
+	})
+	if midErr != nil {
+		return Charge{}, fmt.Errorf("midtrans error: %v", midErr.Message)
+	}
+
+	return Charge{OrderID: orderID, Token: res.Token, RedirectURL: res.RedirectURL}, nil
+}
+
+// GetStatus asks the Midtrans transaction status API about an order. Orders the user
+// never paid through Snap are unknown to Midtrans and give ErrTransactionNotFound.
+func (g *midtransGateway) GetStatus(orderID string) (TransactionStatus, error) {
+	req, err := http.NewRequest(http.MethodGet, g.apiURL+"/v2/"+url.PathEscape(orderID)+"/status", nil)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+	req.SetBasicAuth(g.serverKey, "")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := g.httpClient.Do(req)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		StatusCode        string `json:"status_code"`
+		StatusMessage     string `json:"status_message"`
+		OrderID           string `json:"order_id"`
+		GrossAmount       string `json:"gross_amount"`
+		TransactionStatus string `json:"transaction_status"`
+		FraudStatus       string `json:"fraud_status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return TransactionStatus{}, fmt.Errorf("midtrans status %d: %v", res.StatusCode, err)
+	}
+
+	//Midtrans answers 200 with the error code in the body
+	if res.StatusCode == http.StatusNotFound || body.StatusCode == "404" {
+		return TransactionStatus{}, ErrTransactionNotFound
+	}
+	if res.StatusCode != http.StatusOK || body.TransactionStatus == "" {
+		return TransactionStatus{}, fmt.Errorf("midtrans status %s: %s", body.StatusCode, body.StatusMessage)
+	}
+
+	return TransactionStatus{
+		OrderID:           body.OrderID,
+		StatusCode:        body.StatusCode,
+		GrossAmount:       body.GrossAmount,
+		TransactionStatus: body.TransactionStatus,
+		FraudStatus:       body.FraudStatus,
+	}, nil
+}
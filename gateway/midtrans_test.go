@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStatusStub serves the Midtrans transaction status endpoint from canned bodies.
+func newStatusStub(t *testing.T, bodies map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "test-key", user)
+
+		body, found := bodies[r.URL.Path]
+		if !found {
+			body = `{"status_code": "404", "status_message": "Transaction doesn't exist."}`
+		}
+		if body == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			body = `{"status_code": "500", "status_message": "Sorry. Our system is recovering from unexpected issues."}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestMidtransGateway_GetStatus(t *testing.T) {
+	stub := newStatusStub(t, map[string]string{
+		"/v2/ORDER-3-1/status": `{"status_code": "200", "order_id": "ORDER-3-1", "gross_amount": "50000.00", "transaction_status": "settlement"}`,
+		"/v2/ORDER-3-2/status": "",
+		"/v2/ORDER-3-3/status": `{"status_code": "201", "order_id": "ORDER-3-3", "gross_amount": "50000.00", "transaction_status": "capture", "fraud_status": "challenge"}`,
+	})
+	defer stub.Close()
+
+	midtransGateway := NewMidtransGateway("test-key", false, stub.URL+"/")
+
+	status, err := midtransGateway.GetStatus("ORDER-3-1")
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionStatus{OrderID: "ORDER-3-1", StatusCode: "200", GrossAmount: "50000.00", TransactionStatus: "settlement"}, status)
+
+	status, err = midtransGateway.GetStatus("ORDER-3-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "challenge", status.FraudStatus)
+
+	_, err = midtransGateway.GetStatus("ORDER-404")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	_, err = midtransGateway.GetStatus("ORDER-3-2")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTransactionNotFound)
+}
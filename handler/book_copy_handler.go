@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type BookCopyHandler struct {
+	Service service.BookCopyService
+}
+
+func NewBookCopyHandler(s service.BookCopyService) *BookCopyHandler {
+	return &BookCopyHandler{Service: s}
+}
+
+func toBookCopyData(bookCopy model.BookCopy) dto.BookCopyDataResponse {
+	return dto.BookCopyDataResponse{
+		ID:            bookCopy.ID,
+		BookID:        bookCopy.BookID,
+		BookTitle:     bookCopy.Book.Name,
+		Barcode:       bookCopy.Barcode,
+		Condition:     bookCopy.Condition,
+		ShelfLocation: bookCopy.ShelfLocation,
+		Status:        bookCopy.Status,
+	}
+}
+
+// bookCopyError writes the response for an error of the book copy service.
+func bookCopyError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrBookCopyNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book copy not found",
+		})
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	case errors.Is(err, service.ErrInvalidBookCopy):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid condition or status",
+		})
+	case errors.Is(err, service.ErrBarcodeTaken):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Barcode already in use",
+		})
+	case errors.Is(err, service.ErrBookCopyInUse):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Book copy is on loan or on hold",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		Status:  "Internal Server Error",
+		Code:    http.StatusInternalServerError,
+		Message: message,
+	})
+}
+
+// GetCopies godoc
+// @Summary List the copies of a book
+// @Description Admin only. Lists every physical copy of a book with its barcode, condition, shelf location and status.
+// @Tags Book Copies
+// @Security BearerAuth
+// @Produce json
+// @Param book_id query int true "Book ID"
+// @Success 200 {object} dto.BookCopyListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /copies [get]
+func (h *BookCopyHandler) GetCopies(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	bookID, err := strconv.Atoi(c.QueryParam("book_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "book_id is required",
+		})
+	}
+
+	copies, err := h.Service.GetCopiesByBookID(uint(bookID))
+	if err != nil {
+		return bookCopyError(c, err, "Get Book Copies is Failed")
+	}
+
+	data := make([]dto.BookCopyDataResponse, 0, len(copies))
+	for _, bookCopy := range copies {
+		data = append(data, toBookCopyData(bookCopy))
+	}
+
+	return c.JSON(http.StatusOK, dto.BookCopyListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Book Copies",
+		Data:    data,
+	})
+}
+
+// GetCopyByBarcode godoc
+// @Summary Scan a copy by barcode
+// @Description Admin only. Front desk lookup of a copy by the barcode on its label, with the rental it is out on.
+// @Tags Book Copies
+// @Security BearerAuth
+// @Produce json
+// @Param barcode path string true "Barcode"
+// @Success 200 {object} dto.BookCopyResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /copies/barcode/{barcode} [get]
+func (h *BookCopyHandler) GetCopyByBarcode(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	detail, err := h.Service.GetCopyByBarcode(c.Param("barcode"))
+	if err != nil {
+		return bookCopyError(c, err, "Get Book Copy is Failed")
+	}
+
+	data := toBookCopyData(detail.BookCopy)
+	if detail.Rental != nil {
+		returnDate := ""
+		if detail.Rental.ReturnDate != nil {
+			returnDate = detail.Rental.ReturnDate.Format("2006-01-02")
+		}
+		data.Rental = &dto.BookCopyRentalResponse{
+			RentalID:   detail.Rental.ID,
+			UserID:     detail.Rental.UserID,
+			UserEmail:  detail.Rental.User.Email,
+			RentDate:   detail.Rental.RentDate.Format("2006-01-02"),
+			ReturnDate: returnDate,
+			Status:     detail.Rental.Status,
+		}
+	}
+
+	return c.JSON(http.StatusOK, dto.BookCopyResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Book Copy",
+		Data:    data,
+	})
+}
+
+// CreateCopy godoc
+// @Summary Add a copy of a book
+// @Description Admin only. Registers a new physical copy. A barcode is generated when none is given. The copy goes to the next reservation in line or becomes available.
+// @Tags Book Copies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBookCopyRequest true "Book copy request"
+// @Success 201 {object} dto.BookCopyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /copies [post]
+func (h *BookCopyHandler) CreateCopy(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	var req dto.CreateBookCopyRequest
+	if err := c.Bind(&req); err != nil || req.BookID == 0 {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "book_id is required",
+		})
+	}
+
+	created, err := h.Service.CreateCopy(model.BookCopy{
+		BookID:        req.BookID,
+		Barcode:       req.Barcode,
+		Condition:     req.Condition,
+		ShelfLocation: req.ShelfLocation,
+	})
+	if err != nil {
+		return bookCopyError(c, err, "Create Book Copy is Failed")
+	}
+
+	return c.JSON(http.StatusCreated, dto.BookCopyResponse{
+		Status:  "Created",
+		Code:    http.StatusCreated,
+		Message: "Success Create Book Copy",
+		Data:    toBookCopyData(created),
+	})
+}
+
+// UpdateCopy godoc
+// @Summary Update a copy
+// @Description Admin only. Changes the condition, shelf location or status of a copy. The status of a copy on loan or on hold cannot be changed.
+// @Tags Book Copies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Book copy ID"
+// @Param request body dto.UpdateBookCopyRequest true "Book copy changes"
+// @Success 200 {object} dto.BookCopyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /copies/{id} [put]
+func (h *BookCopyHandler) UpdateCopy(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var req dto.UpdateBookCopyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	updated, err := h.Service.UpdateCopy(uint(id), service.BookCopyUpdate{
+		Condition:     req.Condition,
+		ShelfLocation: req.ShelfLocation,
+		Status:        req.Status,
+	})
+	if err != nil {
+		return bookCopyError(c, err, "Update Book Copy is Failed")
+	}
+
+	return c.JSON(http.StatusOK, dto.BookCopyResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Update Book Copy",
+		Data:    toBookCopyData(updated),
+	})
+}
+
+// DeleteCopy godoc
+// @Summary Delete a copy
+// @Description Admin only. Removes a copy that is neither on loan nor on hold.
+// @Tags Book Copies
+// @Security BearerAuth
+// @Param id path int true "Book copy ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /copies/{id} [delete]
+func (h *BookCopyHandler) DeleteCopy(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	if err := h.Service.DeleteCopy(uint(id)); err != nil {
+		return bookCopyError(c, err, "Delete Book Copy is Failed")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
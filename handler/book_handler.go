@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"pojok-baca-api/dto"
 	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
 	"pojok-baca-api/service"
 	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type ProductHandler struct {
@@ -19,27 +23,151 @@ func NewProductHandler(s service.BookService) *ProductHandler {
 	return &ProductHandler{Service: s}
 }
 
+// toBookData maps a book with its authors to its response.
+func toBookData(book model.Book) dto.GetBookData {
+	isbn := ""
+	if book.ISBN != nil {
+		isbn = *book.ISBN
+	}
+	categories := make([]dto.CategoryRef, 0, len(book.Categories))
+	for _, category := range book.Categories {
+		categories = append(categories, dto.CategoryRef{ID: category.ID, Slug: category.Slug, Name: category.Name})
+	}
+	authors := make([]dto.AuthorData, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		authors = append(authors, dto.AuthorData{ID: author.ID, Name: author.Name})
+	}
+
+	return dto.GetBookData{
+		ID:              book.ID,
+		Name:            book.Name,
+		Stok:            book.Stok,
+		Category:        book.Category,
+		RentalCost:      book.RentalCost,
+		Categories:      categories,
+		ISBN:            isbn,
+		Authors:         authors,
+		Publisher:       book.Publisher,
+		PublicationYear: book.PublicationYear,
+		Language:        book.Language,
+		PageCount:       book.PageCount,
+		Synopsis:        book.Synopsis,
+		CoverURL:        book.CoverURL,
+	}
+}
+
+// bookError answers a failed create or update of a book, with failure as the message of
+// unexpected errors.
+func bookError(c echo.Context, err error, failure string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidBook), errors.Is(err, service.ErrInvalidISBN), errors.Is(err, service.ErrInvalidBookMetadata),
+		errors.Is(err, service.ErrCategoryNotFound):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "BadRequest",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrISBNTaken):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		Status:  "Error",
+		Code:    http.StatusInternalServerError,
+		Message: failure,
+	})
+}
+
+// parseBookFilter reads the catalog query parameters.
+func parseBookFilter(c echo.Context) (repository.BookFilter, error) {
+	filter := repository.BookFilter{
+		Keyword:  c.QueryParam("q"),
+		Category: c.QueryParam("category"),
+		Sort:     c.QueryParam("sort"),
+	}
+
+	intParams := map[string]*int{
+		"min_cost": &filter.MinCost, "max_cost": &filter.MaxCost,
+		"page": &filter.Page, "limit": &filter.Limit,
+	}
+	for name, target := range intParams {
+		if value := c.QueryParam(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = n
+		}
+	}
+
+	if value := c.QueryParam("available"); value != "" {
+		available, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("invalid available")
+		}
+		filter.AvailableOnly = available
+	}
+
+	return filter, nil
+}
+
 // GetBooks godoc
 // @Summary Get all books
-// @Description Retrieve all available books with stock and rental info
+// @Description Search the catalog with stock and rental info, one page at a time
 // @Tags Books
 // @Produce json
+// @Param q query string false "Keyword searched in the book name"
+// @Param category query string false "Category name or slug, also matching its subcategories"
+// @Param available query bool false "Only books with stock left"
+// @Param min_cost query int false "Lowest rental cost"
+// @Param max_cost query int false "Highest rental cost"
+// @Param sort query string false "name, cost or newest, prefix with - for the reverse order"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
 // @Success 200 {object} dto.BookResponse
-// @Failure 409 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
 // @Router /products [get]
 func (h *ProductHandler) GetBooks(c echo.Context) error {
-	products, err := h.Service.GetBooks()
+	filter, err := parseBookFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Status:  "Duplicate",
-			Code:    http.StatusConflict,
-			Message: "Email already exists",
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
 		})
 	}
 
-	var bookResponse []dto.GetAllBooksResponse
+	page, err := h.Service.GetBooks(filter)
+	if errors.Is(err, service.ErrInvalidBookFilter) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Books is Failed",
+		})
+	}
 
-	for _, product := range products {
+	bookResponse := make([]dto.GetAllBooksResponse, 0, len(page.Books))
+
+	for _, product := range page.Books {
 		bookResponse = append(bookResponse, dto.GetAllBooksResponse{
 			ID:         product.ID,
 			Name:       product.Name,
@@ -54,20 +182,23 @@ func (h *ProductHandler) GetBooks(c echo.Context) error {
 		Code:    http.StatusOK,
 		Message: "Success Get All Books",
 		Data:    bookResponse,
+		Meta:    dto.PaginationMeta{Page: page.Page, Limit: page.Limit, Total: page.Total},
+		Links:   pageLinks(c, page.Page, page.Limit, page.Total),
 	})
 }
 
 // CreateBook godoc
 // @Summary Create a new book
-// @Description Only admin users can create books
+// @Description Only admin users can create books. One copy with a generated barcode is created per unit of stok. The book is filed under category_ids, the first being its primary category, or else under the category named by category, which is created if it does not exist.
 // @Tags Books
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param request body dto.GetBookData true "Book creation request"
-// @Success 201 {object} dto.CreateBookResponse
+// @Param request body dto.CreateBookRequest true "Book creation request"
+// @Success 201 {object} dto.GetBookDataResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /products [post]
 func (h *ProductHandler) CreateBook(c echo.Context) error {
@@ -94,34 +225,35 @@ func (h *ProductHandler) CreateBook(c echo.Context) error {
 	}
 
 	newBook := model.Book{
-		Name:       req.Name,
-		Stok:       req.Stok,
-		Category:   req.Category,
-		RentalCost: req.RentalCost,
+		Name:            req.Name,
+		Stok:            req.Stok,
+		Category:        req.Category,
+		RentalCost:      req.RentalCost,
+		ISBN:            &req.ISBN,
+		Publisher:       req.Publisher,
+		PublicationYear: req.PublicationYear,
+		Language:        req.Language,
+		PageCount:       req.PageCount,
+		Synopsis:        req.Synopsis,
+		CoverURL:        req.CoverURL,
+	}
+	for _, name := range req.Authors {
+		newBook.Authors = append(newBook.Authors, model.Author{Name: name})
+	}
+	for _, id := range req.CategoryIDs {
+		newBook.Categories = append(newBook.Categories, model.Category{Model: gorm.Model{ID: id}})
 	}
 
 	createdBook, err := h.Service.Create(newBook)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Status:  "Error",
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to create book",
-		})
-	}
-
-	responseData := dto.GetBookData{
-		ID:         createdBook.ID,
-		Name:       createdBook.Name,
-		Stok:       createdBook.Stok,
-		Category:   createdBook.Category,
-		RentalCost: createdBook.RentalCost,
+		return bookError(c, err, "Failed to create book")
 	}
 
 	return c.JSON(http.StatusCreated, dto.GetBookDataResponse{
 		Status:  "Success",
 		Code:    http.StatusCreated,
 		Message: "Success Create Book",
-		Data: responseData,
+		Data:    toBookData(createdBook),
 	})
 }
 
@@ -132,7 +264,7 @@ func (h *ProductHandler) CreateBook(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
-// @Success 200 {object} dto.BookSwaggerByIDResponse
+// @Success 200 {object} dto.BookByIDResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Router /products/{id} [get]
@@ -161,7 +293,7 @@ func (h *ProductHandler) GetBookByID(c echo.Context) error {
 		Status:  "Success",
 		Code:    http.StatusOK,
 		Message: "Success Get Book",
-		Data:    book,
+		Data:    toBookData(book),
 	})
 }
 
@@ -216,7 +348,7 @@ func (h *ProductHandler) DeleteBookByID(c echo.Context) error {
 
 // UpdateBookByID godoc
 // @Summary Update a book by its ID
-// @Description Only admin can update a book's information
+// @Description Only admin can update a book's information. Bibliographic details left out of the request keep their stored values, and those sent empty are cleared. Stock follows the book copies and cannot be set here.
 // @Tags Books
 // @Security BearerAuth
 // @Accept json
@@ -226,6 +358,8 @@ func (h *ProductHandler) DeleteBookByID(c echo.Context) error {
 // @Success 200 {object} dto.UpdateBookByIDResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /products/{id} [put]
 func (h *ProductHandler) UpdateBookByID(c echo.Context) error {
@@ -266,11 +400,7 @@ func (h *ProductHandler) UpdateBookByID(c echo.Context) error {
 	// Update via service
 	book, err := h.Service.UpdateBookByID(req, uint(id))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Status:  "Error",
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to update book",
-		})
+		return bookError(c, err, "Failed to update book")
 	}
 
 	// Return response
@@ -278,12 +408,6 @@ func (h *ProductHandler) UpdateBookByID(c echo.Context) error {
 		Status:  "Success",
 		Code:    http.StatusOK,
 		Message: "Success Update Book",
-		Data: dto.UpdateResoponse{
-			ID:         uint(book.ID),
-			Name:       book.Name,
-			Stok:       book.Stok,
-			RentalCost: book.RentalCost,
-			Category:   book.Category,
-		},
+		Data:    toBookData(book),
 	})
 }
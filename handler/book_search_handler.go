@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BookSearchHandler struct {
+	Service service.BookSearchService
+}
+
+func NewBookSearchHandler(s service.BookSearchService) *BookSearchHandler {
+	return &BookSearchHandler{Service: s}
+}
+
+// SearchBooks godoc
+// @Summary Search books
+// @Description Full-text search over book names and categories, best matches first, with the matching words highlighted. When nothing matches word for word, books with similar names are returned and fuzzy is set.
+// @Tags Books
+// @Produce json
+// @Param q query string true "Search words"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.BookSearchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /products/search [get]
+func (h *BookSearchHandler) SearchBooks(c echo.Context) error {
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	result, err := h.Service.SearchBooks(c.QueryParam("q"), page, limit)
+	if errors.Is(err, service.ErrEmptySearchQuery) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Search Books is Failed",
+		})
+	}
+
+	data := make([]dto.BookSearchData, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		data = append(data, dto.BookSearchData{
+			ID:         hit.ID,
+			Name:       hit.Name,
+			Stok:       hit.Stok,
+			RentalCost: hit.RentalCost,
+			Category:   hit.Category,
+			Rank:       hit.Rank,
+			Snippet:    hit.Snippet,
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.BookSearchResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Search Books",
+		Data:    data,
+		Fuzzy:   result.Fuzzy,
+		Meta:    dto.PaginationMeta{Page: result.Page, Limit: result.Limit, Total: result.Total},
+		Links:   pageLinks(c, result.Page, result.Limit, result.Total),
+	})
+}
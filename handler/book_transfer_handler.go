@@ -0,0 +1,494 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	transferFormatCSV   = "csv"
+	transferFormatJSONL = "jsonl"
+)
+
+// maxImportSize caps the size of an import file in bytes.
+const maxImportSize = 10 << 20
+
+// exportFlushEvery is how many books an export writes before flushing them to the client.
+const exportFlushEvery = 100
+
+// bookTransferColumns are the columns of a book CSV in the order an export writes them.
+// Authors and categories are separated by listSeparator.
+var bookTransferColumns = []string{
+	"id", "isbn", "name", "category", "categories", "rental_cost", "stok", "authors",
+	"publisher", "publication_year", "language", "page_count", "synopsis", "cover_url",
+}
+
+const listSeparator = ";"
+
+var errMissingNameColumn = errors.New("csv header must have a name column")
+
+type BookTransferHandler struct {
+	Service service.BookTransferService
+}
+
+func NewBookTransferHandler(s service.BookTransferService) *BookTransferHandler {
+	return &BookTransferHandler{Service: s}
+}
+
+// importFormat picks the format of an import from the format query param, or else from
+// the Content-Type of the request.
+func importFormat(c echo.Context) string {
+	if format := c.QueryParam("format"); format != "" {
+		return format
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case "text/csv":
+		return transferFormatCSV
+	case "application/x-ndjson", "application/jsonl", echo.MIMEApplicationJSON:
+		return transferFormatJSONL
+	}
+	return ""
+}
+
+// splitList splits a CSV cell listing several names.
+func splitList(cell string) []string {
+	var names []string
+	for _, name := range strings.Split(cell, listSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// bookFromRecord turns an import record into a book filed under the categories it names,
+// its primary category first.
+func bookFromRecord(record dto.BookTransferRecord) model.Book {
+	book := model.Book{
+		Name:            strings.TrimSpace(record.Name),
+		Stok:            record.Stok,
+		RentalCost:      record.RentalCost,
+		Category:        record.Category,
+		ISBN:            &record.ISBN,
+		Publisher:       record.Publisher,
+		PublicationYear: record.PublicationYear,
+		Language:        record.Language,
+		PageCount:       record.PageCount,
+		Synopsis:        record.Synopsis,
+		CoverURL:        record.CoverURL,
+	}
+	for _, name := range record.Authors {
+		book.Authors = append(book.Authors, model.Author{Name: name})
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range append([]string{record.Category}, record.Categories...) {
+		slug := model.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		book.Categories = append(book.Categories, model.Category{Name: name})
+	}
+	return book
+}
+
+// recordFromBook turns a stored book into an export record.
+func recordFromBook(book model.Book) dto.BookTransferRecord {
+	record := dto.BookTransferRecord{
+		ID:              book.ID,
+		Name:            book.Name,
+		Category:        book.Category,
+		Categories:      make([]string, 0, len(book.Categories)),
+		RentalCost:      book.RentalCost,
+		Stok:            book.Stok,
+		Authors:         make([]string, 0, len(book.Authors)),
+		Publisher:       book.Publisher,
+		PublicationYear: book.PublicationYear,
+		Language:        book.Language,
+		PageCount:       book.PageCount,
+		Synopsis:        book.Synopsis,
+		CoverURL:        book.CoverURL,
+	}
+	if book.ISBN != nil {
+		record.ISBN = *book.ISBN
+	}
+	for _, category := range book.Categories {
+		record.Categories = append(record.Categories, category.Name)
+	}
+	for _, author := range book.Authors {
+		record.Authors = append(record.Authors, author.Name)
+	}
+	return record
+}
+
+// parseBookCSV reads a CSV with a header line naming its columns. Columns are matched
+// case-insensitively; unknown ones are ignored. A row that cannot be read is kept with
+// the reason so every mistake in the file is reported at once.
+func parseBookCSV(r io.Reader) ([]service.BookImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errMissingNameColumn
+	}
+
+	var rows []service.BookImportRow
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, service.BookImportRow{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		var numberErr error
+		number := func(column string) int {
+			value := cell(column)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil && numberErr == nil {
+				numberErr = fmt.Errorf("%s must be a whole number", column)
+			}
+			return n
+		}
+
+		record := dto.BookTransferRecord{
+			ISBN:            cell("isbn"),
+			Name:            cell("name"),
+			Category:        cell("category"),
+			Categories:      splitList(cell("categories")),
+			RentalCost:      number("rental_cost"),
+			Stok:            number("stok"),
+			Authors:         splitList(cell("authors")),
+			Publisher:       cell("publisher"),
+			PublicationYear: number("publication_year"),
+			Language:        cell("language"),
+			PageCount:       number("page_count"),
+			Synopsis:        cell("synopsis"),
+			CoverURL:        cell("cover_url"),
+		}
+		rows = append(rows, service.BookImportRow{Line: line, Book: bookFromRecord(record), Err: numberErr})
+	}
+}
+
+// parseBookJSONL reads one JSON object per line. Blank lines are skipped and unknown
+// fields are ignored.
+func parseBookJSONL(r io.Reader) ([]service.BookImportRow, error) {
+	reader := bufio.NewReader(r)
+	var rows []service.BookImportRow
+	for line := 1; ; line++ {
+		content, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if content = bytes.TrimSpace(content); len(content) > 0 {
+			var record dto.BookTransferRecord
+			if decodeErr := json.Unmarshal(content, &record); decodeErr != nil {
+				rows = append(rows, service.BookImportRow{Line: line, Err: decodeErr})
+			} else {
+				rows = append(rows, service.BookImportRow{Line: line, Book: bookFromRecord(record)})
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+	}
+}
+
+// csvFields lays an export record out in the order of bookTransferColumns.
+func csvFields(record dto.BookTransferRecord) []string {
+	return []string{
+		strconv.FormatUint(uint64(record.ID), 10),
+		record.ISBN,
+		record.Name,
+		record.Category,
+		strings.Join(record.Categories, listSeparator),
+		strconv.Itoa(record.RentalCost),
+		strconv.Itoa(record.Stok),
+		strings.Join(record.Authors, listSeparator),
+		record.Publisher,
+		strconv.Itoa(record.PublicationYear),
+		record.Language,
+		strconv.Itoa(record.PageCount),
+		record.Synopsis,
+		record.CoverURL,
+	}
+}
+
+func toBookImportData(report service.BookImportReport) dto.BookImportData {
+	data := dto.BookImportData{
+		DryRun:  report.DryRun,
+		Rows:    report.Rows,
+		Created: report.Created,
+		Updated: report.Updated,
+		Errors:  make([]dto.BookImportRowError, 0, len(report.Errors)),
+	}
+	for _, rowErr := range report.Errors {
+		data.Errors = append(data.Errors, dto.BookImportRowError{Line: rowErr.Line, Error: rowErr.Err.Error()})
+	}
+	return data
+}
+
+// ImportBooks godoc
+// @Summary Import books in bulk
+// @Description Admin only. Takes a CSV with a header line, or one JSON object per line, with the fields of dto.BookTransferRecord; in a CSV authors and categories are separated by ";". A row whose ISBN belongs to a stored book updates it and adds copies until it has at least stok available ones, any other row creates a book. The whole file is imported in one transaction: when any row is invalid nothing is imported and every invalid row is reported. With dry_run nothing is imported either.
+// @Tags Books
+// @Security BearerAuth
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv or jsonl, defaults from the Content-Type"
+// @Param dry_run query bool false "Only check the rows"
+// @Param file body string true "Books"
+// @Success 200 {object} dto.BookImportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.BookImportResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/books/import [post]
+func (h *BookTransferHandler) ImportBooks(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Status:  "Bad Request",
+				Code:    http.StatusBadRequest,
+				Message: "dry_run must be true or false",
+			})
+		}
+		dryRun = parsed
+	}
+
+	var parse func(io.Reader) ([]service.BookImportRow, error)
+	switch importFormat(c) {
+	case transferFormatCSV:
+		parse = parseBookCSV
+	case transferFormatJSONL:
+		parse = parseBookJSONL
+	default:
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "format must be csv or jsonl",
+		})
+	}
+
+	rows, err := parse(http.MaxBytesReader(c.Response(), c.Request().Body, maxImportSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+			Status:  "Request Entity Too Large",
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("import file must not be larger than %d bytes", maxImportSize),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	report, err := h.Service.ImportBooks(rows, dryRun)
+	switch {
+	case errors.Is(err, service.ErrEmptyImport):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrImportFailed):
+		return c.JSON(http.StatusUnprocessableEntity, dto.BookImportResponse{
+			Status:  "Unprocessable Entity",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+			Data:    toBookImportData(report),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Import Books is Failed",
+		})
+	}
+
+	message := "Success Import Books"
+	if dryRun {
+		message = "Import Books Checked, Nothing Imported"
+	}
+	return c.JSON(http.StatusOK, dto.BookImportResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    toBookImportData(report),
+	})
+}
+
+// ExportBooks godoc
+// @Summary Export the catalog
+// @Description Admin only. Streams every book as a CSV with a header line, or as one JSON object per line, in the format the import takes.
+// @Tags Books
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Success 200 {array} dto.BookTransferRecord
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/books/export [get]
+func (h *BookTransferHandler) ExportBooks(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = transferFormatCSV
+	}
+
+	var contentType, filename string
+	var header, flush func() error
+	var write func(record dto.BookTransferRecord) error
+	switch format {
+	case transferFormatCSV:
+		writer := csv.NewWriter(c.Response())
+		contentType, filename = "text/csv; charset=utf-8", "books.csv"
+		header = func() error { return writer.Write(bookTransferColumns) }
+		write = func(record dto.BookTransferRecord) error { return writer.Write(csvFields(record)) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case transferFormatJSONL:
+		encoder := json.NewEncoder(c.Response())
+		contentType, filename = "application/x-ndjson", "books.jsonl"
+		header = func() error { return nil }
+		write = func(record dto.BookTransferRecord) error { return encoder.Encode(record) }
+		flush = func() error { return nil }
+	default:
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "format must be csv or jsonl",
+		})
+	}
+
+	// The response is only started with the first book, so a failure before it can still
+	// be answered with an error.
+	res := c.Response()
+	start := func() error {
+		res.Header().Set(echo.HeaderContentType, contentType)
+		res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		res.WriteHeader(http.StatusOK)
+		return header()
+	}
+
+	written := 0
+	err := h.Service.ExportBooks(func(book model.Book) error {
+		if !res.Committed {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := write(recordFromBook(book)); err != nil {
+			return err
+		}
+		if written++; written%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			res.Flush()
+		}
+		return nil
+	})
+	if err != nil && !res.Committed {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Export Books is Failed",
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if !res.Committed {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryHandler struct {
+	Service service.CategoryService
+}
+
+func NewCategoryHandler(s service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{Service: s}
+}
+
+// categoryError answers a failed change to a category, with failure as the message of
+// unexpected errors.
+func categoryError(c echo.Context, err error, failure string) error {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+		})
+	case errors.Is(err, service.ErrInvalidCategory):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrCategoryTaken), errors.Is(err, service.ErrCategoryInUse):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		Status:  "Internal Server Error",
+		Code:    http.StatusInternalServerError,
+		Message: failure,
+	})
+}
+
+func toCategoryData(category model.Category) dto.CategoryDataResponse {
+	return dto.CategoryDataResponse{
+		ID:       category.ID,
+		Slug:     category.Slug,
+		Name:     category.Name,
+		ParentID: category.ParentID,
+	}
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description List every category by name with the number of books filed directly under it. Subcategories point at their parent.
+// @Tags Categories
+// @Produce json
+// @Success 200 {object} dto.CategoryListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategories(c echo.Context) error {
+	categories, err := h.Service.ListCategories()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Categories is Failed",
+		})
+	}
+
+	data := make([]dto.CategoryDataResponse, 0, len(categories))
+	for _, category := range categories {
+		data = append(data, dto.CategoryDataResponse{
+			ID:        category.ID,
+			Slug:      category.Slug,
+			Name:      category.Name,
+			ParentID:  category.ParentID,
+			BookCount: category.BookCount,
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.CategoryListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Categories",
+		Data:    data,
+	})
+}
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Admin only. The slug defaults to one made from the name; names and slugs are unique.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CategoryRequest true "Category"
+// @Success 201 {object} dto.CategoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/categories [post]
+func (h *CategoryHandler) CreateCategory(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	var req dto.CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+		})
+	}
+
+	category, err := h.Service.CreateCategory(model.Category{Name: req.Name, Slug: req.Slug, ParentID: req.ParentID})
+	if err != nil {
+		return categoryError(c, err, "Create Category is Failed")
+	}
+
+	return c.JSON(http.StatusCreated, dto.CategoryResponse{
+		Status:  "success",
+		Code:    http.StatusCreated,
+		Message: "Success Create Category",
+		Data:    toCategoryData(category),
+	})
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Admin only. Renaming a category renames it on its books and pricing plans too. A category cannot be moved below itself.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param request body dto.CategoryRequest true "Category"
+// @Success 200 {object} dto.CategoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var req dto.CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+		})
+	}
+
+	category, err := h.Service.UpdateCategory(uint(id), model.Category{Name: req.Name, Slug: req.Slug, ParentID: req.ParentID})
+	if err != nil {
+		return categoryError(c, err, "Update Category is Failed")
+	}
+
+	return c.JSON(http.StatusOK, dto.CategoryResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Update Category",
+		Data:    toCategoryData(category),
+	})
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Admin only. Subcategories move up to the deleted category's parent. A category with books can only be deleted by merging its books into another category with merge_into.
+// @Tags Categories
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param merge_into query int false "ID of the category the books move to"
+// @Success 204 {object} nil "No Content"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var mergeInto *uint
+	if value := c.QueryParam("merge_into"); value != "" {
+		target, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Status:  "Bad Request",
+				Code:    http.StatusBadRequest,
+				Message: "Invalid query parameter",
+				Details: "invalid merge_into",
+			})
+		}
+		targetID := uint(target)
+		mergeInto = &targetID
+	}
+
+	if err := h.Service.DeleteCategory(uint(id), mergeInto); err != nil {
+		return categoryError(c, err, "Delete Category is Failed")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"io"
 	"net/http"
 	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
 	"pojok-baca-api/service"
+	"time"
 )
 
 type DepositTransactionHandler struct {
@@ -30,7 +35,15 @@ func (h *DepositTransactionHandler) Create(c echo.Context) error {
 	claims := userToken.Claims.(jwt.MapClaims)
 	userID := uint(claims["user_id"].(float64))
 
-	res, err := h.Service.CreateTransaction(userID, req.Amount)
+	res, err := h.Service.CreateTransaction(userID, req.Amount, req.VoucherCode)
+	if isVoucherError(err) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "error",
+			Code:    400,
+			Message: "Voucher cannot be used",
+			Details: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Status:  "error",
@@ -43,24 +56,187 @@ func (h *DepositTransactionHandler) Create(c echo.Context) error {
 		Status:  "success",
 		Code:    200,
 		Message: "Success Create Transaction",
-		OrderID: res.Token, // or res.OrderID
+		OrderID: res.OrderID,
 		PayURL:  res.RedirectURL,
 	})
 }
 
-func (h *DepositTransactionHandler) Webhook(c echo.Context) error {
-	var payload struct {
-		OrderID           string `json:"order_id"`
-		TransactionStatus string `json:"transaction_status"`
+func toDepositData(tx model.DepositTransaction) dto.DepositTransactionDataResponse {
+	paidAt := ""
+	if tx.PaidAt != nil {
+		paidAt = tx.PaidAt.Format(time.RFC3339)
 	}
 
-	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, "Invalid payload")
+	return dto.DepositTransactionDataResponse{
+		OrderID:    tx.OrderID,
+		Amount:     tx.Deposit,
+		Bonus:      tx.Bonus,
+		Status:     tx.Status,
+		PaidAt:     paidAt,
+		PaymentRef: tx.PaymentRef,
+		CreatedAt:  tx.CreatedAt.Format(time.RFC3339),
 	}
+}
+
+// ListTransactions godoc
+// @Summary List my deposits
+// @Description Deposit orders of the logged-in user, newest first
+// @Tags Deposits
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Only deposits in this status" Enums(pending, capture, settlement, deny, cancel, expire, failure)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.DepositTransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/deposits [get]
+func (h *DepositTransactionHandler) ListTransactions(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
 
-	err := h.Service.HandleWebhook(payload.OrderID, payload.TransactionStatus)
+	page, limit, err := parsePagination(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, "Failed to process webhook")
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	deposits, err := h.Service.ListTransactions(userID, c.QueryParam("status"), page, limit)
+	if errors.Is(err, service.ErrInvalidDepositStatus) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Deposits is Failed",
+		})
+	}
+
+	data := make([]dto.DepositTransactionDataResponse, 0, len(deposits.Deposits))
+	for _, tx := range deposits.Deposits {
+		data = append(data, toDepositData(tx))
+	}
+
+	return c.JSON(http.StatusOK, dto.DepositTransactionListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Deposits",
+		Data:    data,
+		Meta:    dto.PaginationMeta{Page: deposits.Page, Limit: deposits.Limit, Total: deposits.Total},
+	})
+}
+
+// GetTransaction godoc
+// @Summary Get one of my deposits
+// @Description Amount, status, payment time and payment reference of a deposit order of the logged-in user
+// @Tags Deposits
+// @Security BearerAuth
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Success 200 {object} dto.DepositTransactionDetailResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/deposits/{order_id} [get]
+func (h *DepositTransactionHandler) GetTransaction(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	tx, err := h.Service.GetTransaction(userID, c.Param("order_id"))
+	if errors.Is(err, service.ErrDepositOrderNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Deposit transaction not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Deposit is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.DepositTransactionDetailResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Deposit",
+		Data:    toDepositData(tx),
+	})
+}
+
+// Webhook receives payment status notifications from Midtrans. It is mounted outside
+// /api and is not part of the public API docs. The signature_key is verified against
+// the server key and gross_amount must match the deposit before anything is credited.
+// Repeated notifications are answered with 200 so Midtrans stops retrying them.
+func (h *DepositTransactionHandler) Webhook(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid payload",
+		})
+	}
+
+	var payload dto.DepositNotificationRequest
+	if err := json.Unmarshal(body, &payload); err != nil || payload.OrderID == "" || payload.TransactionStatus == "" ||
+		payload.StatusCode == "" || payload.GrossAmount == "" || payload.SignatureKey == "" {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid payload",
+		})
+	}
+
+	err = h.Service.HandleWebhook(service.DepositNotification{
+		OrderID:           payload.OrderID,
+		StatusCode:        payload.StatusCode,
+		GrossAmount:       payload.GrossAmount,
+		SignatureKey:      payload.SignatureKey,
+		TransactionStatus: payload.TransactionStatus,
+		FraudStatus:       payload.FraudStatus,
+		Payload:           string(body),
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidSignature):
+		return c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Status:  "Forbidden",
+			Code:    http.StatusForbidden,
+			Message: "Invalid signature",
+		})
+	case errors.Is(err, service.ErrDepositOrderNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Deposit transaction not found",
+		})
+	case errors.Is(err, service.ErrGrossAmountMismatch):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Gross amount does not match the deposit",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to process webhook",
+		})
 	}
 
 	return c.JSON(http.StatusOK, "OK")
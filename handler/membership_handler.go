@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type MembershipHandler struct {
+	Service service.MembershipService
+}
+
+func NewMembershipHandler(s service.MembershipService) *MembershipHandler {
+	return &MembershipHandler{Service: s}
+}
+
+func toMembershipPlanData(plan model.MembershipPlan) dto.MembershipPlanDataResponse {
+	return dto.MembershipPlanDataResponse{
+		ID:           plan.ID,
+		Name:         plan.Name,
+		Price:        plan.Price,
+		DurationDays: plan.DurationDays,
+		RentalQuota:  plan.RentalQuota,
+		Unlimited:    plan.RentalQuota == 0,
+	}
+}
+
+func toMembershipData(membership model.Membership) dto.MembershipDataResponse {
+	data := dto.MembershipDataResponse{
+		MembershipID: membership.ID,
+		PlanID:       membership.PlanID,
+		PlanName:     membership.Plan.Name,
+		Price:        membership.Price,
+		StartsAt:     membership.StartsAt.Format(time.RFC3339),
+		ExpiresAt:    membership.ExpiresAt.Format(time.RFC3339),
+		AutoRenew:    membership.AutoRenew,
+		Unlimited:    membership.Unlimited(),
+		RentalQuota:  membership.RentalQuota,
+		RentalsUsed:  membership.RentalsUsed,
+	}
+	if !membership.Unlimited() {
+		remaining := membership.RemainingQuota()
+		data.RemainingQuota = &remaining
+	}
+	return data
+}
+
+// membershipResult writes the response of a call that returns the user's membership.
+func membershipResult(c echo.Context, membership model.Membership, err error, name string) error {
+	switch {
+	case errors.Is(err, service.ErrMembershipNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "No active membership",
+		})
+	case errors.Is(err, service.ErrMembershipPlanNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Membership plan not found",
+		})
+	case errors.Is(err, service.ErrMembershipActive):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "A membership is already active",
+		})
+	case errors.Is(err, service.ErrOutstandingBalance):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Outstanding balance must be settled first",
+		})
+	case errors.Is(err, service.ErrInsufficientDeposit):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient deposit",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: name + " is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.MembershipResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success " + name,
+		Data:    toMembershipData(membership),
+	})
+}
+
+// ListPlans godoc
+// @Summary List membership plans
+// @Description Membership plans that can be bought, cheapest first. A rental quota of zero is unlimited.
+// @Tags Memberships
+// @Produce json
+// @Success 200 {object} dto.MembershipPlanListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /membership-plans [get]
+func (h *MembershipHandler) ListPlans(c echo.Context) error {
+	plans, err := h.Service.ListPlans()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Membership Plans is Failed",
+		})
+	}
+
+	data := make([]dto.MembershipPlanDataResponse, 0, len(plans))
+	for _, plan := range plans {
+		data = append(data, toMembershipPlanData(plan))
+	}
+
+	return c.JSON(http.StatusOK, dto.MembershipPlanListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Membership Plans",
+		Data:    data,
+	})
+}
+
+// CreatePlan godoc
+// @Summary Create a membership plan
+// @Description Only admin users can create membership plans. The rental quota is per period, zero for unlimited.
+// @Tags Memberships
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateMembershipPlanRequest true "Membership plan"
+// @Success 201 {object} dto.MembershipPlanResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /membership-plans [post]
+func (h *MembershipHandler) CreatePlan(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	var req dto.CreateMembershipPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	plan, err := h.Service.CreatePlan(model.MembershipPlan{
+		Name:         req.Name,
+		Price:        req.Price,
+		DurationDays: req.DurationDays,
+		RentalQuota:  req.RentalQuota,
+	})
+	if errors.Is(err, service.ErrInvalidMembershipPlan) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid membership plan",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Create Membership Plan is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.MembershipPlanResponse{
+		Status:  "success",
+		Code:    http.StatusCreated,
+		Message: "Success Create Membership Plan",
+		Data:    toMembershipPlanData(plan),
+	})
+}
+
+// Subscribe godoc
+// @Summary Buy a membership
+// @Description Buys a period of a membership plan, paid from the deposit. Rentals on the standard terms are free while the quota lasts. With auto_renew the next period is paid from the deposit when this one ends.
+// @Tags Memberships
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.SubscribeMembershipRequest true "Plan to buy"
+// @Success 200 {object} dto.MembershipResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/membership [post]
+func (h *MembershipHandler) Subscribe(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	var req dto.SubscribeMembershipRequest
+	if err := c.Bind(&req); err != nil || req.PlanID == 0 {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "plan_id is required",
+		})
+	}
+
+	membership, err := h.Service.Subscribe(userID, req.PlanID, req.AutoRenew)
+	return membershipResult(c, membership, err, "Subscribe Membership")
+}
+
+// GetMembership godoc
+// @Summary Get my membership
+// @Description The running membership of the logged-in user with the remaining rental quota
+// @Tags Memberships
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.MembershipResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/membership [get]
+func (h *MembershipHandler) GetMembership(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	membership, err := h.Service.GetActiveMembership(userID)
+	return membershipResult(c, membership, err, "Get Membership")
+}
+
+// SetAutoRenew godoc
+// @Summary Turn membership auto-renewal on or off
+// @Description Whether the next period is bought from the deposit when the running one ends
+// @Tags Memberships
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MembershipAutoRenewRequest true "Auto-renewal"
+// @Success 200 {object} dto.MembershipResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/membership/auto-renew [put]
+func (h *MembershipHandler) SetAutoRenew(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	var req dto.MembershipAutoRenewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	membership, err := h.Service.SetAutoRenew(userID, req.AutoRenew)
+	return membershipResult(c, membership, err, "Update Membership")
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+	"pojok-baca-api/dto"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parsePagination reads the page and limit query parameters. Missing ones are zero,
+// which the services replace with their defaults.
+func parsePagination(c echo.Context) (int, int, error) {
+	var page, limit int
+	intParams := map[string]*int{"page": &page, "limit": &limit}
+	for name, target := range intParams {
+		if value := c.QueryParam(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid %s", name)
+			}
+			*target = n
+		}
+	}
+	return page, limit, nil
+}
+
+// pageLinks builds the links to the pages before and after page, keeping the rest of
+// the request's query.
+func pageLinks(c echo.Context, page int, limit int, total int64) dto.PageLinks {
+	link := func(target int) string {
+		u := *c.Request().URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(target))
+		query.Set("limit", strconv.Itoa(limit))
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+
+	var links dto.PageLinks
+	if int64(page*limit) < total {
+		links.Next = link(page + 1)
+	}
+	if page > 1 {
+		links.Prev = link(page - 1)
+	}
+	return links
+}
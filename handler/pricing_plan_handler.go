@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type PricingPlanHandler struct {
+	Service service.PricingPlanService
+}
+
+func NewPricingPlanHandler(s service.PricingPlanService) *PricingPlanHandler {
+	return &PricingPlanHandler{Service: s}
+}
+
+func toPricingPlanData(plan model.PricingPlan) dto.PricingPlanDataResponse {
+	return dto.PricingPlanDataResponse{
+		ID:           plan.ID,
+		BookID:       plan.BookID,
+		Category:     plan.Category,
+		Name:         plan.Name,
+		DurationDays: plan.DurationDays,
+		Price:        plan.Price,
+	}
+}
+
+// GetBookPlans godoc
+// @Summary Get pricing plans of a book
+// @Description List the rental plans that can be chosen for a book, its own plans and those of its category. Without a plan the book rents for 7 days at its rental cost.
+// @Tags Pricing Plans
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} dto.PricingPlanListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /products/{id}/plans [get]
+func (h *PricingPlanHandler) GetBookPlans(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "BadRequest",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	plans, err := h.Service.GetPlansForBook(uint(id))
+	if errors.Is(err, service.ErrBookNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get pricing plans",
+		})
+	}
+
+	var data []dto.PricingPlanDataResponse
+	for _, plan := range plans {
+		data = append(data, toPricingPlanData(plan))
+	}
+
+	return c.JSON(http.StatusOK, dto.PricingPlanListResponse{
+		Status:  "Success",
+		Code:    http.StatusOK,
+		Message: "Success Get Pricing Plans",
+		Data:    data,
+	})
+}
+
+// CreatePlan godoc
+// @Summary Create a pricing plan
+// @Description Only admin users can create pricing plans. A plan belongs to one book (book_id) or to a whole existing category, named by its name or slug.
+// @Tags Pricing Plans
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePricingPlanRequest true "Pricing plan request"
+// @Success 201 {object} dto.PricingPlanResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /pricing-plans [post]
+func (h *PricingPlanHandler) CreatePlan(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	var req dto.CreatePricingPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "BadRequest",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	plan, err := h.Service.CreatePlan(model.PricingPlan{
+		BookID:       req.BookID,
+		Category:     req.Category,
+		Name:         req.Name,
+		DurationDays: req.DurationDays,
+		Price:        req.Price,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidPricingPlan):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "BadRequest",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create pricing plan",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.PricingPlanResponse{
+		Status:  "Success",
+		Code:    http.StatusCreated,
+		Message: "Success Create Pricing Plan",
+		Data:    toPricingPlanData(plan),
+	})
+}
+
+// DeletePlan godoc
+// @Summary Delete a pricing plan
+// @Description Only admin can delete a pricing plan. Existing rentals keep their terms.
+// @Tags Pricing Plans
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Pricing plan ID"
+// @Success 204 {object} nil "No Content"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /pricing-plans/{id} [delete]
+func (h *PricingPlanHandler) DeletePlan(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "BadRequest",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	if err := h.Service.DeletePlan(uint(id)); err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete pricing plan",
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"pojok-baca-api/dto"
 	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
 	"pojok-baca-api/service"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -12,22 +16,35 @@ import (
 )
 
 type RentalHandler struct {
-	Service     service.RentalService
-	bookService service.BookService
-	userService service.UserService
+	Service service.RentalService
 }
 
-func NewRentalHandler(rentalService service.RentalService, bookService service.BookService, userService service.UserService) *RentalHandler {
+func NewRentalHandler(rentalService service.RentalService) *RentalHandler {
 	return &RentalHandler{
-		Service:     rentalService,
-		bookService: bookService,
-		userService: userService,
+		Service: rentalService,
+	}
+}
+
+func toRentalData(rental model.Rental) dto.RentalDataResponse {
+	return dto.RentalDataResponse{
+		RentalID:      rental.ID,
+		BookID:        rental.BookID,
+		BookCopyID:    rental.BookCopyID,
+		PricingPlanID: rental.PricingPlanID,
+		DurationDays:  rental.DurationDays,
+		Price:         rental.Price,
+		Discount:      rental.Discount,
+		RentDate:      rental.RentDate.Format("2006-01-02"),
+		ReturnDate:    rental.ReturnDate.Format("2006-01-02"),
+		LateFee:       rental.LateFee,
+		DamageFee:     rental.DamageFee,
+		Status:        rental.Status,
 	}
 }
 
 // CreateRental godoc
 // @Summary Create a rental
-// @Description Create a new book rental by user. Requires login.
+// @Description Create a new book rental by user. Requires login. The price of the chosen pricing plan, or the book rental cost for the standard 7 days, is debited from the deposit. A copy held for the user's ready reservation is used when the book is out of stock.
 // @Tags Rentals
 // @Security BearerAuth
 // @Accept json
@@ -36,6 +53,7 @@ func NewRentalHandler(rentalService service.RentalService, bookService service.B
 // @Success 201 {object} dto.RentalResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /rentals [post]
 func (h *RentalHandler) CreateRental(c echo.Context) error {
@@ -44,7 +62,6 @@ func (h *RentalHandler) CreateRental(c echo.Context) error {
 	userToken := c.Get("user").(*jwt.Token)
 	claims := userToken.Claims.(jwt.MapClaims)
 	userID := uint(claims["user_id"].(float64))
-	email := claims["email"].(string)
 
 	//Request data rental
 	var req dto.RentalRequest
@@ -65,94 +82,185 @@ func (h *RentalHandler) CreateRental(c echo.Context) error {
 		})
 	}
 
-	//Check book is available or not
-	book, err := h.bookService.GetBookByID(req.BookID)
-	if book.Stok == 0 {
+	//Checkout book, deposit and stock are updated together
+	createdRental, err := h.Service.CreateRental(userID, req.BookID, req.PlanID)
+	switch {
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	case errors.Is(err, service.ErrBookNotAvailable):
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Status:  "Internal Server Error",
+			Status:  "Bad Request",
 			Code:    http.StatusBadRequest,
 			Message: "Book not available",
 		})
-	}
-
-	//Check deposit
-	user, err := h.userService.GetUserByEmail(email)
-	if user.Deposit == nil {
+	case errors.Is(err, service.ErrPricingPlanNotFound):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Pricing plan not available for this book",
+		})
+	case errors.Is(err, service.ErrDepositNotFound):
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Status:  "Bad Request",
 			Code:    http.StatusBadRequest,
 			Message: "Deposit not found for this user",
 		})
-	}
-
-	userDepo := user.Deposit
-	bookCost := book.RentalCost
-	if *userDepo < bookCost {
+	case errors.Is(err, service.ErrOutstandingBalance):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Outstanding balance must be settled first",
+		})
+	case errors.Is(err, service.ErrInsufficientDeposit):
 		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Status:  "Bad Request",
 			Code:    http.StatusBadRequest,
 			Message: "Insufficient deposit",
 		})
-	}
-
-	//Update Deposit
-	depoResult := *userDepo - bookCost
-	_, err = h.userService.UpdateDepositUser(depoResult, user.ID)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, dto.ErrorResponse{
 			Status:  "Internal Server Error",
 			Code:    http.StatusInternalServerError,
-			Message: "Update deposit is failed",
+			Message: "Create Rental is Failed",
 		})
 	}
 
-	//Set rentDate & return Date
-	rentDate := time.Now()
-	returnDate := rentDate.AddDate(0, 0, 7)
+	//Create rental success
+	return c.JSON(http.StatusCreated, dto.RentalResponse{
+		Status:  "Created",
+		Code:    http.StatusCreated,
+		Message: "Success Create Rental",
+		Data:    toRentalData(createdRental),
+	})
+}
+
+// Checkout godoc
+// @Summary Checkout a cart of books
+// @Description Rent several books at once. Availability and total cost are checked for the whole cart and all rentals are created together with one deposit debit. An optional voucher code takes its discount off the books it applies to.
+// @Tags Rentals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CheckoutRequest true "Checkout request payload"
+// @Success 201 {object} dto.CheckoutResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /rentals/checkout [post]
+func (h *RentalHandler) Checkout(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
 
-	//Create New Data
-	newRental := model.Rental{
-		BookID:     req.BookID,
-		UserID:     userID,
-		RentDate:   rentDate,
-		ReturnDate: &returnDate,
-		Status:     "Borrowed",
+	var req dto.CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
 	}
 
-	//Create Rental
-	createdRental, err := h.Service.CreateRental(newRental)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, dto.ErrorResponse{
+	items := make([]service.CartItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, service.CartItem{BookID: item.BookID, PlanID: item.PlanID, Quantity: item.Quantity})
+	}
+
+	checkout, err := h.Service.Checkout(userID, items, req.VoucherCode)
+	switch {
+	case errors.Is(err, service.ErrEmptyCart), errors.Is(err, service.ErrInvalidCartItem):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+			Details: err.Error(),
+		})
+	case errors.Is(err, service.ErrBookNotAvailable):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Book not available",
+			Details: err.Error(),
+		})
+	case errors.Is(err, service.ErrPricingPlanNotFound):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Pricing plan not available for this book",
+			Details: err.Error(),
+		})
+	case errors.Is(err, service.ErrDepositNotFound):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Deposit not found for this user",
+		})
+	case errors.Is(err, service.ErrOutstandingBalance):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Outstanding balance must be settled first",
+		})
+	case errors.Is(err, service.ErrInsufficientDeposit):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient deposit",
+		})
+	case isVoucherError(err):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Voucher cannot be used",
+			Details: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Status:  "Internal Server Error",
 			Code:    http.StatusInternalServerError,
-			Message: "Create Rental is Failed",
+			Message: "Checkout is Failed",
 		})
 	}
 
-	//Create rental success
-	return c.JSON(http.StatusCreated, dto.RentalResponse{
+	rentals := make([]dto.RentalDataResponse, 0, len(checkout.Rentals))
+	for _, rental := range checkout.Rentals {
+		rentals = append(rentals, toRentalData(rental))
+	}
+
+	return c.JSON(http.StatusCreated, dto.CheckoutResponse{
 		Status:  "Created",
 		Code:    http.StatusCreated,
-		Message: "Success Create Rental",
-		Data: dto.RentalDataResponse{
-			BookID:     createdRental.BookID,
-			RentDate:   createdRental.RentDate.Format("2006-01-02"),
-			ReturnDate: createdRental.ReturnDate.Format("2006-01-02"),
-			Status:     createdRental.Status,
+		Message: "Success Checkout",
+		Data: dto.CheckoutDataResponse{
+			CheckoutID: checkout.ID,
+			TotalCost:  checkout.TotalCost,
+			Discount:   checkout.Discount,
+			Rentals:    rentals,
 		},
 	})
 }
 
 // GetRentalByUserID godoc
 // @Summary Get rental history by logged-in user
-// @Description Returns a list of rental data for the authenticated user
+// @Description Returns a list of rental data, including late fees, damage charges and the history of every rental, for the authenticated user
 // @Tags Rentals
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} dto.RentalUserResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /rentals [get]
+// @Router /rentals/report [get]
 func (h *RentalHandler) GetRentalByUserID(c echo.Context) error {
 	userToken := c.Get("user").(*jwt.Token)
 	claims := userToken.Claims.(jwt.MapClaims)
@@ -174,6 +282,23 @@ func (h *RentalHandler) GetRentalByUserID(c echo.Context) error {
 		if rental.ReturnDate != nil {
 			returnDate = rental.ReturnDate.Format("2006-01-02")
 		}
+		returnedAt := ""
+		if rental.ReturnedAt != nil {
+			returnedAt = rental.ReturnedAt.Format("2006-01-02")
+		}
+
+		events := make([]dto.RentalEventResponse, 0, len(rental.Events))
+		for _, event := range rental.Events {
+			events = append(events, dto.RentalEventResponse{
+				Type:       event.Type,
+				FromStatus: event.FromStatus,
+				ToStatus:   event.ToStatus,
+				Reason:     event.Reason,
+				Condition:  event.Condition,
+				Amount:     event.Amount,
+				CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+			})
+		}
 
 		rentalResponses = append(rentalResponses, dto.RentalUserDataResponse{
 			RentalID:   rental.ID,
@@ -181,7 +306,11 @@ func (h *RentalHandler) GetRentalByUserID(c echo.Context) error {
 			BookTitle:  rental.Book.Name,
 			RentDate:   rental.RentDate.Format("2006-01-02"),
 			ReturnDate: returnDate,
+			ReturnedAt: returnedAt,
+			LateFee:    rental.LateFee,
+			DamageFee:  rental.DamageFee,
 			Status:     rental.Status,
+			Events:     events,
 		})
 	}
 
@@ -192,3 +321,517 @@ func (h *RentalHandler) GetRentalByUserID(c echo.Context) error {
 		Data:    rentalResponses,
 	})
 }
+
+// ReturnRental godoc
+// @Summary Return a rented book
+// @Description Return a borrowed or overdue book owned by the logged-in user. The book stock is restored and any late fee is debited from the deposit.
+// @Tags Rentals
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Success 200 {object} dto.ReturnRentalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /rentals/{id}/return [post]
+func (h *RentalHandler) ReturnRental(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	rental, err := h.Service.ReturnRental(uint(id), userID)
+	if errors.Is(err, service.ErrRentalNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Rental not found",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidRentalTransition) {
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Rental cannot be returned",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Return Rental is Failed",
+		})
+	}
+
+	returnDate := ""
+	if rental.ReturnDate != nil {
+		returnDate = rental.ReturnDate.Format("2006-01-02")
+	}
+
+	return c.JSON(http.StatusOK, dto.ReturnRentalResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Return Rental",
+		Data: dto.ReturnRentalDataResponse{
+			RentalID:   rental.ID,
+			BookID:     rental.BookID,
+			RentDate:   rental.RentDate.Format("2006-01-02"),
+			ReturnDate: returnDate,
+			ReturnedAt: rental.ReturnedAt.Format("2006-01-02"),
+			LateFee:    rental.LateFee,
+			Status:     rental.Status,
+		},
+	})
+}
+
+// ExtendRental godoc
+// @Summary Extend a rental
+// @Description Push the due date of a borrowed rental by the configured period. The extension cost is debited from the deposit. Refused when another user is waiting for the book.
+// @Tags Rentals
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Success 200 {object} dto.ExtendRentalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /rentals/{id}/extend [post]
+func (h *RentalHandler) ExtendRental(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	rental, cost, err := h.Service.ExtendRental(uint(id), userID)
+	switch {
+	case errors.Is(err, service.ErrRentalNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Rental not found",
+		})
+	case errors.Is(err, service.ErrRentalNotExtendable):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Only borrowed rentals can be extended",
+		})
+	case errors.Is(err, service.ErrRenewalLimitReached):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Rental renewal limit reached",
+		})
+	case errors.Is(err, service.ErrBookReserved):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Book has a waiting reservation",
+		})
+	case errors.Is(err, service.ErrOutstandingBalance):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Outstanding balance must be settled first",
+		})
+	case errors.Is(err, service.ErrInsufficientDeposit):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient deposit",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Extend Rental is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ExtendRentalResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Extend Rental",
+		Data: dto.ExtendRentalDataResponse{
+			RentalID:      rental.ID,
+			BookID:        rental.BookID,
+			ReturnDate:    rental.ReturnDate.Format("2006-01-02"),
+			RenewalCount:  rental.RenewalCount,
+			ExtensionCost: cost,
+			Status:        rental.Status,
+		},
+	})
+}
+
+// parseRentalFilter reads the admin rental list query. Dates are YYYY-MM-DD and the
+// to date is inclusive.
+func parseRentalFilter(c echo.Context) (repository.RentalFilter, error) {
+	filter := repository.RentalFilter{
+		Status: c.QueryParam("status"),
+		Sort:   c.QueryParam("sort"),
+	}
+
+	uintParams := map[string]*uint{"user_id": &filter.UserID, "book_id": &filter.BookID}
+	for name, target := range uintParams {
+		if value := c.QueryParam(name); value != "" {
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = uint(n)
+		}
+	}
+
+	intParams := map[string]*int{"page": &filter.Page, "limit": &filter.Limit}
+	for name, target := range intParams {
+		if value := c.QueryParam(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = n
+		}
+	}
+
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, errors.New("invalid from, use YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, errors.New("invalid to, use YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	if value := c.QueryParam("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("invalid overdue")
+		}
+		filter.OverdueOnly = overdue
+	}
+
+	return filter, nil
+}
+
+// ListRentals godoc
+// @Summary List all rentals
+// @Description Admin only. Lists the rentals of every user with filters, pagination and sorting.
+// @Tags Admin Rentals
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Rental status" Enums(Borrowed, Overdue, Returned, Lost, Cancelled)
+// @Param user_id query int false "User ID"
+// @Param book_id query int false "Book ID"
+// @Param from query string false "Rented on or after (YYYY-MM-DD)"
+// @Param to query string false "Rented on or before (YYYY-MM-DD)"
+// @Param overdue query bool false "Only rentals past their due date"
+// @Param sort query string false "id, rent_date, return_date, status or price, prefix with - for descending"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.AdminRentalListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rentals [get]
+func (h *RentalHandler) ListRentals(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	filter, err := parseRentalFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	page, err := h.Service.ListRentals(filter)
+	if errors.Is(err, service.ErrInvalidRentalFilter) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Rentals is Failed",
+		})
+	}
+
+	data := make([]dto.AdminRentalDataResponse, 0, len(page.Rentals))
+	for _, rental := range page.Rentals {
+		returnDate := ""
+		if rental.ReturnDate != nil {
+			returnDate = rental.ReturnDate.Format("2006-01-02")
+		}
+		returnedAt := ""
+		if rental.ReturnedAt != nil {
+			returnedAt = rental.ReturnedAt.Format("2006-01-02")
+		}
+
+		data = append(data, dto.AdminRentalDataResponse{
+			RentalID:   rental.ID,
+			UserID:     rental.UserID,
+			UserEmail:  rental.User.Email,
+			BookID:     rental.BookID,
+			BookTitle:  rental.Book.Name,
+			Price:      rental.Price,
+			RentDate:   rental.RentDate.Format("2006-01-02"),
+			ReturnDate: returnDate,
+			ReturnedAt: returnedAt,
+			LateFee:    rental.LateFee,
+			Status:     rental.Status,
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.AdminRentalListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Rentals",
+		Data:    data,
+		Meta:    dto.PaginationMeta{Page: page.Page, Limit: page.Limit, Total: page.Total},
+	})
+}
+
+// adminRentalAction runs one of the admin rental actions for the rental in the path,
+// with the reason from the request body and the caller as the acting admin.
+func (h *RentalHandler) adminRentalAction(c echo.Context, action func(id uint, adminID uint, reason string) (model.Rental, error), name string) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	adminID := uint(claims["user_id"].(float64))
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var req dto.AdminRentalActionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	rental, err := action(uint(id), adminID, req.Reason)
+	return adminRentalResult(c, rental, err, name)
+}
+
+// adminRentalResult writes the response of an admin rental action.
+func adminRentalResult(c echo.Context, rental model.Rental, err error, name string) error {
+	switch {
+	case errors.Is(err, service.ErrReasonRequired):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "reason is required",
+		})
+	case errors.Is(err, service.ErrRentalNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Rental not found",
+		})
+	case errors.Is(err, service.ErrInvalidRentalTransition):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Rental is already closed",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: name + " is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.AdminRentalActionResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success " + name,
+		Data:    toRentalData(rental),
+	})
+}
+
+// ForceCloseRental godoc
+// @Summary Force-close a rental
+// @Description Admin only. Returns a borrowed or overdue rental on behalf of its user. The late fee is charged and the copy goes back into circulation. The reason is recorded.
+// @Tags Admin Rentals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Param request body dto.AdminRentalActionRequest true "Reason for the action"
+// @Success 200 {object} dto.AdminRentalActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rentals/{id}/close [post]
+func (h *RentalHandler) ForceCloseRental(c echo.Context) error {
+	return h.adminRentalAction(c, h.Service.ForceCloseRental, "Close Rental")
+}
+
+// MarkRentalLost godoc
+// @Summary Mark a rental as lost
+// @Description Admin only. Closes a borrowed or overdue rental whose copy will not come back. The replacement fee is charged, even when the deposit goes below zero, and stock is not restored. The reason is recorded.
+// @Tags Admin Rentals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Param request body dto.AdminRentalActionRequest true "Reason for the action"
+// @Success 200 {object} dto.AdminRentalActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rentals/{id}/lost [post]
+func (h *RentalHandler) MarkRentalLost(c echo.Context) error {
+	return h.adminRentalAction(c, h.Service.MarkRentalLost, "Mark Rental Lost")
+}
+
+// CancelRental godoc
+// @Summary Cancel a rental
+// @Description Admin only. Voids a rental made in error. The rental price is refunded to the deposit and the copy goes back into circulation. The reason is recorded.
+// @Tags Admin Rentals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Param request body dto.AdminRentalActionRequest true "Reason for the action"
+// @Success 200 {object} dto.AdminRentalActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rentals/{id}/cancel [post]
+func (h *RentalHandler) CancelRental(c echo.Context) error {
+	return h.adminRentalAction(c, h.Service.CancelRental, "Cancel Rental")
+}
+
+// ReportDamage godoc
+// @Summary Report a returned copy as damaged
+// @Description Admin only. Closes a borrowed or overdue rental whose copy came back damaged. The late fee and the repair fee are charged, even when the deposit goes below zero. The copy gets the recorded condition and is taken out of circulation.
+// @Tags Admin Rentals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rental ID"
+// @Param request body dto.ReportDamageRequest true "Condition of the copy and notes"
+// @Success 200 {object} dto.AdminRentalActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rentals/{id}/damage [post]
+func (h *RentalHandler) ReportDamage(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	adminID := uint(claims["user_id"].(float64))
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var req dto.ReportDamageRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	rental, err := h.Service.ReportDamage(uint(id), adminID, req.Condition, req.Notes)
+	if errors.Is(err, service.ErrInvalidDamageCondition) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "condition must be Fair, Poor or Damaged",
+		})
+	}
+	if errors.Is(err, service.ErrReasonRequired) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "notes are required",
+		})
+	}
+	return adminRentalResult(c, rental, err, "Report Damage")
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ReservationHandler struct {
+	Service service.ReservationService
+}
+
+func NewReservationHandler(s service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{Service: s}
+}
+
+func toReservationData(reservation model.Reservation, position int64) dto.ReservationDataResponse {
+	holdExpiresAt := ""
+	if reservation.HoldExpiresAt != nil {
+		holdExpiresAt = reservation.HoldExpiresAt.Format("2006-01-02 15:04:05")
+	}
+
+	return dto.ReservationDataResponse{
+		ReservationID: reservation.ID,
+		BookID:        reservation.BookID,
+		BookTitle:     reservation.Book.Name,
+		Status:        reservation.Status,
+		QueuePosition: position,
+		HoldExpiresAt: holdExpiresAt,
+		CreatedAt:     reservation.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// CreateReservation godoc
+// @Summary Reserve an out-of-stock book
+// @Description Join the FIFO queue of a book with no stock. When a copy is returned the first user in line gets a time-limited hold.
+// @Tags Reservations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ReservationRequest true "Reservation request payload"
+// @Success 201 {object} dto.ReservationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reservations [post]
+func (h *ReservationHandler) CreateReservation(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	var req dto.ReservationRequest
+	if err := c.Bind(&req); err != nil || req.BookID == 0 {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "book_id is required",
+		})
+	}
+
+	reservation, err := h.Service.CreateReservation(userID, req.BookID)
+	switch {
+	case errors.Is(err, service.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Book not found",
+		})
+	case errors.Is(err, service.ErrBookInStock):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Book is in stock, rent it directly",
+		})
+	case errors.Is(err, service.ErrReservationExists):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "You already reserved this book",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Create Reservation is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.ReservationResponse{
+		Status:  "Created",
+		Code:    http.StatusCreated,
+		Message: "Success Create Reservation",
+		Data:    toReservationData(reservation.Reservation, reservation.QueuePosition),
+	})
+}
+
+// GetReservations godoc
+// @Summary Get reservations of logged-in user
+// @Description Returns the reservations of the authenticated user with their queue position
+// @Tags Reservations
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.ReservationListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reservations [get]
+func (h *ReservationHandler) GetReservations(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	reservations, err := h.Service.GetReservationsByUserID(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Reservations is Failed",
+		})
+	}
+
+	var data []dto.ReservationDataResponse
+	for _, reservation := range reservations {
+		data = append(data, toReservationData(reservation.Reservation, reservation.QueuePosition))
+	}
+
+	return c.JSON(http.StatusOK, dto.ReservationListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Reservations",
+		Data:    data,
+	})
+}
+
+// CancelReservation godoc
+// @Summary Cancel a reservation
+// @Description Cancel a waiting or ready reservation of the logged-in user. A held copy is passed to the next user in line.
+// @Tags Reservations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} dto.ReservationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /reservations/{id} [delete]
+func (h *ReservationHandler) CancelReservation(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	reservation, err := h.Service.CancelReservation(uint(id), userID)
+	switch {
+	case errors.Is(err, service.ErrReservationNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Reservation not found",
+		})
+	case errors.Is(err, service.ErrReservationNotCancelable):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Reservation can no longer be cancelled",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Cancel Reservation is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ReservationResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Cancel Reservation",
+		Data:    toReservationData(reservation, 0),
+	})
+}
@@ -80,4 +80,122 @@ func TestCreateBook_Unauthorized(t *testing.T) {
 	json.Unmarshal(rec.Body.Bytes(), &resp)
 	assert.Equal(t, "Unauthorized", resp.Status)
 	assert.Equal(t, "You are not allowed to access this resource", resp.Message)
-}
\ No newline at end of file
+}
+func TestCreateBook_WithMetadata(t *testing.T) {
+	e := echo.New()
+	body := `{"name":"Atomic Habits","stok":2,"category":"Self Development","rental_cost":20000,
+		"isbn":"978-0-7352-1129-2","authors":["James Clear"],"publisher":"Avery","publication_year":2018,
+		"language":"en","page_count":320,"cover_url":"https://covers.example.com/atomic-habits.jpg"}`
+	req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "admin",
+	})
+	c.Set("user", token)
+
+	mockService := new(service.BookServiceMock)
+
+	isbn := "9780735211292"
+	created := model.Book{
+		Name: "Atomic Habits", Stok: 2, Category: "Self Development", RentalCost: 20000,
+		ISBN: &isbn, Publisher: "Avery", PublicationYear: 2018, Language: "en", PageCount: 320,
+		CoverURL: "https://covers.example.com/atomic-habits.jpg",
+		Authors:  []model.Author{{Name: "James Clear"}},
+	}
+	mockService.On("Create", mock.MatchedBy(func(book model.Book) bool {
+		return *book.ISBN == "978-0-7352-1129-2" && len(book.Authors) == 1 && book.Authors[0].Name == "James Clear" &&
+			book.PublicationYear == 2018 && book.PageCount == 320
+	})).Return(created, nil)
+
+	handler := handler.ProductHandler{Service: mockService}
+	err := handler.CreateBook(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.GetBookDataResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "9780735211292", resp.Data.ISBN)
+	assert.Equal(t, "James Clear", resp.Data.Authors[0].Name)
+	assert.Equal(t, "Avery", resp.Data.Publisher)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateBook_MetadataErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidBook, http.StatusBadRequest},
+		{service.ErrInvalidISBN, http.StatusBadRequest},
+		{service.ErrInvalidBookMetadata, http.StatusBadRequest},
+		{service.ErrISBNTaken, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		e := echo.New()
+		body := `{"name":"Golang","stok":10,"category":"Programming","rental_cost":5000,"isbn":"123"}`
+		req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"role": "admin",
+		})
+		c.Set("user", token)
+
+		mockService := new(service.BookServiceMock)
+		mockService.On("Create", mock.AnythingOfType("model.Book")).Return(model.Book{}, tc.err)
+
+		handler := handler.ProductHandler{Service: mockService}
+		err := handler.CreateBook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, rec.Code, tc.err.Error())
+	}
+}
+
+func TestCreateBook_WithCategoryIDs(t *testing.T) {
+	e := echo.New()
+	body := `{"name":"Laskar Pelangi","stok":3,"rental_cost":10000,"category_ids":[2,1]}`
+	req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "admin",
+	})
+	c.Set("user", token)
+
+	mockService := new(service.BookServiceMock)
+
+	novel := model.Category{Slug: "novel", Name: "Novel"}
+	novel.ID = 2
+	sastra := model.Category{Slug: "sastra", Name: "Sastra"}
+	sastra.ID = 1
+	created := model.Book{Name: "Laskar Pelangi", Stok: 3, RentalCost: 10000, Category: "Novel", Categories: []model.Category{novel, sastra}}
+	mockService.On("Create", mock.MatchedBy(func(book model.Book) bool {
+		return len(book.Categories) == 2 && book.Categories[0].ID == 2 && book.Categories[1].ID == 1
+	})).Return(created, nil)
+
+	handler := handler.ProductHandler{Service: mockService}
+	err := handler.CreateBook(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.GetBookDataResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Novel", resp.Data.Category)
+	assert.Equal(t, []dto.CategoryRef{{ID: 2, Slug: "novel", Name: "Novel"}, {ID: 1, Slug: "sastra", Name: "Sastra"}}, resp.Data.Categories)
+
+	mockService.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 	"pojok-baca-api/dto"
 	"pojok-baca-api/handler"
 	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
 	"pojok-baca-api/service"
 	"testing"
 
@@ -29,7 +30,7 @@ func TestGetBooks_Success(t *testing.T) {
 		{Name: "Book B", Stok: 5, RentalCost: 3000, Category: "History"},
 	}
 
-	mockService.On("GetBooks").Return(mockBooks, nil)
+	mockService.On("GetBooks", repository.BookFilter{}).Return(service.BookPage{Books: mockBooks, Total: 2, Page: 1, Limit: 20}, nil)
 
 	handler := handler.ProductHandler{Service: mockService}
 
@@ -44,6 +45,9 @@ func TestGetBooks_Success(t *testing.T) {
 	assert.Equal(t, "Success", resp.Status)
 	assert.Len(t, resp.Data, 2)
 	assert.Equal(t, "Book A", resp.Data[0].Name)
+	assert.Equal(t, int64(2), resp.Meta.Total)
+	assert.Empty(t, resp.Links.Next)
+	assert.Empty(t, resp.Links.Prev)
 
 	mockService.AssertExpectations(t)
 }
@@ -56,20 +60,72 @@ func TestGetBooks_Failure(t *testing.T) {
 
 	mockService := new(service.BookServiceMock)
 
-	mockService.On("GetBooks").Return([]model.Book{}, errors.New("failed to fetch"))
+	mockService.On("GetBooks", repository.BookFilter{}).Return(service.BookPage{}, errors.New("failed to fetch"))
 
 	handler := handler.ProductHandler{Service: mockService}
 
 	err := handler.GetBooks(c)
 
 	assert.NoError(t, err)
-	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
 	var resp dto.ErrorResponse
 	err = json.Unmarshal(rec.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Duplicate", resp.Status)
-	assert.Equal(t, "Email already exists", resp.Message)
+	assert.Equal(t, "Internal Server Error", resp.Status)
+	assert.Equal(t, "Get Books is Failed", resp.Message)
 
 	mockService.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestGetBooks_FilterAndLinks(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/products?q=habit&category=Self+Development&available=true&min_cost=1000&max_cost=20000&sort=-cost&page=2&limit=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	mockService := new(service.BookServiceMock)
+
+	filter := repository.BookFilter{
+		Keyword: "habit", Category: "Self Development", AvailableOnly: true,
+		MinCost: 1000, MaxCost: 20000, Sort: "-cost", Page: 2, Limit: 1,
+	}
+	mockBooks := []model.Book{{Name: "Atomic Habits", Stok: 5, RentalCost: 20000, Category: "Self Development"}}
+	mockService.On("GetBooks", filter).Return(service.BookPage{Books: mockBooks, Total: 3, Page: 2, Limit: 1}, nil)
+
+	handler := handler.ProductHandler{Service: mockService}
+
+	err := handler.GetBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.BookResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, dto.PaginationMeta{Page: 2, Limit: 1, Total: 3}, resp.Meta)
+	assert.Equal(t, "/api/products?available=true&category=Self+Development&limit=1&max_cost=20000&min_cost=1000&page=3&q=habit&sort=-cost", resp.Links.Next)
+	assert.Equal(t, "/api/products?available=true&category=Self+Development&limit=1&max_cost=20000&min_cost=1000&page=1&q=habit&sort=-cost", resp.Links.Prev)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetBooks_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"?page=two", "?available=maybe", "?sort=password"} {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockService := new(service.BookServiceMock)
+		mockService.On("GetBooks", repository.BookFilter{Sort: "password"}).Return(service.BookPage{}, service.ErrInvalidBookFilter)
+
+		handler := handler.ProductHandler{Service: mockService}
+
+		err := handler.GetBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,98 @@
+package book_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSearchRepository serves fixed full-text and similarity results and records the
+// searches it was asked for.
+type fakeSearchRepository struct {
+	fullText []repository.BookSearchHit
+	similar  []repository.BookSearchHit
+	err      error
+	searches []string
+}
+
+func (f *fakeSearchRepository) FullText(query string, page int, limit int) ([]repository.BookSearchHit, int64, error) {
+	f.searches = append(f.searches, "fulltext:"+query)
+	return f.fullText, int64(len(f.fullText)), f.err
+}
+
+func (f *fakeSearchRepository) Similar(query string, page int, limit int) ([]repository.BookSearchHit, int64, error) {
+	f.searches = append(f.searches, "similar:"+query)
+	return f.similar, int64(len(f.similar)), f.err
+}
+
+func searchBooks(repo repository.BookSearchRepository, target string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := handler.NewBookSearchHandler(service.NewBookSearchService(repo))
+	handler.SearchBooks(c)
+	return rec
+}
+
+func TestSearchBooks_Ranked(t *testing.T) {
+	repo := &fakeSearchRepository{fullText: []repository.BookSearchHit{
+		{ID: 1, Name: "Atomic Habits", Category: "Self Development", Rank: 0.6, Snippet: "Atomic <mark>Habits</mark> - Self Development"},
+		{ID: 2, Name: "The Power of Habit", Category: "Self Development", Rank: 0.3, Snippet: "The Power of <mark>Habit</mark> - Self Development"},
+	}}
+
+	rec := searchBooks(repo, "/api/products/search?q=+habits+")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"fulltext:habits"}, repo.searches)
+
+	var resp dto.BookSearchResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.False(t, resp.Fuzzy)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, "Atomic <mark>Habits</mark> - Self Development", resp.Data[0].Snippet)
+	assert.Equal(t, dto.PaginationMeta{Page: 1, Limit: 20, Total: 2}, resp.Meta)
+}
+
+func TestSearchBooks_FallsBackToSimilarNames(t *testing.T) {
+	repo := &fakeSearchRepository{similar: []repository.BookSearchHit{
+		{ID: 1, Name: "Atomic Habits", Category: "Self Development", Rank: 0.5, Snippet: "Atomic Habits - Self Development"},
+	}}
+
+	rec := searchBooks(repo, "/api/products/search?q=atomik")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"fulltext:atomik", "similar:atomik"}, repo.searches)
+
+	var resp dto.BookSearchResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Fuzzy)
+	assert.Len(t, resp.Data, 1)
+}
+
+func TestSearchBooks_InvalidQuery(t *testing.T) {
+	for _, target := range []string{"/api/products/search", "/api/products/search?q=+", "/api/products/search?q=habit&page=x"} {
+		repo := &fakeSearchRepository{}
+
+		rec := searchBooks(repo, target)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Empty(t, repo.searches)
+	}
+}
+
+func TestSearchBooks_Failure(t *testing.T) {
+	rec := searchBooks(&fakeSearchRepository{err: errors.New("connection refused")}, "/api/products/search?q=habit")
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,203 @@
+package book_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func transferContext(method, target, contentType, body, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"role":    role,
+	}))
+	return c, rec
+}
+
+func TestImportBooks_CSV(t *testing.T) {
+	body := "Name,ISBN,Category,Categories,Rental_Cost,Stok,Authors,Shelf\n" +
+		"Atomic Habits,978-0-7352-1129-2,Self Development,Psychology;Self Development,20000,3,James Clear,A1\n" +
+		"\"Laskar\nPelangi\",,Novel,,15000,two,Andrea Hirata; ,B2\n"
+	c, rec := transferContext(http.MethodPost, "/admin/books/import", "text/csv", body, "admin")
+
+	mockService := new(service.BookTransferServiceMock)
+	mockService.On("ImportBooks", mock.MatchedBy(func(rows []service.BookImportRow) bool {
+		if len(rows) != 2 || rows[0].Line != 2 || rows[1].Line != 3 {
+			return false
+		}
+		first, second := rows[0].Book, rows[1].Book
+		return rows[0].Err == nil && first.Name == "Atomic Habits" && *first.ISBN == "978-0-7352-1129-2" &&
+			first.RentalCost == 20000 && first.Stok == 3 &&
+			len(first.Categories) == 2 && first.Categories[0].Name == "Self Development" && first.Categories[1].Name == "Psychology" &&
+			len(first.Authors) == 1 && first.Authors[0].Name == "James Clear" &&
+			second.Name == "Laskar\nPelangi" && rows[1].Err != nil && strings.Contains(rows[1].Err.Error(), "stok")
+	}), false).Return(service.BookImportReport{
+		Rows:    2,
+		Created: 1,
+		Errors:  []service.BookImportRowError{{Line: 3, Err: errors.New("stok must be a whole number")}},
+	}, service.ErrImportFailed)
+
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ImportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var resp dto.BookImportResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Data.Rows)
+	assert.Equal(t, []dto.BookImportRowError{{Line: 3, Error: "stok must be a whole number"}}, resp.Data.Errors)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportBooks_JSONLinesDryRun(t *testing.T) {
+	body := `{"name":"Atomic Habits","isbn":"9780735211292","category":"Self Development","rental_cost":20000,"stok":2}` + "\n\n" +
+		`{"name":` + "\n"
+	c, rec := transferContext(http.MethodPost, "/admin/books/import?dry_run=true", "application/x-ndjson", body, "admin")
+
+	mockService := new(service.BookTransferServiceMock)
+	mockService.On("ImportBooks", mock.MatchedBy(func(rows []service.BookImportRow) bool {
+		return len(rows) == 2 && rows[0].Line == 1 && rows[0].Err == nil && rows[0].Book.Stok == 2 &&
+			rows[1].Line == 3 && rows[1].Err != nil
+	}), true).Return(service.BookImportReport{DryRun: true, Rows: 2, Created: 2}, nil)
+
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ImportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.BookImportResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.DryRun)
+	assert.Empty(t, resp.Data.Errors)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportBooks_BadRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+	}{
+		{"unknown format", "/admin/books/import", "text/plain", "name\nAtomic Habits\n"},
+		{"bad dry run", "/admin/books/import?format=csv&dry_run=maybe", "", "name\nAtomic Habits\n"},
+		{"no name column", "/admin/books/import?format=csv", "", "title\nAtomic Habits\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := transferContext(http.MethodPost, tt.target, tt.contentType, tt.body, "admin")
+
+			mockService := new(service.BookTransferServiceMock)
+			h := handler.NewBookTransferHandler(mockService)
+			err := h.ImportBooks(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			mockService.AssertNotCalled(t, "ImportBooks", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestImportBooks_NotAdmin(t *testing.T) {
+	c, rec := transferContext(http.MethodPost, "/admin/books/import", "text/csv", "name\nAtomic Habits\n", "user")
+
+	mockService := new(service.BookTransferServiceMock)
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ImportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockService.AssertNotCalled(t, "ImportBooks", mock.Anything, mock.Anything)
+}
+
+func exportedBooks() []model.Book {
+	isbn := "9780735211292"
+	return []model.Book{
+		{
+			Name: "Atomic Habits", Stok: 3, RentalCost: 20000, Category: "Self Development", ISBN: &isbn,
+			Authors:    []model.Author{{Name: "James Clear"}},
+			Categories: []model.Category{{Name: "Self Development"}, {Name: "Psychology"}},
+		},
+		{Name: "Laskar Pelangi", Stok: 0, RentalCost: 15000, Category: "Novel", Synopsis: "Sepuluh anak, satu sekolah"},
+	}
+}
+
+func TestExportBooks_CSV(t *testing.T) {
+	c, rec := transferContext(http.MethodGet, "/admin/books/export", "", "", "admin")
+
+	mockService := new(service.BookTransferServiceMock)
+	mockService.On("ExportBooks").Return(exportedBooks(), nil)
+
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ExportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "name", records[0][2])
+	assert.Equal(t, []string{"0", "9780735211292", "Atomic Habits", "Self Development", "Self Development;Psychology",
+		"20000", "3", "James Clear", "", "0", "", "0", "", ""}, records[1])
+	assert.Equal(t, "Sepuluh anak, satu sekolah", records[2][12])
+	mockService.AssertExpectations(t)
+}
+
+func TestExportBooks_JSONLines(t *testing.T) {
+	c, rec := transferContext(http.MethodGet, "/admin/books/export?format=jsonl", "", "", "admin")
+
+	mockService := new(service.BookTransferServiceMock)
+	mockService.On("ExportBooks").Return(exportedBooks(), nil)
+
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ExportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	var record dto.BookTransferRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, []string{"James Clear"}, record.Authors)
+	assert.Equal(t, []string{"Self Development", "Psychology"}, record.Categories)
+	mockService.AssertExpectations(t)
+}
+
+func TestExportBooks_Failure(t *testing.T) {
+	c, rec := transferContext(http.MethodGet, "/admin/books/export", "", "", "admin")
+
+	mockService := new(service.BookTransferServiceMock)
+	mockService.On("ExportBooks").Return([]model.Book{}, errors.New("db error"))
+
+	h := handler.NewBookTransferHandler(mockService)
+	err := h.ExportBooks(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,81 @@
+package book_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func updateBook(mockService *service.BookServiceMock, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/books/1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/books/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "admin",
+	})
+	c.Set("user", token)
+
+	handler := handler.ProductHandler{Service: mockService}
+	handler.UpdateBookByID(c)
+	return rec
+}
+
+func TestUpdateBookByID_WithMetadata(t *testing.T) {
+	mockService := new(service.BookServiceMock)
+
+	publisher, year, language := "Bentang Pustaka", 2005, "id"
+	req := dto.UpdateBookRequest{
+		Name: "Laskar Pelangi", RentalCost: 10000, Category: "Novel",
+		UpdateBookMetadataRequest: dto.UpdateBookMetadataRequest{
+			Authors: []string{"Andrea Hirata"}, Publisher: &publisher, PublicationYear: &year, Language: &language,
+		},
+	}
+	updated := model.Book{
+		Model: gorm.Model{ID: 1}, Name: "Laskar Pelangi", Stok: 3, RentalCost: 10000, Category: "Novel",
+		Publisher: "Bentang Pustaka", PublicationYear: 2005, Language: "id",
+		Authors: []model.Author{{Model: gorm.Model{ID: 4}, Name: "Andrea Hirata"}},
+	}
+	mockService.On("UpdateBookByID", req, uint(1)).Return(updated, nil)
+
+	rec := updateBook(mockService, `{"name":"Laskar Pelangi","rental_cost":10000,"category":"Novel",
+		"authors":["Andrea Hirata"],"publisher":"Bentang Pustaka","publication_year":2005,"language":"id"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.UpdateBookByIDResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(1), resp.Data.ID)
+	assert.Equal(t, 3, resp.Data.Stok)
+	assert.Equal(t, []dto.AuthorData{{ID: 4, Name: "Andrea Hirata"}}, resp.Data.Authors)
+	assert.Empty(t, resp.Data.ISBN)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateBookByID_NotFound(t *testing.T) {
+	mockService := new(service.BookServiceMock)
+	mockService.On("UpdateBookByID", dto.UpdateBookRequest{Name: "Golang", RentalCost: 5000, Category: "Programming"}, uint(1)).
+		Return(model.Book{}, service.ErrBookNotFound)
+
+	rec := updateBook(mockService, `{"name":"Golang","rental_cost":5000,"category":"Programming"}`)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockService.AssertExpectations(t)
+}
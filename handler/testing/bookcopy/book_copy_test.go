@@ -0,0 +1,150 @@
+package bookcopy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, target, body, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(7),
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestGetCopyByBarcode_OnLoan(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/copies/barcode/PB0000010001", "", "admin")
+	c.SetParamNames("barcode")
+	c.SetParamValues("PB0000010001")
+
+	dueDate := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+	detail := service.BookCopyDetail{
+		BookCopy: model.BookCopy{
+			Model:   gorm.Model{ID: 4},
+			BookID:  1,
+			Barcode: "PB0000010001",
+			Status:  model.BookCopyStatusOnLoan,
+			Book:    model.Book{Name: "Atomic Habits"},
+		},
+		Rental: &model.Rental{
+			Model:      gorm.Model{ID: 12},
+			UserID:     3,
+			RentDate:   time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC),
+			ReturnDate: &dueDate,
+			Status:     model.RentalStatusBorrowed,
+			User:       model.User{Email: "reader@mail.com"},
+		},
+	}
+
+	mockService := new(service.BookCopyServiceMock)
+	mockService.On("GetCopyByBarcode", "PB0000010001").Return(detail, nil)
+
+	h := handler.NewBookCopyHandler(mockService)
+	err := h.GetCopyByBarcode(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.BookCopyResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Atomic Habits", resp.Data.BookTitle)
+	assert.Equal(t, model.BookCopyStatusOnLoan, resp.Data.Status)
+	assert.NotNil(t, resp.Data.Rental)
+	assert.Equal(t, "reader@mail.com", resp.Data.Rental.UserEmail)
+	assert.Equal(t, "2025-07-10", resp.Data.Rental.ReturnDate)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCopyByBarcode_NotFound(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/copies/barcode/UNKNOWN", "", "admin")
+	c.SetParamNames("barcode")
+	c.SetParamValues("UNKNOWN")
+
+	mockService := new(service.BookCopyServiceMock)
+	mockService.On("GetCopyByBarcode", "UNKNOWN").Return(service.BookCopyDetail{}, service.ErrBookCopyNotFound)
+
+	h := handler.NewBookCopyHandler(mockService)
+	err := h.GetCopyByBarcode(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateCopy_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/copies", `{"book_id":1,"shelf_location":"A-3"}`, "admin")
+
+	mockService := new(service.BookCopyServiceMock)
+	mockService.On("CreateCopy", model.BookCopy{BookID: 1, ShelfLocation: "A-3"}).Return(model.BookCopy{
+		Model:         gorm.Model{ID: 5},
+		BookID:        1,
+		Barcode:       "PB0000010005",
+		Condition:     model.BookCopyConditionGood,
+		ShelfLocation: "A-3",
+		Status:        model.BookCopyStatusAvailable,
+	}, nil)
+
+	h := handler.NewBookCopyHandler(mockService)
+	err := h.CreateCopy(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.BookCopyResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "PB0000010005", resp.Data.Barcode)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateCopy_NotAdmin(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/copies", `{"book_id":1}`, "user")
+
+	mockService := new(service.BookCopyServiceMock)
+
+	h := handler.NewBookCopyHandler(mockService)
+	err := h.CreateCopy(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockService.AssertNotCalled(t, "CreateCopy")
+}
+
+func TestUpdateCopy_InUse(t *testing.T) {
+	c, rec := newContext(http.MethodPut, "/copies/4", `{"status":"Retired"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("4")
+
+	mockService := new(service.BookCopyServiceMock)
+	mockService.On("UpdateCopy", uint(4), service.BookCopyUpdate{Status: "Retired"}).Return(model.BookCopy{}, service.ErrBookCopyInUse)
+
+	h := handler.NewBookCopyHandler(mockService)
+	err := h.UpdateCopy(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockService.AssertExpectations(t)
+}
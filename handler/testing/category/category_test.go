@@ -0,0 +1,179 @@
+package category
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, target, body, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestListCategories_Success(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/categories", "", "user")
+
+	parentID := uint(1)
+	mockCategoryService := new(service.CategoryServiceMock)
+	mockCategoryService.On("ListCategories").Return([]repository.CategoryCount{
+		{ID: 2, Slug: "novel", Name: "Novel", ParentID: &parentID, BookCount: 4},
+		{ID: 1, Slug: "sastra", Name: "Sastra", BookCount: 0},
+	}, nil)
+
+	handler := handler.NewCategoryHandler(mockCategoryService)
+	err := handler.ListCategories(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.CategoryListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, int64(4), resp.Data[0].BookCount)
+	assert.Equal(t, &parentID, resp.Data[0].ParentID)
+	assert.Nil(t, resp.Data[1].ParentID)
+	mockCategoryService.AssertExpectations(t)
+}
+
+func TestCreateCategory_NotAdmin(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/categories", `{"name":"Novel"}`, "user")
+
+	mockCategoryService := new(service.CategoryServiceMock)
+
+	handler := handler.NewCategoryHandler(mockCategoryService)
+	err := handler.CreateCategory(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockCategoryService.AssertNotCalled(t, "CreateCategory")
+}
+
+func TestCreateCategory_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/categories", `{"name":"Novel","parent_id":1}`, "admin")
+
+	parentID := uint(1)
+	mockCategoryService := new(service.CategoryServiceMock)
+	mockCategoryService.On("CreateCategory", model.Category{Name: "Novel", ParentID: &parentID}).
+		Return(model.Category{Model: gorm.Model{ID: 2}, Slug: "novel", Name: "Novel", ParentID: &parentID}, nil)
+
+	handler := handler.NewCategoryHandler(mockCategoryService)
+	err := handler.CreateCategory(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.CategoryResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "novel", resp.Data.Slug)
+	mockCategoryService.AssertExpectations(t)
+}
+
+func TestCreateCategory_Errors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidCategory, http.StatusBadRequest},
+		{service.ErrCategoryTaken, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		c, rec := newContext(http.MethodPost, "/admin/categories", `{"name":"Self Development"}`, "admin")
+
+		mockCategoryService := new(service.CategoryServiceMock)
+		mockCategoryService.On("CreateCategory", model.Category{Name: "Self Development"}).Return(model.Category{}, tc.err)
+
+		handler := handler.NewCategoryHandler(mockCategoryService)
+		err := handler.CreateCategory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, rec.Code, tc.err.Error())
+	}
+}
+
+func TestUpdateCategory_NotFound(t *testing.T) {
+	c, rec := newContext(http.MethodPut, "/admin/categories/9", `{"name":"Novel"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("9")
+
+	mockCategoryService := new(service.CategoryServiceMock)
+	mockCategoryService.On("UpdateCategory", uint(9), model.Category{Name: "Novel"}).Return(model.Category{}, service.ErrCategoryNotFound)
+
+	handler := handler.NewCategoryHandler(mockCategoryService)
+	err := handler.UpdateCategory(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockCategoryService.AssertExpectations(t)
+}
+
+func TestDeleteCategory(t *testing.T) {
+	mergeInto := uint(3)
+	cases := []struct {
+		target    string
+		mergeInto *uint
+		err       error
+		code      int
+	}{
+		{"/admin/categories/2", nil, nil, http.StatusNoContent},
+		{"/admin/categories/2", nil, service.ErrCategoryInUse, http.StatusConflict},
+		{"/admin/categories/2?merge_into=3", &mergeInto, nil, http.StatusNoContent},
+		{"/admin/categories/2?merge_into=3", &mergeInto, service.ErrCategoryNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		c, rec := newContext(http.MethodDelete, tc.target, "", "admin")
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+
+		mockCategoryService := new(service.CategoryServiceMock)
+		mockCategoryService.On("DeleteCategory", uint(2), tc.mergeInto).Return(tc.err)
+
+		handler := handler.NewCategoryHandler(mockCategoryService)
+		err := handler.DeleteCategory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, rec.Code, tc.target)
+		mockCategoryService.AssertExpectations(t)
+	}
+}
+
+func TestDeleteCategory_InvalidMergeInto(t *testing.T) {
+	c, rec := newContext(http.MethodDelete, "/admin/categories/2?merge_into=novel", "", "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+
+	mockCategoryService := new(service.CategoryServiceMock)
+
+	handler := handler.NewCategoryHandler(mockCategoryService)
+	err := handler.DeleteCategory(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockCategoryService.AssertNotCalled(t, "DeleteCategory")
+}
@@ -0,0 +1,168 @@
+package deposit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newUserContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(3),
+		"role":    "user",
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestCreateDeposit_ReturnsOrderID(t *testing.T) {
+	c, rec := newUserContext(http.MethodPost, "/user/deposit", `{"amount": 50000}`)
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("CreateTransaction", uint(3), 50000, "").Return(gateway.Charge{
+		OrderID:     "ORDER-3-1",
+		Token:       "snap-token",
+		RedirectURL: "https://app.sandbox.midtrans.com/snap/v2/vtweb/snap-token",
+	}, nil)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.Create(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.DepositResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "ORDER-3-1", resp.OrderID)
+	assert.Equal(t, "https://app.sandbox.midtrans.com/snap/v2/vtweb/snap-token", resp.PayURL)
+	mockDepositService.AssertExpectations(t)
+}
+
+func TestCreateDeposit_VoucherNotFound(t *testing.T) {
+	c, rec := newUserContext(http.MethodPost, "/user/deposit", `{"amount": 150000, "voucher_code": "NOPE"}`)
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("CreateTransaction", uint(3), 150000, "NOPE").Return(gateway.Charge{}, service.ErrVoucherNotFound)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.Create(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Voucher cannot be used", resp.Message)
+	mockDepositService.AssertExpectations(t)
+}
+
+func TestListDeposits_Success(t *testing.T) {
+	c, rec := newUserContext(http.MethodGet, "/user/deposits?status=settlement&page=1&limit=5", "")
+
+	paidAt := time.Date(2025, 7, 3, 8, 5, 0, 0, time.UTC)
+	deposits := []model.DepositTransaction{{
+		Model:      gorm.Model{CreatedAt: time.Date(2025, 7, 3, 8, 0, 0, 0, time.UTC)},
+		UserID:     3,
+		OrderID:    "ORDER-3-1",
+		PaymentRef: "snap-token",
+		Deposit:    50000,
+		Status:     model.DepositStatusSettlement,
+		PaidAt:     &paidAt,
+	}}
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("ListTransactions", uint(3), "settlement", 1, 5).
+		Return(service.DepositPage{Deposits: deposits, Total: 1, Page: 1, Limit: 5}, nil)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.ListTransactions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.DepositTransactionListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, "ORDER-3-1", resp.Data[0].OrderID)
+	assert.Equal(t, 50000, resp.Data[0].Amount)
+	assert.Equal(t, "2025-07-03T08:05:00Z", resp.Data[0].PaidAt)
+	assert.Equal(t, "snap-token", resp.Data[0].PaymentRef)
+	assert.Equal(t, dto.PaginationMeta{Page: 1, Limit: 5, Total: 1}, resp.Meta)
+	mockDepositService.AssertExpectations(t)
+}
+
+func TestListDeposits_InvalidStatus(t *testing.T) {
+	c, rec := newUserContext(http.MethodGet, "/user/deposits?status=paid", "")
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("ListTransactions", uint(3), "paid", 0, 0).Return(service.DepositPage{}, service.ErrInvalidDepositStatus)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.ListTransactions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetDeposit_Success(t *testing.T) {
+	c, rec := newUserContext(http.MethodGet, "/", "")
+	c.SetPath("/user/deposits/:order_id")
+	c.SetParamNames("order_id")
+	c.SetParamValues("ORDER-3-1")
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("GetTransaction", uint(3), "ORDER-3-1").Return(model.DepositTransaction{
+		UserID:  3,
+		OrderID: "ORDER-3-1",
+		Deposit: 50000,
+		Status:  model.DepositStatusPending,
+	}, nil)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.GetTransaction(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.DepositTransactionDetailResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "pending", resp.Data.Status)
+	assert.Empty(t, resp.Data.PaidAt)
+}
+
+func TestGetDeposit_NotFound(t *testing.T) {
+	c, rec := newUserContext(http.MethodGet, "/", "")
+	c.SetPath("/user/deposits/:order_id")
+	c.SetParamNames("order_id")
+	c.SetParamValues("ORDER-9-1")
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("GetTransaction", uint(3), "ORDER-9-1").Return(model.DepositTransaction{}, service.ErrDepositOrderNotFound)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.GetTransaction(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,95 @@
+package deposit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const notificationBody = `{
+	"order_id": "ORDER-3-1718000000",
+	"status_code": "200",
+	"gross_amount": "50000.00",
+	"signature_key": "abc123",
+	"transaction_status": "settlement"
+}`
+
+var notification = service.DepositNotification{
+	OrderID:           "ORDER-3-1718000000",
+	StatusCode:        "200",
+	GrossAmount:       "50000.00",
+	SignatureKey:      "abc123",
+	TransactionStatus: "settlement",
+	Payload:           notificationBody,
+}
+
+func newWebhookContext(body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/webhook/deposit", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestWebhook_Success(t *testing.T) {
+	c, rec := newWebhookContext(notificationBody)
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+	mockDepositService.On("HandleWebhook", notification).Return(nil)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.Webhook(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockDepositService.AssertExpectations(t)
+}
+
+func TestWebhook_MissingSignature(t *testing.T) {
+	c, rec := newWebhookContext(`{"order_id": "ORDER-3-1718000000", "transaction_status": "settlement"}`)
+
+	mockDepositService := new(service.DepositTransactionServiceMock)
+
+	handler := handler.NewDepositTransactionHandler(mockDepositService)
+	err := handler.Webhook(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockDepositService.AssertNotCalled(t, "HandleWebhook")
+}
+
+func TestWebhook_ServiceErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid signature", service.ErrInvalidSignature, http.StatusForbidden},
+		{"unknown order", service.ErrDepositOrderNotFound, http.StatusNotFound},
+		{"amount mismatch", service.ErrGrossAmountMismatch, http.StatusBadRequest},
+		{"unexpected", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := newWebhookContext(notificationBody)
+
+			mockDepositService := new(service.DepositTransactionServiceMock)
+			mockDepositService.On("HandleWebhook", notification).Return(tc.err)
+
+			handler := handler.NewDepositTransactionHandler(mockDepositService)
+			err := handler.Webhook(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.code, rec.Code)
+			mockDepositService.AssertExpectations(t)
+		})
+	}
+}
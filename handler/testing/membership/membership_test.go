@@ -0,0 +1,164 @@
+package membership
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, target, body string, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(3),
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func premiumMembership() model.Membership {
+	startsAt := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	return model.Membership{
+		Model:     gorm.Model{ID: 7},
+		UserID:    3,
+		PlanID:    2,
+		Price:     120000,
+		StartsAt:  startsAt,
+		ExpiresAt: startsAt.AddDate(0, 0, 30),
+		AutoRenew: true,
+		Status:    model.MembershipStatusActive,
+		Plan:      model.MembershipPlan{Name: "Premium"},
+	}
+}
+
+func TestListPlans_Success(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/membership-plans", "", "user")
+
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("ListPlans").Return([]model.MembershipPlan{
+		{Model: gorm.Model{ID: 1}, Name: "Basic", Price: 50000, DurationDays: 30, RentalQuota: 3},
+		{Model: gorm.Model{ID: 2}, Name: "Premium", Price: 120000, DurationDays: 30},
+	}, nil)
+
+	handler := handler.NewMembershipHandler(mockMembershipService)
+	err := handler.ListPlans(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.MembershipPlanListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 2)
+	assert.False(t, resp.Data[0].Unlimited)
+	assert.True(t, resp.Data[1].Unlimited)
+	mockMembershipService.AssertExpectations(t)
+}
+
+func TestCreatePlan_NotAdmin(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/membership-plans", `{"name":"Basic","price":50000,"duration_days":30,"rental_quota":3}`, "user")
+
+	mockMembershipService := new(service.MembershipServiceMock)
+
+	handler := handler.NewMembershipHandler(mockMembershipService)
+	err := handler.CreatePlan(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockMembershipService.AssertNotCalled(t, "CreatePlan")
+}
+
+func TestCreatePlan_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/membership-plans", `{"name":"Basic","price":50000,"duration_days":30,"rental_quota":3}`, "admin")
+
+	plan := model.MembershipPlan{Name: "Basic", Price: 50000, DurationDays: 30, RentalQuota: 3}
+	created := plan
+	created.ID = 1
+
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("CreatePlan", plan).Return(created, nil)
+
+	handler := handler.NewMembershipHandler(mockMembershipService)
+	err := handler.CreatePlan(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	mockMembershipService.AssertExpectations(t)
+}
+
+func TestSubscribe_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/user/membership", `{"plan_id":2,"auto_renew":true}`, "user")
+
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("Subscribe", uint(3), uint(2), true).Return(premiumMembership(), nil)
+
+	handler := handler.NewMembershipHandler(mockMembershipService)
+	err := handler.Subscribe(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.MembershipResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Premium", resp.Data.PlanName)
+	assert.True(t, resp.Data.Unlimited)
+	assert.Nil(t, resp.Data.RemainingQuota)
+	mockMembershipService.AssertExpectations(t)
+}
+
+func TestSubscribe_Errors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrMembershipPlanNotFound, http.StatusNotFound},
+		{service.ErrMembershipActive, http.StatusConflict},
+		{service.ErrInsufficientDeposit, http.StatusBadRequest},
+		{service.ErrOutstandingBalance, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		c, rec := newContext(http.MethodPost, "/user/membership", `{"plan_id":1}`, "user")
+
+		mockMembershipService := new(service.MembershipServiceMock)
+		mockMembershipService.On("Subscribe", uint(3), uint(1), false).Return(model.Membership{}, tc.err)
+
+		handler := handler.NewMembershipHandler(mockMembershipService)
+		err := handler.Subscribe(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, rec.Code, tc.err.Error())
+	}
+}
+
+func TestSetAutoRenew_NoMembership(t *testing.T) {
+	c, rec := newContext(http.MethodPut, "/user/membership/auto-renew", `{"auto_renew":false}`, "user")
+
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("SetAutoRenew", uint(3), false).Return(model.Membership{}, service.ErrMembershipNotFound)
+
+	handler := handler.NewMembershipHandler(mockMembershipService)
+	err := handler.SetAutoRenew(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockMembershipService.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+package pricingplan
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetBookPlans_Success(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/products/1/plans", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/products/:id/plans")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	bookID := uint(1)
+	mockService := new(service.PricingPlanServiceMock)
+	mockService.On("GetPlansForBook", uint(1)).Return([]model.PricingPlan{
+		{Model: gorm.Model{ID: 1}, BookID: &bookID, Name: "3 days", DurationDays: 3, Price: 8000},
+		{Model: gorm.Model{ID: 2}, Category: "Novel", Name: "14 days", DurationDays: 14, Price: 30000},
+	}, nil)
+
+	handler := handler.NewPricingPlanHandler(mockService)
+	err := handler.GetBookPlans(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.PricingPlanListResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, 3, resp.Data[0].DurationDays)
+	assert.Equal(t, "Novel", resp.Data[1].Category)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreatePlan_Success(t *testing.T) {
+	e := echo.New()
+	body := `{"category":"Novel","name":"14 days","duration_days":14,"price":30000}`
+	req := httptest.NewRequest(http.MethodPost, "/pricing-plans", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT dengan role admin
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "admin",
+	})
+	c.Set("user", token)
+
+	plan := model.PricingPlan{Category: "Novel", Name: "14 days", DurationDays: 14, Price: 30000}
+	created := plan
+	created.ID = 5
+
+	mockService := new(service.PricingPlanServiceMock)
+	mockService.On("CreatePlan", plan).Return(created, nil)
+
+	handler := handler.NewPricingPlanHandler(mockService)
+	err := handler.CreatePlan(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.PricingPlanResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), resp.Data.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreatePlan_CategoryNotFound(t *testing.T) {
+	e := echo.New()
+	body := `{"category":"Novle","name":"14 days","duration_days":14,"price":30000}`
+	req := httptest.NewRequest(http.MethodPost, "/pricing-plans", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "admin",
+	})
+	c.Set("user", token)
+
+	plan := model.PricingPlan{Category: "Novle", Name: "14 days", DurationDays: 14, Price: 30000}
+	mockService := new(service.PricingPlanServiceMock)
+	mockService.On("CreatePlan", plan).Return(model.PricingPlan{}, service.ErrCategoryNotFound)
+
+	handler := handler.NewPricingPlanHandler(mockService)
+	err := handler.CreatePlan(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreatePlan_Unauthorized(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/pricing-plans", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// role bukan admin
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "user",
+	})
+	c.Set("user", token)
+
+	mockService := new(service.PricingPlanServiceMock)
+	handler := handler.NewPricingPlanHandler(mockService)
+	err := handler.CreatePlan(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,215 @@
+package rental
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newAdminContext(method, target, body, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(7),
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestListRentals_Success(t *testing.T) {
+	c, rec := newAdminContext(http.MethodGet, "/admin/rentals?status=Borrowed&user_id=3&from=2025-07-01&to=2025-07-31&sort=-rent_date&page=2&limit=10", "", "admin")
+
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	filter := repository.RentalFilter{Status: "Borrowed", UserID: 3, From: &from, To: &to, Sort: "-rent_date", Page: 2, Limit: 10}
+
+	dueDate := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+	rentals := []model.Rental{{
+		Model:      gorm.Model{ID: 11},
+		UserID:     3,
+		BookID:     2,
+		RentDate:   time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC),
+		ReturnDate: &dueDate,
+		Status:     model.RentalStatusBorrowed,
+		User:       model.User{Email: "reader@mail.com"},
+		Book:       model.Book{Name: "Atomic Habits"},
+	}}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ListRentals", filter).Return(service.RentalPage{Rentals: rentals, Total: 11, Page: 2, Limit: 10}, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ListRentals(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.AdminRentalListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, "reader@mail.com", resp.Data[0].UserEmail)
+	assert.Equal(t, "Atomic Habits", resp.Data[0].BookTitle)
+	assert.Equal(t, "2025-07-10", resp.Data[0].ReturnDate)
+	assert.Equal(t, dto.PaginationMeta{Page: 2, Limit: 10, Total: 11}, resp.Meta)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestListRentals_InvalidQuery(t *testing.T) {
+	c, rec := newAdminContext(http.MethodGet, "/admin/rentals?from=yesterday", "", "admin")
+
+	mockRentalService := new(service.RentalServiceMock)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ListRentals(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockRentalService.AssertNotCalled(t, "ListRentals")
+}
+
+func TestListRentals_NotAdmin(t *testing.T) {
+	c, rec := newAdminContext(http.MethodGet, "/admin/rentals", "", "user")
+
+	mockRentalService := new(service.RentalServiceMock)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ListRentals(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockRentalService.AssertNotCalled(t, "ListRentals")
+}
+
+func TestCancelRental_Success(t *testing.T) {
+	c, rec := newAdminContext(http.MethodPost, "/admin/rentals/5/cancel", `{"reason":"Scanned the wrong book"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	dueDate := time.Now().AddDate(0, 0, 7)
+	cancelled := model.Rental{
+		Model:      gorm.Model{ID: 5},
+		BookID:     2,
+		RentDate:   time.Now(),
+		ReturnDate: &dueDate,
+		Status:     model.RentalStatusCancelled,
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CancelRental", uint(5), uint(7), "Scanned the wrong book").Return(cancelled, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CancelRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.AdminRentalActionResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Success Cancel Rental", resp.Message)
+	assert.Equal(t, model.RentalStatusCancelled, resp.Data.Status)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestMarkRentalLost_ReasonRequired(t *testing.T) {
+	c, rec := newAdminContext(http.MethodPost, "/admin/rentals/5/lost", `{}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("MarkRentalLost", uint(5), uint(7), "").Return(model.Rental{}, service.ErrReasonRequired)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.MarkRentalLost(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestForceCloseRental_AlreadyClosed(t *testing.T) {
+	c, rec := newAdminContext(http.MethodPost, "/admin/rentals/5/close", `{"reason":"Found on the shelf"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ForceCloseRental", uint(5), uint(7), "Found on the shelf").Return(model.Rental{}, service.ErrInvalidRentalTransition)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ForceCloseRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestReportDamage_Success(t *testing.T) {
+	c, rec := newAdminContext(http.MethodPost, "/admin/rentals/5/damage", `{"condition":"Damaged","notes":"Water damage on the cover"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	dueDate := time.Now().AddDate(0, 0, 2)
+	returnedAt := time.Now()
+	returned := model.Rental{
+		Model:      gorm.Model{ID: 5},
+		BookID:     2,
+		RentDate:   time.Now().AddDate(0, 0, -5),
+		ReturnDate: &dueDate,
+		ReturnedAt: &returnedAt,
+		DamageFee:  25000,
+		Status:     model.RentalStatusReturned,
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ReportDamage", uint(5), uint(7), "Damaged", "Water damage on the cover").Return(returned, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReportDamage(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.AdminRentalActionResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Success Report Damage", resp.Message)
+	assert.Equal(t, 25000, resp.Data.DamageFee)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestReportDamage_InvalidCondition(t *testing.T) {
+	c, rec := newAdminContext(http.MethodPost, "/admin/rentals/5/damage", `{"condition":"Good","notes":"Looks fine"}`, "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ReportDamage", uint(5), uint(7), "Good", "Looks fine").Return(model.Rental{}, service.ErrInvalidDamageCondition)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReportDamage(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockRentalService.AssertExpectations(t)
+}
@@ -0,0 +1,145 @@
+package rental
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCheckout_Success(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"items": [{"book_id": 1, "quantity": 2}, {"book_id": 3, "quantity": 1}]}`)
+
+	dueDate := time.Now().AddDate(0, 0, 7)
+	checkoutID := uint(4)
+	checkout := model.Checkout{
+		Model:     gorm.Model{ID: checkoutID},
+		UserID:    1,
+		TotalCost: 25000,
+		Rentals: []model.Rental{
+			{Model: gorm.Model{ID: 10}, BookID: 1, CheckoutID: &checkoutID, RentDate: time.Now(), ReturnDate: &dueDate, Status: model.RentalStatusBorrowed},
+			{Model: gorm.Model{ID: 11}, BookID: 1, CheckoutID: &checkoutID, RentDate: time.Now(), ReturnDate: &dueDate, Status: model.RentalStatusBorrowed},
+			{Model: gorm.Model{ID: 12}, BookID: 3, CheckoutID: &checkoutID, RentDate: time.Now(), ReturnDate: &dueDate, Status: model.RentalStatusBorrowed},
+		},
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("Checkout", uint(1), []service.CartItem{
+		{BookID: 1, Quantity: 2},
+		{BookID: 3, Quantity: 1},
+	}, "").Return(checkout, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.Checkout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.CheckoutResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), resp.Data.CheckoutID)
+	assert.Equal(t, 25000, resp.Data.TotalCost)
+	assert.Len(t, resp.Data.Rentals, 3)
+	assert.Equal(t, uint(12), resp.Data.Rentals[2].RentalID)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCheckout_BookNotAvailable(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"items": [{"book_id": 3, "quantity": 5}]}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("Checkout", uint(1), []service.CartItem{{BookID: 3, Quantity: 5}}, "").
+		Return(model.Checkout{}, fmt.Errorf("%w: book %d", service.ErrBookNotAvailable, 3))
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.Checkout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Book not available", resp.Message)
+	assert.Equal(t, "book not available: book 3", resp.Details)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCheckout_EmptyCart(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"items": []}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("Checkout", uint(1), []service.CartItem{}, "").Return(model.Checkout{}, service.ErrEmptyCart)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.Checkout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCheckout_WithVoucher(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"items": [{"book_id": 1, "quantity": 1}], "voucher_code": "FIRSTFREE"}`)
+
+	checkoutID := uint(5)
+	dueDate := time.Now().AddDate(0, 0, 7)
+	checkout := model.Checkout{
+		Model:     gorm.Model{ID: checkoutID},
+		UserID:    1,
+		TotalCost: 0,
+		Discount:  15000,
+		Rentals: []model.Rental{
+			{Model: gorm.Model{ID: 13}, BookID: 1, CheckoutID: &checkoutID, Price: 15000, Discount: 15000, RentDate: time.Now(), ReturnDate: &dueDate, Status: model.RentalStatusBorrowed},
+		},
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("Checkout", uint(1), []service.CartItem{{BookID: 1, Quantity: 1}}, "FIRSTFREE").Return(checkout, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.Checkout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.CheckoutResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 0, resp.Data.TotalCost)
+	assert.Equal(t, 15000, resp.Data.Discount)
+	assert.Equal(t, 15000, resp.Data.Rentals[0].Discount)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCheckout_VoucherUsedUp(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"items": [{"book_id": 1, "quantity": 1}], "voucher_code": "FIRSTFREE"}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("Checkout", uint(1), []service.CartItem{{BookID: 1, Quantity: 1}}, "FIRSTFREE").
+		Return(model.Checkout{}, service.ErrVoucherLimitReached)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.Checkout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Voucher cannot be used", resp.Message)
+	assert.Equal(t, service.ErrVoucherLimitReached.Error(), resp.Details)
+
+	mockRentalService.AssertExpectations(t)
+}
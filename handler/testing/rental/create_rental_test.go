@@ -13,16 +13,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
-	"gorm.io/gorm"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
-func TestCreateRental_Success(t *testing.T) {
+func newCreateRentalContext(body string) (echo.Context, *httptest.ResponseRecorder) {
 	e := echo.New()
-	body := `{"book_id": 1}`
-
 	req := httptest.NewRequest(http.MethodPost, "/rentals", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
@@ -35,23 +31,16 @@ func TestCreateRental_Success(t *testing.T) {
 	})
 	c.Set("user", token)
 
+	return c, rec
+}
+
+func TestCreateRental_Success(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1}`)
+
 	// Init mock services
 	mockRentalService := new(service.RentalServiceMock)
-	mockBookService := new(service.BookServiceMock)
-	mockUserService := new(service.UserServiceMock)
 
 	// Mock data
-	book := model.Book{
-		Model:      gorm.Model{ID: 1},
-		Stok:       2,
-		RentalCost: 5000,
-	}
-	deposit := 10000
-	user := model.User{
-		Model:  gorm.Model{ID: 1},
-		Email:  "user@mail.com",
-		Deposit: &deposit,
-	}
 	createdRental := model.Rental{
 		BookID:     1,
 		UserID:     1,
@@ -61,13 +50,10 @@ func TestCreateRental_Success(t *testing.T) {
 	}
 
 	// Set expectations
-	mockBookService.On("GetBookByID", uint(1)).Return(book, nil)
-	mockUserService.On("GetUserByEmail", "user@mail.com").Return(user, nil)
-	mockUserService.On("UpdateDepositUser", 5000, uint(1)).Return(user, nil)
-	mockRentalService.On("CreateRental", mock.AnythingOfType("model.Rental")).Return(createdRental, nil)
+	mockRentalService.On("CreateRental", uint(1), uint(1), (*uint)(nil)).Return(createdRental, nil)
 
 	// Call handler
-	handler := handler.NewRentalHandler(mockRentalService, mockBookService, mockUserService)
+	handler := handler.NewRentalHandler(mockRentalService)
 	err := handler.CreateRental(c)
 
 	// Assert
@@ -81,8 +67,63 @@ func TestCreateRental_Success(t *testing.T) {
 	assert.Equal(t, uint(1), resp.Data.BookID)
 
 	// Verify expectations
-	mockBookService.AssertExpectations(t)
-	mockUserService.AssertExpectations(t)
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCreateRental_InsufficientDeposit(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CreateRental", uint(1), uint(1), (*uint)(nil)).Return(model.Rental{}, service.ErrInsufficientDeposit)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CreateRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Insufficient deposit", resp.Message)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCreateRental_OutstandingBalance(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CreateRental", uint(1), uint(1), (*uint)(nil)).Return(model.Rental{}, service.ErrOutstandingBalance)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CreateRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Outstanding balance must be settled first", resp.Message)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCreateRental_BookNotAvailable(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1}`)
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CreateRental", uint(1), uint(1), (*uint)(nil)).Return(model.Rental{}, service.ErrBookNotAvailable)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CreateRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Book not available", resp.Message)
+
 	mockRentalService.AssertExpectations(t)
 }
 
@@ -90,4 +131,56 @@ func ptrToTime(t time.Time) *time.Time {
 	return &t
 }
 
+func TestCreateRental_WithPricingPlan(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1, "plan_id": 2}`)
+
+	planID := uint(2)
+	createdRental := model.Rental{
+		BookID:        1,
+		UserID:        1,
+		PricingPlanID: &planID,
+		DurationDays:  14,
+		Price:         35000,
+		RentDate:      time.Now(),
+		ReturnDate:    ptrToTime(time.Now().AddDate(0, 0, 14)),
+		Status:        "Borrowed",
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CreateRental", uint(1), uint(1), &planID).Return(createdRental, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CreateRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.RentalResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, &planID, resp.Data.PricingPlanID)
+	assert.Equal(t, 14, resp.Data.DurationDays)
+	assert.Equal(t, 35000, resp.Data.Price)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestCreateRental_UnknownPricingPlan(t *testing.T) {
+	c, rec := newCreateRentalContext(`{"book_id": 1, "plan_id": 9}`)
 
+	planID := uint(9)
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("CreateRental", uint(1), uint(1), &planID).Return(model.Rental{}, service.ErrPricingPlanNotFound)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.CreateRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Pricing plan not available for this book", resp.Message)
+
+	mockRentalService.AssertExpectations(t)
+}
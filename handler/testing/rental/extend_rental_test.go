@@ -0,0 +1,102 @@
+package rental
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newExtendContext(rentalID string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/rentals/"+rentalID+"/extend", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/rentals/:id/extend")
+	c.SetParamNames("id")
+	c.SetParamValues(rentalID)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestExtendRental_Success(t *testing.T) {
+	c, rec := newExtendContext("1")
+
+	dueDate := time.Now().AddDate(0, 0, 10)
+	extended := model.Rental{
+		Model:        gorm.Model{ID: 1},
+		UserID:       1,
+		BookID:       2,
+		ReturnDate:   &dueDate,
+		RenewalCount: 1,
+		Status:       model.RentalStatusBorrowed,
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ExtendRental", uint(1), uint(1)).Return(extended, 5000, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ExtendRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.ExtendRentalResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Success Extend Rental", resp.Message)
+	assert.Equal(t, dueDate.Format("2006-01-02"), resp.Data.ReturnDate)
+	assert.Equal(t, 1, resp.Data.RenewalCount)
+	assert.Equal(t, 5000, resp.Data.ExtensionCost)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestExtendRental_LimitReached(t *testing.T) {
+	c, rec := newExtendContext("1")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ExtendRental", uint(1), uint(1)).Return(model.Rental{}, 0, service.ErrRenewalLimitReached)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ExtendRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Rental renewal limit reached", resp.Message)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestExtendRental_InsufficientDeposit(t *testing.T) {
+	c, rec := newExtendContext("1")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ExtendRental", uint(1), uint(1)).Return(model.Rental{}, 0, service.ErrInsufficientDeposit)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ExtendRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockRentalService.AssertExpectations(t)
+}
@@ -39,6 +39,9 @@ func TestGetRentalByUserID_Success(t *testing.T) {
 			ReturnDate: &returnDate,
 			Status:     "Borrowed",
 			Book:       model.Book{Name: "Clean Architecture"},
+			Events: []model.RentalEvent{
+				{Type: model.RentalEventExtended, FromStatus: "Borrowed", ToStatus: "Borrowed", Amount: -20000},
+			},
 		},
 	}
 
@@ -47,7 +50,7 @@ func TestGetRentalByUserID_Success(t *testing.T) {
 	mockRentalService.On("GetRentalByUserID", uint(1)).Return(mockRentals, nil)
 
 	// Handler
-	handler := handler.NewRentalHandler(mockRentalService, nil, nil)
+	handler := handler.NewRentalHandler(mockRentalService)
 
 	// Eksekusi
 	err := handler.GetRentalByUserID(c)
@@ -64,6 +67,9 @@ func TestGetRentalByUserID_Success(t *testing.T) {
 	assert.Len(t, resp.Data, 1)
 	assert.Equal(t, uint(1), resp.Data[0].RentalID)
 	assert.Equal(t, "Clean Architecture", resp.Data[0].BookTitle)
+	assert.Len(t, resp.Data[0].Events, 1)
+	assert.Equal(t, model.RentalEventExtended, resp.Data[0].Events[0].Type)
+	assert.Equal(t, -20000, resp.Data[0].Events[0].Amount)
 
 	mockRentalService.AssertExpectations(t)
 }
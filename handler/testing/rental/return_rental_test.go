@@ -0,0 +1,119 @@
+package rental
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newReturnContext(rentalID string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/rentals/"+rentalID+"/return", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/rentals/:id/return")
+	c.SetParamNames("id")
+	c.SetParamValues(rentalID)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestReturnRental_Success(t *testing.T) {
+	c, rec := newReturnContext("1")
+
+	dueDate := time.Now().AddDate(0, 0, 3)
+	returnedAt := time.Now()
+	returned := model.Rental{
+		Model:      gorm.Model{ID: 1},
+		UserID:     1,
+		BookID:     2,
+		RentDate:   time.Now().AddDate(0, 0, -4),
+		ReturnDate: &dueDate,
+		ReturnedAt: &returnedAt,
+		LateFee:    4000,
+		Status:     model.RentalStatusReturned,
+	}
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ReturnRental", uint(1), uint(1)).Return(returned, nil)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReturnRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.ReturnRentalResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Success Return Rental", resp.Message)
+	assert.Equal(t, uint(1), resp.Data.RentalID)
+	assert.Equal(t, model.RentalStatusReturned, resp.Data.Status)
+	assert.Equal(t, returnedAt.Format("2006-01-02"), resp.Data.ReturnedAt)
+	assert.Equal(t, 4000, resp.Data.LateFee)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestReturnRental_NotFound(t *testing.T) {
+	c, rec := newReturnContext("9")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ReturnRental", uint(9), uint(1)).Return(model.Rental{}, service.ErrRentalNotFound)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReturnRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestReturnRental_AlreadyReturned(t *testing.T) {
+	c, rec := newReturnContext("1")
+
+	mockRentalService := new(service.RentalServiceMock)
+	mockRentalService.On("ReturnRental", uint(1), uint(1)).Return(model.Rental{}, service.ErrInvalidRentalTransition)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReturnRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Rental cannot be returned", resp.Message)
+
+	mockRentalService.AssertExpectations(t)
+}
+
+func TestReturnRental_InvalidID(t *testing.T) {
+	c, rec := newReturnContext("abc")
+
+	mockRentalService := new(service.RentalServiceMock)
+
+	handler := handler.NewRentalHandler(mockRentalService)
+	err := handler.ReturnRental(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,178 @@
+package reservation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, path, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestCreateReservation_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/reservations", `{"book_id": 3}`)
+
+	mockService := new(service.ReservationServiceMock)
+	mockService.On("CreateReservation", uint(1), uint(3)).Return(service.ReservationView{
+		Reservation: model.Reservation{
+			Model:  gorm.Model{ID: 7, CreatedAt: time.Now()},
+			UserID: 1,
+			BookID: 3,
+			Status: model.ReservationStatusWaiting,
+		},
+		QueuePosition: 2,
+	}, nil)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.CreateReservation(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.ReservationResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), resp.Data.ReservationID)
+	assert.Equal(t, model.ReservationStatusWaiting, resp.Data.Status)
+	assert.Equal(t, int64(2), resp.Data.QueuePosition)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateReservation_BookInStock(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/reservations", `{"book_id": 3}`)
+
+	mockService := new(service.ReservationServiceMock)
+	mockService.On("CreateReservation", uint(1), uint(3)).Return(service.ReservationView{}, service.ErrBookInStock)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.CreateReservation(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	var resp dto.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "Book is in stock, rent it directly", resp.Message)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateReservation_MissingBookID(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/reservations", `{}`)
+
+	mockService := new(service.ReservationServiceMock)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.CreateReservation(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetReservations_Success(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/reservations", "")
+
+	holdExpiresAt := time.Now().Add(48 * time.Hour)
+	mockService := new(service.ReservationServiceMock)
+	mockService.On("GetReservationsByUserID", uint(1)).Return([]service.ReservationView{
+		{Reservation: model.Reservation{
+			Model:         gorm.Model{ID: 1},
+			BookID:        3,
+			Status:        model.ReservationStatusReady,
+			HoldExpiresAt: &holdExpiresAt,
+			Book:          model.Book{Name: "Bumi Manusia"},
+		}},
+		{Reservation: model.Reservation{
+			Model:  gorm.Model{ID: 2},
+			BookID: 4,
+			Status: model.ReservationStatusWaiting,
+		}, QueuePosition: 1},
+	}, nil)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.GetReservations(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.ReservationListResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, "Bumi Manusia", resp.Data[0].BookTitle)
+	assert.NotEmpty(t, resp.Data[0].HoldExpiresAt)
+	assert.Equal(t, int64(1), resp.Data[1].QueuePosition)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCancelReservation_Success(t *testing.T) {
+	c, rec := newContext(http.MethodDelete, "/reservations/5", "")
+	c.SetPath("/reservations/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	mockService := new(service.ReservationServiceMock)
+	mockService.On("CancelReservation", uint(5), uint(1)).Return(model.Reservation{
+		Model:  gorm.Model{ID: 5},
+		BookID: 3,
+		Status: model.ReservationStatusCancelled,
+	}, nil)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.CancelReservation(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.ReservationResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReservationStatusCancelled, resp.Data.Status)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCancelReservation_NotFound(t *testing.T) {
+	c, rec := newContext(http.MethodDelete, "/reservations/5", "")
+	c.SetPath("/reservations/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+
+	mockService := new(service.ReservationServiceMock)
+	mockService.On("CancelReservation", uint(5), uint(1)).Return(model.Reservation{}, service.ErrReservationNotFound)
+
+	handler := handler.NewReservationHandler(mockService)
+	err := handler.CancelReservation(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -10,10 +10,12 @@ import (
 	"pojok-baca-api/model"
 	"pojok-baca-api/service"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetDataByID_Success(t *testing.T) {
@@ -43,8 +45,11 @@ func TestGetDataByID_Success(t *testing.T) {
 
 	mockService.On("GetUserById", uint(1)).Return(mockUser, nil)
 
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("GetActiveMembership", uint(1)).Return(model.Membership{}, service.ErrMembershipNotFound)
+
 	// Panggil handler
-	handler := handler.NewUserHandler(mockService)
+	handler := handler.NewUserHandler(mockService, mockMembershipService)
 	err := handler.GetDataByID(c)
 
 	// Validasi
@@ -58,6 +63,7 @@ func TestGetDataByID_Success(t *testing.T) {
 	assert.Equal(t, "Successfully get your data", resp.Message)
 	assert.Equal(t, "john@mail.com", resp.Data.Email)
 	assert.Equal(t, 10000, resp.Data.Deposit)
+	assert.Nil(t, resp.Data.Membership)
 
 	mockService.AssertExpectations(t)
 }
@@ -78,7 +84,7 @@ func TestGetDataByID_UserNotFound(t *testing.T) {
 	mockService := new(service.UserServiceMock)
 	mockService.On("GetUserById", uint(99)).Return(model.User{}, errors.New("user not found"))
 
-	handler := handler.NewUserHandler(mockService)
+	handler := handler.NewUserHandler(mockService, new(service.MembershipServiceMock))
 	err := handler.GetDataByID(c)
 
 	assert.NoError(t, err)
@@ -91,3 +97,54 @@ func TestGetDataByID_UserNotFound(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestGetDataByID_WithMembership(t *testing.T) {
+	e := echo.New()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"email":   "john@mail.com",
+		"role":    "user",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", token)
+
+	mockService := new(service.UserServiceMock)
+	deposit := 10000
+	mockService.On("GetUserById", uint(1)).Return(model.User{Name: "John Doe", Email: "john@mail.com", Deposit: &deposit}, nil)
+
+	startsAt := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	mockMembershipService := new(service.MembershipServiceMock)
+	mockMembershipService.On("GetActiveMembership", uint(1)).Return(model.Membership{
+		Model:       gorm.Model{ID: 7},
+		UserID:      1,
+		PlanID:      1,
+		Price:       50000,
+		RentalQuota: 3,
+		RentalsUsed: 1,
+		StartsAt:    startsAt,
+		ExpiresAt:   startsAt.AddDate(0, 0, 30),
+		AutoRenew:   true,
+		Status:      model.MembershipStatusActive,
+		Plan:        model.MembershipPlan{Name: "Basic"},
+	}, nil)
+
+	handler := handler.NewUserHandler(mockService, mockMembershipService)
+	err := handler.GetDataByID(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.UserResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotNil(t, resp.Data.Membership)
+	assert.Equal(t, "Basic", resp.Data.Membership.PlanName)
+	assert.Equal(t, "2025-07-31T10:00:00Z", resp.Data.Membership.ExpiresAt)
+	assert.Equal(t, 2, *resp.Data.Membership.RemainingQuota)
+
+	mockService.AssertExpectations(t)
+	mockMembershipService.AssertExpectations(t)
+}
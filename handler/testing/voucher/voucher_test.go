@@ -0,0 +1,153 @@
+package voucher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, target, body string, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestCreateVoucher_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/vouchers",
+		`{"code":"TOPUP10","type":"percentage","scope":"top_up","value":10,"min_amount":100000,"valid_until":"2025-09-01T00:00:00Z","max_uses_per_user":1}`, "admin")
+
+	validUntil := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	voucher := model.Voucher{
+		Code:           "TOPUP10",
+		Type:           model.VoucherTypePercentage,
+		Scope:          model.VoucherScopeTopUp,
+		Value:          10,
+		MinAmount:      100000,
+		ValidUntil:     &validUntil,
+		MaxUsesPerUser: 1,
+	}
+	created := voucher
+	created.ID = 3
+	created.Active = true
+
+	mockVoucherService := new(service.VoucherServiceMock)
+	mockVoucherService.On("CreateVoucher", voucher).Return(created, nil)
+
+	handler := handler.NewVoucherHandler(mockVoucherService)
+	err := handler.CreateVoucher(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.VoucherResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(3), resp.Data.ID)
+	assert.Equal(t, "2025-09-01T00:00:00Z", resp.Data.ValidUntil)
+	assert.Empty(t, resp.Data.ValidFrom)
+	assert.True(t, resp.Data.Active)
+	mockVoucherService.AssertExpectations(t)
+}
+
+func TestCreateVoucher_NotAdmin(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/vouchers", `{"code":"FREE"}`, "user")
+
+	mockVoucherService := new(service.VoucherServiceMock)
+
+	handler := handler.NewVoucherHandler(mockVoucherService)
+	err := handler.CreateVoucher(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockVoucherService.AssertNotCalled(t, "CreateVoucher")
+}
+
+func TestCreateVoucher_CodeTaken(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/vouchers",
+		`{"code":"FIRSTFREE","type":"percentage","scope":"rental","value":100,"categories":["Novel"]}`, "admin")
+
+	mockVoucherService := new(service.VoucherServiceMock)
+	mockVoucherService.On("CreateVoucher", model.Voucher{
+		Code:       "FIRSTFREE",
+		Type:       model.VoucherTypePercentage,
+		Scope:      model.VoucherScopeRental,
+		Value:      100,
+		Categories: "Novel",
+	}).Return(model.Voucher{}, service.ErrVoucherCodeTaken)
+
+	handler := handler.NewVoucherHandler(mockVoucherService)
+	err := handler.CreateVoucher(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockVoucherService.AssertExpectations(t)
+}
+
+func TestListVouchers_Success(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/admin/vouchers?page=1&limit=10", "", "admin")
+
+	vouchers := []model.Voucher{{
+		Model:      gorm.Model{ID: 2},
+		Code:       "FIRSTFREE",
+		Type:       model.VoucherTypePercentage,
+		Scope:      model.VoucherScopeRental,
+		Value:      100,
+		Categories: "Novel,Komik",
+		Active:     true,
+	}}
+
+	mockVoucherService := new(service.VoucherServiceMock)
+	mockVoucherService.On("ListVouchers", 1, 10).
+		Return(service.VoucherPage{Vouchers: vouchers, Total: 1, Page: 1, Limit: 10}, nil)
+
+	handler := handler.NewVoucherHandler(mockVoucherService)
+	err := handler.ListVouchers(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.VoucherListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, []string{"Novel", "Komik"}, resp.Data[0].Categories)
+	assert.Equal(t, dto.PaginationMeta{Page: 1, Limit: 10, Total: 1}, resp.Meta)
+	mockVoucherService.AssertExpectations(t)
+}
+
+func TestDeactivateVoucher_NotFound(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/admin/vouchers/9/deactivate", "", "admin")
+	c.SetParamNames("id")
+	c.SetParamValues("9")
+
+	mockVoucherService := new(service.VoucherServiceMock)
+	mockVoucherService.On("DeactivateVoucher", uint(9)).Return(model.Voucher{}, service.ErrVoucherNotFound)
+
+	handler := handler.NewVoucherHandler(mockVoucherService)
+	err := handler.DeactivateVoucher(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockVoucherService.AssertExpectations(t)
+}
@@ -0,0 +1,213 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newUserContext(target string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(3),
+		"role":    "user",
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestGetTransactions_Success(t *testing.T) {
+	c, rec := newUserContext("/user/wallet/transactions?page=2&limit=2")
+
+	orderID := "ORDER-3-1718000000"
+	rentalID := uint(8)
+	createdAt := time.Date(2025, 7, 3, 8, 0, 0, 0, time.UTC)
+	entries := []repository.WalletEntry{
+		{
+			LedgerEntry: model.LedgerEntry{
+				Model:       gorm.Model{ID: 12, CreatedAt: createdAt},
+				UserID:      3,
+				Account:     model.LedgerAccountWallet,
+				Amount:      -15000,
+				Type:        model.LedgerTypeRentalCharge,
+				RentalID:    &rentalID,
+				Description: "Rental of Atomic Habits",
+			},
+			RunningBalance: 45000,
+		},
+		{
+			LedgerEntry: model.LedgerEntry{
+				Model:          gorm.Model{ID: 10, CreatedAt: createdAt},
+				UserID:         3,
+				Account:        model.LedgerAccountWallet,
+				Amount:         60000,
+				Type:           model.LedgerTypeTopUp,
+				DepositOrderID: &orderID,
+			},
+			RunningBalance: 60000,
+		},
+	}
+
+	mockWalletService := new(service.WalletServiceMock)
+	mockWalletService.On("GetTransactions", uint(3), 2, 2).
+		Return(service.WalletPage{Entries: entries, Balance: 40000, Total: 5, Page: 2, Limit: 2}, nil)
+
+	handler := handler.NewWalletHandler(mockWalletService)
+	err := handler.GetTransactions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.WalletTransactionListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 40000, resp.Balance)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, -15000, resp.Data[0].Amount)
+	assert.Equal(t, 45000, resp.Data[0].RunningBalance)
+	assert.Equal(t, &rentalID, resp.Data[0].RentalID)
+	assert.Nil(t, resp.Data[0].DepositOrderID)
+	assert.Equal(t, &orderID, resp.Data[1].DepositOrderID)
+	assert.Equal(t, "2025-07-03T08:00:00Z", resp.Data[1].CreatedAt)
+	assert.Equal(t, dto.PaginationMeta{Page: 2, Limit: 2, Total: 5}, resp.Meta)
+
+	mockWalletService.AssertExpectations(t)
+}
+
+func TestGetTransactions_InvalidPage(t *testing.T) {
+	c, rec := newUserContext("/user/wallet/transactions?page=first")
+
+	mockWalletService := new(service.WalletServiceMock)
+
+	handler := handler.NewWalletHandler(mockWalletService)
+	err := handler.GetTransactions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockWalletService.AssertNotCalled(t, "GetTransactions")
+}
+
+func TestGetTransactions_Failed(t *testing.T) {
+	c, rec := newUserContext("/user/wallet/transactions")
+
+	mockWalletService := new(service.WalletServiceMock)
+	mockWalletService.On("GetTransactions", uint(3), 0, 0).Return(service.WalletPage{}, errors.New("db down"))
+
+	handler := handler.NewWalletHandler(mockWalletService)
+	err := handler.GetTransactions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	mockWalletService.AssertExpectations(t)
+}
+
+func newAdminContext(body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/3/deposit/adjustments", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"role":    "admin",
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func TestAdjustBalance_Success(t *testing.T) {
+	c, rec := newAdminContext(`{"amount":-15000,"reason_code":"correction","note":"Late fee charged twice"}`)
+
+	adjustment := model.BalanceAdjustment{
+		UserID:     3,
+		AdminID:    1,
+		Amount:     -15000,
+		ReasonCode: model.AdjustmentReasonCorrection,
+		Note:       "Late fee charged twice",
+	}
+	created := adjustment
+	created.ID = 5
+	created.BalanceAfter = 30000
+
+	mockWalletService := new(service.WalletServiceMock)
+	mockWalletService.On("AdjustBalance", adjustment).Return(created, nil)
+
+	handler := handler.NewWalletHandler(mockWalletService)
+	err := handler.AdjustBalance(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.BalanceAdjustmentResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(5), resp.Data.AdjustmentID)
+	assert.Equal(t, uint(1), resp.Data.AdminID)
+	assert.Equal(t, 30000, resp.Data.BalanceAfter)
+	mockWalletService.AssertExpectations(t)
+}
+
+func TestAdjustBalance_NotAdmin(t *testing.T) {
+	c, rec := newAdminContext(`{"amount":5000,"reason_code":"goodwill","note":"Sorry"}`)
+	c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": float64(3), "role": "user"}))
+
+	mockWalletService := new(service.WalletServiceMock)
+
+	handler := handler.NewWalletHandler(mockWalletService)
+	err := handler.AdjustBalance(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockWalletService.AssertNotCalled(t, "AdjustBalance")
+}
+
+func TestAdjustBalance_ServiceErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidAdjustmentAmount, http.StatusBadRequest},
+		{service.ErrInvalidReasonCode, http.StatusBadRequest},
+		{service.ErrNoteRequired, http.StatusBadRequest},
+		{service.ErrUserNotFound, http.StatusNotFound},
+		{service.ErrNegativeBalance, http.StatusConflict},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		c, rec := newAdminContext(`{"amount":-90000,"reason_code":"chargeback","note":"Bank reversed the top up"}`)
+
+		mockWalletService := new(service.WalletServiceMock)
+		mockWalletService.On("AdjustBalance", mock.Anything).Return(model.BalanceAdjustment{}, tc.err)
+
+		handler := handler.NewWalletHandler(mockWalletService)
+		err := handler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, rec.Code, tc.err.Error())
+	}
+}
@@ -0,0 +1,195 @@
+package withdrawal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newContext(method, target, body string, userID float64, role string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Simulasi JWT claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+	})
+	c.Set("user", token)
+
+	return c, rec
+}
+
+func withID(c echo.Context, id string) {
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+}
+
+func TestRequestWithdrawal_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/user/withdrawals", `{"amount": 40000, "note": "Moving out"}`, 3, "user")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("RequestWithdrawal", uint(3), 40000, "Moving out").Return(model.Withdrawal{
+		Model:  gorm.Model{ID: 4},
+		UserID: 3,
+		Amount: 40000,
+		Status: model.WithdrawalStatusPending,
+		Note:   "Moving out",
+		Events: []model.WithdrawalEvent{{ToStatus: model.WithdrawalStatusPending, ActorID: 3}},
+	}, nil)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.RequestWithdrawal(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.WithdrawalResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(4), resp.Data.WithdrawalID)
+	assert.Equal(t, "pending", resp.Data.Status)
+	assert.Len(t, resp.Data.Events, 1)
+	mockWithdrawalService.AssertExpectations(t)
+}
+
+func TestRequestWithdrawal_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid amount", service.ErrInvalidWithdrawalAmount, http.StatusBadRequest},
+		{"insufficient deposit", service.ErrInsufficientDeposit, http.StatusBadRequest},
+		{"already open", service.ErrWithdrawalInProgress, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := newContext(http.MethodPost, "/user/withdrawals", `{"amount": 40000}`, 3, "user")
+
+			mockWithdrawalService := new(service.WithdrawalServiceMock)
+			mockWithdrawalService.On("RequestWithdrawal", uint(3), 40000, "").Return(model.Withdrawal{}, tc.err)
+
+			handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+			err := handler.RequestWithdrawal(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestGetWithdrawal_NotFound(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/", "", 3, "user")
+	withID(c, "9")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("GetWithdrawal", uint(3), uint(9)).Return(model.Withdrawal{}, service.ErrWithdrawalNotFound)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.GetWithdrawal(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestListWithdrawals_Success(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/admin/withdrawals?status=pending&limit=10", "", 7, "admin")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("ListWithdrawals", "pending", 0, 10).Return(service.WithdrawalPage{
+		Withdrawals: []model.Withdrawal{{
+			Model:  gorm.Model{ID: 4},
+			UserID: 3,
+			Amount: 40000,
+			Status: model.WithdrawalStatusPending,
+			User:   model.User{Email: "reader@mail.com"},
+		}},
+		Total: 1, Page: 1, Limit: 10,
+	}, nil)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.ListWithdrawals(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.WithdrawalListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, "reader@mail.com", resp.Data[0].UserEmail)
+	assert.Equal(t, dto.PaginationMeta{Page: 1, Limit: 10, Total: 1}, resp.Meta)
+}
+
+func TestListWithdrawals_NotAdmin(t *testing.T) {
+	c, rec := newContext(http.MethodGet, "/admin/withdrawals", "", 3, "user")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.ListWithdrawals(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockWithdrawalService.AssertNotCalled(t, "ListWithdrawals")
+}
+
+func TestApproveWithdrawal_Success(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/", `{"reason": "Bank details verified"}`, 7, "admin")
+	withID(c, "4")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("ApproveWithdrawal", uint(4), uint(7), "Bank details verified").Return(model.Withdrawal{
+		Model:  gorm.Model{ID: 4},
+		Status: model.WithdrawalStatusApproved,
+	}, nil)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.ApproveWithdrawal(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockWithdrawalService.AssertExpectations(t)
+}
+
+func TestRejectWithdrawal_AlreadyPaid(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/", `{"reason": "Duplicate"}`, 7, "admin")
+	withID(c, "4")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("RejectWithdrawal", uint(4), uint(7), "Duplicate").Return(model.Withdrawal{}, service.ErrInvalidWithdrawalTransition)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.RejectWithdrawal(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestMarkWithdrawalPaid_MissingReference(t *testing.T) {
+	c, rec := newContext(http.MethodPost, "/", `{}`, 7, "admin")
+	withID(c, "4")
+
+	mockWithdrawalService := new(service.WithdrawalServiceMock)
+	mockWithdrawalService.On("MarkWithdrawalPaid", uint(4), uint(7), "").Return(model.Withdrawal{}, service.ErrPayoutRefRequired)
+
+	handler := handler.NewWithdrawalHandler(mockWithdrawalService)
+	err := handler.MarkWithdrawalPaid(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
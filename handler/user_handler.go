@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"pojok-baca-api/dto"
@@ -14,11 +15,12 @@ import (
 )
 
 type UserHandler struct {
-	Service service.UserService
+	Service     service.UserService
+	Memberships service.MembershipService
 }
 
-func NewUserHandler(service service.UserService) *UserHandler {
-	return &UserHandler{Service: service}
+func NewUserHandler(service service.UserService, memberships service.MembershipService) *UserHandler {
+	return &UserHandler{Service: service, Memberships: memberships}
 }
 
 // CreateUser godoc
@@ -160,7 +162,7 @@ func (h *UserHandler) Login(c echo.Context) error {
 
 // GetDataByID godoc
 // @Summary Get current logged-in user data
-// @Description Retrieve user profile based on JWT token
+// @Description Retrieve user profile based on JWT token, with the active membership and its remaining rental quota
 // @Tags Users
 // @Security BearerAuth
 // @Accept json
@@ -189,14 +191,29 @@ func (h *UserHandler) GetDataByID(c echo.Context) error {
 		deposit = *user.Deposit
 	}
 
+	var membershipData *dto.MembershipDataResponse
+	membership, err := h.Memberships.GetActiveMembership(userID)
+	if err != nil && !errors.Is(err, service.ErrMembershipNotFound) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Error",
+			Code:    http.StatusBadRequest,
+			Message: "Failed to get your data",
+		})
+	}
+	if err == nil {
+		data := toMembershipData(membership)
+		membershipData = &data
+	}
+
 	return c.JSON(http.StatusOK, dto.UserResponse{
 		Status:  "success",
 		Code:    http.StatusOK,
 		Message: "Successfully get your data",
 		Data: dto.UserDataResponse{
-			Name:    user.Name,
-			Email:   user.Email,
-			Deposit: deposit,
+			Name:       user.Name,
+			Email:      user.Email,
+			Deposit:    deposit,
+			Membership: membershipData,
 		},
 	})
 }
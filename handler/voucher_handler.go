@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// isVoucherError reports whether err means the voucher given with a checkout or a
+// top-up cannot be redeemed.
+func isVoucherError(err error) bool {
+	for _, target := range []error{
+		service.ErrVoucherNotFound, service.ErrVoucherNotActive, service.ErrVoucherNotApplicable,
+		service.ErrVoucherMinAmount, service.ErrVoucherUsedUp, service.ErrVoucherLimitReached,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type VoucherHandler struct {
+	Service service.VoucherService
+}
+
+func NewVoucherHandler(s service.VoucherService) *VoucherHandler {
+	return &VoucherHandler{Service: s}
+}
+
+func toVoucherData(voucher model.Voucher) dto.VoucherDataResponse {
+	data := dto.VoucherDataResponse{
+		ID:             voucher.ID,
+		Code:           voucher.Code,
+		Type:           voucher.Type,
+		Scope:          voucher.Scope,
+		Value:          voucher.Value,
+		MaxDiscount:    voucher.MaxDiscount,
+		MinAmount:      voucher.MinAmount,
+		Categories:     voucher.CategoryList(),
+		MaxUses:        voucher.MaxUses,
+		MaxUsesPerUser: voucher.MaxUsesPerUser,
+		Active:         voucher.Active,
+	}
+	if voucher.ValidFrom != nil {
+		data.ValidFrom = voucher.ValidFrom.Format(time.RFC3339)
+	}
+	if voucher.ValidUntil != nil {
+		data.ValidUntil = voucher.ValidUntil.Format(time.RFC3339)
+	}
+	return data
+}
+
+// CreateVoucher godoc
+// @Summary Create a voucher
+// @Description Admin only. A percentage voucher takes value percent off a rental checkout or adds it as bonus credit to a top-up, a fixed voucher takes value off a checkout and a bonus_credit voucher adds value to a top-up. Limits of zero mean no limit, no categories means every category.
+// @Tags Admin Vouchers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateVoucherRequest true "Voucher"
+// @Success 201 {object} dto.VoucherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/vouchers [post]
+func (h *VoucherHandler) CreateVoucher(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	var req dto.CreateVoucherRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	voucher, err := h.Service.CreateVoucher(model.Voucher{
+		Code:           req.Code,
+		Type:           req.Type,
+		Scope:          req.Scope,
+		Value:          req.Value,
+		MaxDiscount:    req.MaxDiscount,
+		MinAmount:      req.MinAmount,
+		Categories:     strings.Join(req.Categories, ","),
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidVoucher):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid voucher",
+			Details: err.Error(),
+		})
+	case errors.Is(err, service.ErrVoucherCodeTaken):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Voucher code already exists",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Create Voucher is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.VoucherResponse{
+		Status:  "success",
+		Code:    http.StatusCreated,
+		Message: "Success Create Voucher",
+		Data:    toVoucherData(voucher),
+	})
+}
+
+// ListVouchers godoc
+// @Summary List vouchers
+// @Description Admin only. Every voucher, newest first
+// @Tags Admin Vouchers
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.VoucherListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/vouchers [get]
+func (h *VoucherHandler) ListVouchers(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	vouchers, err := h.Service.ListVouchers(page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Vouchers is Failed",
+		})
+	}
+
+	data := make([]dto.VoucherDataResponse, 0, len(vouchers.Vouchers))
+	for _, voucher := range vouchers.Vouchers {
+		data = append(data, toVoucherData(voucher))
+	}
+
+	return c.JSON(http.StatusOK, dto.VoucherListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Vouchers",
+		Data:    data,
+		Meta:    dto.PaginationMeta{Page: vouchers.Page, Limit: vouchers.Limit, Total: vouchers.Total},
+	})
+}
+
+// DeactivateVoucher godoc
+// @Summary Deactivate a voucher
+// @Description Admin only. The code can no longer be redeemed. Past redemptions, including top-up bonuses still waiting for payment, are kept.
+// @Tags Admin Vouchers
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Voucher ID"
+// @Success 200 {object} dto.VoucherResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/vouchers/{id}/deactivate [post]
+func (h *VoucherHandler) DeactivateVoucher(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	voucher, err := h.Service.DeactivateVoucher(uint(id))
+	if errors.Is(err, service.ErrVoucherNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Voucher not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Deactivate Voucher is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.VoucherResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Deactivate Voucher",
+		Data:    toVoucherData(voucher),
+	})
+}
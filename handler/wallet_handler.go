@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type WalletHandler struct {
+	Service service.WalletService
+}
+
+func NewWalletHandler(s service.WalletService) *WalletHandler {
+	return &WalletHandler{Service: s}
+}
+
+// GetTransactions godoc
+// @Summary List wallet transactions
+// @Description Ledger entries of the logged-in user's wallet, newest first, each with the balance right after it
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.WalletTransactionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/wallet/transactions [get]
+func (h *WalletHandler) GetTransactions(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	wallet, err := h.Service.GetTransactions(userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Wallet Transactions is Failed",
+		})
+	}
+
+	data := make([]dto.WalletTransactionResponse, 0, len(wallet.Entries))
+	for _, entry := range wallet.Entries {
+		data = append(data, dto.WalletTransactionResponse{
+			ID:             entry.ID,
+			Type:           entry.Type,
+			Amount:         entry.Amount,
+			RunningBalance: entry.RunningBalance,
+			DepositOrderID: entry.DepositOrderID,
+			RentalID:       entry.RentalID,
+			Description:    entry.Description,
+			CreatedAt:      entry.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.WalletTransactionListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Wallet Transactions",
+		Balance: wallet.Balance,
+		Data:    data,
+		Meta:    dto.PaginationMeta{Page: wallet.Page, Limit: wallet.Limit, Total: wallet.Total},
+	})
+}
+
+// AdjustBalance godoc
+// @Summary Adjust a user's deposit
+// @Description Admin only. Credits (positive amount) or debits a user's deposit by hand with a reason code and a note, recorded with the acting admin. A debit that would make the balance negative is refused unless allow_negative is set.
+// @Tags Admin Wallets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.BalanceAdjustmentRequest true "Adjustment"
+// @Success 201 {object} dto.BalanceAdjustmentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/deposit/adjustments [post]
+func (h *WalletHandler) AdjustBalance(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	adminID := uint(claims["user_id"].(float64))
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	var req dto.BalanceAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	adjustment, err := h.Service.AdjustBalance(model.BalanceAdjustment{
+		UserID:        uint(id),
+		AdminID:       adminID,
+		Amount:        req.Amount,
+		ReasonCode:    req.ReasonCode,
+		Note:          req.Note,
+		AllowNegative: req.AllowNegative,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidAdjustmentAmount):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "amount must not be zero",
+		})
+	case errors.Is(err, service.ErrInvalidReasonCode):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Unknown reason_code",
+			Details: "reason_code must be one of correction, goodwill, chargeback, migration, other",
+		})
+	case errors.Is(err, service.ErrNoteRequired):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "note is required",
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+	case errors.Is(err, service.ErrNegativeBalance):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Adjustment would make the balance negative",
+			Details: "set allow_negative to apply it anyway",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Adjust Balance is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.BalanceAdjustmentResponse{
+		Status:  "success",
+		Code:    http.StatusCreated,
+		Message: "Success Adjust Balance",
+		Data: dto.BalanceAdjustmentDataResponse{
+			AdjustmentID:  adjustment.ID,
+			UserID:        adjustment.UserID,
+			AdminID:       adjustment.AdminID,
+			Amount:        adjustment.Amount,
+			ReasonCode:    adjustment.ReasonCode,
+			Note:          adjustment.Note,
+			AllowNegative: adjustment.AllowNegative,
+			BalanceAfter:  adjustment.BalanceAfter,
+			CreatedAt:     adjustment.CreatedAt.Format(time.RFC3339),
+		},
+	})
+}
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/service"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type WithdrawalHandler struct {
+	Service service.WithdrawalService
+}
+
+func NewWithdrawalHandler(s service.WithdrawalService) *WithdrawalHandler {
+	return &WithdrawalHandler{Service: s}
+}
+
+func toWithdrawalData(withdrawal model.Withdrawal) dto.WithdrawalDataResponse {
+	events := make([]dto.WithdrawalEventResponse, 0, len(withdrawal.Events))
+	for _, event := range withdrawal.Events {
+		events = append(events, dto.WithdrawalEventResponse{
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			Reason:     event.Reason,
+			ActorID:    event.ActorID,
+			CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return dto.WithdrawalDataResponse{
+		WithdrawalID: withdrawal.ID,
+		UserID:       withdrawal.UserID,
+		UserEmail:    withdrawal.User.Email,
+		Amount:       withdrawal.Amount,
+		Status:       withdrawal.Status,
+		Note:         withdrawal.Note,
+		PayoutRef:    withdrawal.PayoutRef,
+		CreatedAt:    withdrawal.CreatedAt.Format(time.RFC3339),
+		Events:       events,
+	}
+}
+
+// withdrawalResult writes the response of a call that returns a single withdrawal.
+func withdrawalResult(c echo.Context, withdrawal model.Withdrawal, err error, name string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidWithdrawalAmount):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "amount must be greater than zero",
+		})
+	case errors.Is(err, service.ErrInsufficientDeposit):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient deposit",
+		})
+	case errors.Is(err, service.ErrReasonRequired):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "reason is required",
+		})
+	case errors.Is(err, service.ErrPayoutRefRequired):
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "payout_ref is required",
+		})
+	case errors.Is(err, service.ErrWithdrawalNotFound):
+		return c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "Withdrawal not found",
+		})
+	case errors.Is(err, service.ErrWithdrawalInProgress):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "You already have an open withdrawal",
+		})
+	case errors.Is(err, service.ErrInvalidWithdrawalTransition):
+		return c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Status:  "Conflict",
+			Code:    http.StatusConflict,
+			Message: "Withdrawal cannot move to this status",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: name + " is Failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.WithdrawalResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success " + name,
+		Data:    toWithdrawalData(withdrawal),
+	})
+}
+
+// withdrawalListResult writes the response of a call that returns a page of withdrawals.
+func withdrawalListResult(c echo.Context, page service.WithdrawalPage, err error) error {
+	if errors.Is(err, service.ErrInvalidWithdrawalStatus) {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Get Withdrawals is Failed",
+		})
+	}
+
+	data := make([]dto.WithdrawalDataResponse, 0, len(page.Withdrawals))
+	for _, withdrawal := range page.Withdrawals {
+		data = append(data, toWithdrawalData(withdrawal))
+	}
+
+	return c.JSON(http.StatusOK, dto.WithdrawalListResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
+		Message: "Success Get Withdrawals",
+		Data:    data,
+		Meta:    dto.PaginationMeta{Page: page.Page, Limit: page.Limit, Total: page.Total},
+	})
+}
+
+// RequestWithdrawal godoc
+// @Summary Request a withdrawal
+// @Description Ask for part or all of the deposit back. Nothing leaves the balance until an admin approves it. Only one withdrawal can be open at a time.
+// @Tags Withdrawals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.WithdrawalRequest true "Withdrawal request payload"
+// @Success 200 {object} dto.WithdrawalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/withdrawals [post]
+func (h *WithdrawalHandler) RequestWithdrawal(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	var req dto.WithdrawalRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	withdrawal, err := h.Service.RequestWithdrawal(userID, req.Amount, req.Note)
+	return withdrawalResult(c, withdrawal, err, "Request Withdrawal")
+}
+
+// GetWithdrawals godoc
+// @Summary List my withdrawals
+// @Description Withdrawals of the logged-in user, newest first
+// @Tags Withdrawals
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.WithdrawalListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/withdrawals [get]
+func (h *WithdrawalHandler) GetWithdrawals(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	withdrawals, err := h.Service.GetWithdrawals(userID, page, limit)
+	return withdrawalListResult(c, withdrawals, err)
+}
+
+// GetWithdrawal godoc
+// @Summary Get one of my withdrawals
+// @Description A withdrawal of the logged-in user with its full status history
+// @Tags Withdrawals
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Success 200 {object} dto.WithdrawalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /user/withdrawals/{id} [get]
+func (h *WithdrawalHandler) GetWithdrawal(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	userID := uint(claims["user_id"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	withdrawal, err := h.Service.GetWithdrawal(userID, uint(id))
+	return withdrawalResult(c, withdrawal, err, "Get Withdrawal")
+}
+
+// ListWithdrawals godoc
+// @Summary List all withdrawals
+// @Description Admin only. Withdrawals of every user, newest first, optionally in one status
+// @Tags Admin Withdrawals
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Only withdrawals in this status" Enums(pending, approved, paid, rejected)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} dto.WithdrawalListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/withdrawals [get]
+func (h *WithdrawalHandler) ListWithdrawals(c echo.Context) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameter",
+			Details: err.Error(),
+		})
+	}
+
+	withdrawals, err := h.Service.ListWithdrawals(c.QueryParam("status"), page, limit)
+	return withdrawalListResult(c, withdrawals, err)
+}
+
+// adminWithdrawalAction runs one of the admin withdrawal actions for the withdrawal in
+// the path, with the text read from the request body and the caller as the acting admin.
+func (h *WithdrawalHandler) adminWithdrawalAction(c echo.Context, text func() (string, error),
+	action func(id uint, adminID uint, text string) (model.Withdrawal, error), name string) error {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+	adminID := uint(claims["user_id"].(float64))
+	role := claims["role"].(string)
+
+	if role != "admin" {
+		return c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Status:  "Unauthorized",
+			Code:    http.StatusUnauthorized,
+			Message: "You are not allowed to access this resource",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+	}
+
+	value, err := text()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		})
+	}
+
+	withdrawal, err := action(uint(id), adminID, value)
+	return withdrawalResult(c, withdrawal, err, name)
+}
+
+// reasonFromBody reads the optional reason of an admin withdrawal action.
+func reasonFromBody(c echo.Context) func() (string, error) {
+	return func() (string, error) {
+		var req dto.WithdrawalActionRequest
+		err := c.Bind(&req)
+		return req.Reason, err
+	}
+}
+
+// ApproveWithdrawal godoc
+// @Summary Approve a withdrawal
+// @Description Admin only. Reserves the amount: it leaves the user's balance and is held until the payout is recorded. Fails when the balance no longer covers it.
+// @Tags Admin Withdrawals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Param request body dto.WithdrawalActionRequest false "Optional note"
+// @Success 200 {object} dto.WithdrawalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/withdrawals/{id}/approve [post]
+func (h *WithdrawalHandler) ApproveWithdrawal(c echo.Context) error {
+	return h.adminWithdrawalAction(c, reasonFromBody(c), h.Service.ApproveWithdrawal, "Approve Withdrawal")
+}
+
+// RejectWithdrawal godoc
+// @Summary Reject a withdrawal
+// @Description Admin only. Turns down a pending or approved withdrawal. A held amount goes back to the user's balance. The reason is recorded.
+// @Tags Admin Withdrawals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Param request body dto.WithdrawalActionRequest true "Reason"
+// @Success 200 {object} dto.WithdrawalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/withdrawals/{id}/reject [post]
+func (h *WithdrawalHandler) RejectWithdrawal(c echo.Context) error {
+	return h.adminWithdrawalAction(c, reasonFromBody(c), h.Service.RejectWithdrawal, "Reject Withdrawal")
+}
+
+// MarkWithdrawalPaid godoc
+// @Summary Record a withdrawal payout
+// @Description Admin only. Records that an approved withdrawal was paid out by hand, with the transfer reference.
+// @Tags Admin Withdrawals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Param request body dto.WithdrawalPaidRequest true "Payout reference"
+// @Success 200 {object} dto.WithdrawalResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/withdrawals/{id}/paid [post]
+func (h *WithdrawalHandler) MarkWithdrawalPaid(c echo.Context) error {
+	payoutRef := func() (string, error) {
+		var req dto.WithdrawalPaidRequest
+		err := c.Bind(&req)
+		return req.PayoutRef, err
+	}
+	return h.adminWithdrawalAction(c, payoutRef, h.Service.MarkWithdrawalPaid, "Mark Withdrawal Paid")
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"pojok-baca-api/config"
 	_ "pojok-baca-api/docs"
+	"pojok-baca-api/gateway"
 	"pojok-baca-api/handler"
 	"pojok-baca-api/middleware"
+	"pojok-baca-api/migration"
 	"pojok-baca-api/repository"
+	"pojok-baca-api/scheduler"
 	"pojok-baca-api/service"
 
 	"github.com/labstack/echo/v4"
@@ -35,38 +39,142 @@ func main() {
 
 	config.LoadEnv()
 	db := config.DBInit()
+	rentalConfig := config.LoadRentalConfig()
+	walletConfig := config.LoadWalletConfig()
+	paymentConfig := config.LoadPaymentConfig()
+	membershipConfig := config.LoadMembershipConfig()
 
-	//if err := db.AutoMigrate(&model.User{}, &model.Book{}, &model.Rental{}, &model.DepositTransaction{}); err != nil {
-	//	panic("Auto migrate fail : " + err.Error())
-	//}
+	if err := migration.Run(db); err != nil {
+		panic("Auto migrate fail : " + err.Error())
+	}
 
 	log.Println("Auto migrate success")
 
+	uow := repository.NewUnitOfWork(db)
+
+	//Membership
+	membershipRepo := repository.NewMembershipRepository(db)
+	membershipService := service.NewMembershipService(membershipRepo, uow)
+	membershipHandler := handler.NewMembershipHandler(membershipService)
+
+	go scheduler.Every(context.Background(), membershipConfig.RenewInterval, "renew memberships", func() error {
+		renewed, expired, err := membershipService.RenewMemberships()
+		if renewed > 0 || expired > 0 {
+			log.Printf("Renewed %d memberships, expired %d", renewed, expired)
+		}
+		return err
+	})
+
 	//USER
 	userRepo := repository.NewUserRepository(db)
 	userService := service.NewUserService(userRepo)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, membershipService)
+
+	//Category
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := service.NewCategoryService(categoryRepo, uow)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	//BOOK
 	bookRepo := repository.NewBookRepository(db)
-	bookService := service.NewBookService(bookRepo)
+	bookService := service.NewBookService(bookRepo, categoryRepo)
 	bookHandler := handler.NewProductHandler(bookService)
+	bookSearchService := service.NewBookSearchService(repository.NewBookSearchRepository(db))
+	bookSearchHandler := handler.NewBookSearchHandler(bookSearchService)
 
 	//Rental
 	rentalRepo := repository.NewRentalRepository(db)
-	rentalService := service.NewRentalService(rentalRepo)
-	rentalHandler := handler.NewRentalHandler(rentalService, bookService, userService)
+	rentalService := service.NewRentalService(rentalRepo, uow, rentalConfig)
+	rentalHandler := handler.NewRentalHandler(rentalService)
+
+	go scheduler.Every(context.Background(), rentalConfig.OverdueCheckInterval, "overdue rentals", func() error {
+		count, err := rentalService.MarkOverdueRentals()
+		if count > 0 {
+			log.Printf("Marked %d rentals as overdue", count)
+		}
+		return err
+	})
+
+	//Book copy
+	bookCopyRepo := repository.NewBookCopyRepository(db)
+	bookCopyService := service.NewBookCopyService(bookCopyRepo, rentalRepo, uow, rentalConfig)
+	bookCopyHandler := handler.NewBookCopyHandler(bookCopyService)
+	bookTransferService := service.NewBookTransferService(bookRepo, uow, rentalConfig)
+	bookTransferHandler := handler.NewBookTransferHandler(bookTransferService)
+
+	//Pricing plan
+	pricingPlanRepo := repository.NewPricingPlanRepository(db)
+	pricingPlanService := service.NewPricingPlanService(pricingPlanRepo, bookRepo, categoryRepo)
+	pricingPlanHandler := handler.NewPricingPlanHandler(pricingPlanService)
+
+	//Reservation
+	reservationRepo := repository.NewReservationRepository(db)
+	reservationService := service.NewReservationService(reservationRepo, uow, rentalConfig)
+	reservationHandler := handler.NewReservationHandler(reservationService)
+
+	go scheduler.Every(context.Background(), rentalConfig.HoldCheckInterval, "expire reservation holds", func() error {
+		count, err := reservationService.ExpireHolds()
+		if count > 0 {
+			log.Printf("Expired %d reservation holds", count)
+		}
+		return err
+	})
+
+	//Wallet
+	ledgerRepo := repository.NewLedgerRepository(db)
+	walletService := service.NewWalletService(ledgerRepo, uow)
+	walletHandler := handler.NewWalletHandler(walletService)
+
+	go scheduler.Every(context.Background(), walletConfig.ReconcileInterval, "reconcile wallets", func() error {
+		count, err := walletService.ReconcileBalances()
+		if count > 0 {
+			log.Printf("Corrected %d deposits from the wallet ledger", count)
+		}
+		return err
+	})
+
+	//Withdrawal
+	withdrawalRepo := repository.NewWithdrawalRepository(db)
+	withdrawalService := service.NewWithdrawalService(withdrawalRepo, uow)
+	withdrawalHandler := handler.NewWithdrawalHandler(withdrawalService)
+
+	//Voucher
+	voucherRepo := repository.NewVoucherRepository(db)
+	voucherService := service.NewVoucherService(voucherRepo)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
 
 	//Transaction
 	tranRepo := repository.NewDepositTransactionRepository(db)
-	tranService := service.NewDepositService(tranRepo, userRepo)
+	var paymentGateway gateway.PaymentGateway
+	var fakeGateway *gateway.FakeGateway
+	if paymentConfig.Gateway == "fake" {
+		fakeGateway = gateway.NewFakeGateway(paymentConfig.FakeBaseURL, paymentConfig.FakeWebhookURL, paymentConfig.ServerKey)
+		paymentGateway = fakeGateway
+		log.Println("Using the fake payment gateway, no real payments are made")
+	} else {
+		paymentGateway = gateway.NewMidtransGateway(paymentConfig.ServerKey, paymentConfig.Production, paymentConfig.MidtransAPIURL)
+	}
+	tranService := service.NewDepositService(tranRepo, uow, paymentGateway, paymentConfig.ServerKey)
 	tranHandler := handler.NewDepositTransactionHandler(tranService)
 
+	reconciliationService := service.NewPaymentReconciliationService(tranRepo, repository.NewReconciliationReportRepository(db), uow, paymentGateway, paymentConfig)
+	go scheduler.Every(context.Background(), paymentConfig.ReconcileInterval, "reconcile payments", func() error {
+		report, err := reconciliationService.ReconcilePending()
+		if report.Checked > 0 {
+			log.Printf("Reconciled %d pending deposits: %d updated, %d expired, %d unchanged, %d failed",
+				report.Checked, report.Updated, report.Expired, report.Unchanged, report.Failed)
+		}
+		return err
+	})
+
 	e := echo.New()
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	e.POST("/webhook/deposit", tranHandler.Webhook)
+	if fakeGateway != nil {
+		e.GET("/fake-pay/:order_id", echo.WrapHandler(fakeGateway))
+	}
 	//group api
 	api := e.Group("/api")
 
@@ -74,28 +182,88 @@ func main() {
 	user := api.Group("/user")
 	productGroup := api.Group("/products")
 	rentalGroup := api.Group("/rentals")
+	reservationGroup := api.Group("/reservations")
+	pricingPlanGroup := api.Group("/pricing-plans")
+	membershipPlanGroup := api.Group("/membership-plans")
+	adminGroup := api.Group("/admin")
+	copyGroup := api.Group("/copies")
 
 	//User register & login
 	user.POST("/register", userHandler.CreateUser)
 	user.POST("/login", userHandler.Login)
 
 	productGroup.GET("", bookHandler.GetBooks)
+	productGroup.GET("/search", bookSearchHandler.SearchBooks)
 	productGroup.GET("/:id", bookHandler.GetBookByID)
+	productGroup.GET("/:id/plans", pricingPlanHandler.GetBookPlans)
+	membershipPlanGroup.GET("", membershipHandler.ListPlans)
+	api.GET("/categories", categoryHandler.ListCategories)
 
 	jwtSecret := os.Getenv("JWT_SECRET")
 
 	user.Use(middleware.JWTMiddleware(jwtSecret))
 	user.GET("/me", userHandler.GetDataByID)
 	user.POST("/deposit", tranHandler.Create)
+	user.GET("/deposits", tranHandler.ListTransactions)
+	user.GET("/deposits/:order_id", tranHandler.GetTransaction)
+	user.POST("/withdrawals", withdrawalHandler.RequestWithdrawal)
+	user.GET("/withdrawals", withdrawalHandler.GetWithdrawals)
+	user.GET("/withdrawals/:id", withdrawalHandler.GetWithdrawal)
+	user.GET("/wallet/transactions", walletHandler.GetTransactions)
+	user.POST("/membership", membershipHandler.Subscribe)
+	user.GET("/membership", membershipHandler.GetMembership)
+	user.PUT("/membership/auto-renew", membershipHandler.SetAutoRenew)
 
 	productGroup.Use(middleware.JWTMiddleware(jwtSecret))
 	productGroup.POST("", bookHandler.CreateBook)
 	productGroup.PUT("/:id", bookHandler.UpdateBookByID)
 	productGroup.DELETE("/:id", bookHandler.DeleteBookByID)
 
+	pricingPlanGroup.Use(middleware.JWTMiddleware(jwtSecret))
+	pricingPlanGroup.POST("", pricingPlanHandler.CreatePlan)
+	pricingPlanGroup.DELETE("/:id", pricingPlanHandler.DeletePlan)
+
+	membershipPlanGroup.Use(middleware.JWTMiddleware(jwtSecret))
+	membershipPlanGroup.POST("", membershipHandler.CreatePlan)
+
 	rentalGroup.Use(middleware.JWTMiddleware(jwtSecret))
 	rentalGroup.POST("", rentalHandler.CreateRental)
+	rentalGroup.POST("/checkout", rentalHandler.Checkout)
 	rentalGroup.GET("/report", rentalHandler.GetRentalByUserID)
+	rentalGroup.POST("/:id/return", rentalHandler.ReturnRental)
+	rentalGroup.POST("/:id/extend", rentalHandler.ExtendRental)
+
+	adminGroup.Use(middleware.JWTMiddleware(jwtSecret))
+	adminGroup.GET("/rentals", rentalHandler.ListRentals)
+	adminGroup.POST("/rentals/:id/close", rentalHandler.ForceCloseRental)
+	adminGroup.POST("/rentals/:id/lost", rentalHandler.MarkRentalLost)
+	adminGroup.POST("/rentals/:id/cancel", rentalHandler.CancelRental)
+	adminGroup.POST("/rentals/:id/damage", rentalHandler.ReportDamage)
+	adminGroup.GET("/withdrawals", withdrawalHandler.ListWithdrawals)
+	adminGroup.POST("/withdrawals/:id/approve", withdrawalHandler.ApproveWithdrawal)
+	adminGroup.POST("/withdrawals/:id/reject", withdrawalHandler.RejectWithdrawal)
+	adminGroup.POST("/withdrawals/:id/paid", withdrawalHandler.MarkWithdrawalPaid)
+	adminGroup.POST("/users/:id/deposit/adjustments", walletHandler.AdjustBalance)
+	adminGroup.GET("/vouchers", voucherHandler.ListVouchers)
+	adminGroup.POST("/vouchers", voucherHandler.CreateVoucher)
+	adminGroup.POST("/vouchers/:id/deactivate", voucherHandler.DeactivateVoucher)
+	adminGroup.POST("/categories", categoryHandler.CreateCategory)
+	adminGroup.PUT("/categories/:id", categoryHandler.UpdateCategory)
+	adminGroup.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+	adminGroup.POST("/books/import", bookTransferHandler.ImportBooks)
+	adminGroup.GET("/books/export", bookTransferHandler.ExportBooks)
+
+	copyGroup.Use(middleware.JWTMiddleware(jwtSecret))
+	copyGroup.GET("", bookCopyHandler.GetCopies)
+	copyGroup.GET("/barcode/:barcode", bookCopyHandler.GetCopyByBarcode)
+	copyGroup.POST("", bookCopyHandler.CreateCopy)
+	copyGroup.PUT("/:id", bookCopyHandler.UpdateCopy)
+	copyGroup.DELETE("/:id", bookCopyHandler.DeleteCopy)
+
+	reservationGroup.Use(middleware.JWTMiddleware(jwtSecret))
+	reservationGroup.POST("", reservationHandler.CreateReservation)
+	reservationGroup.GET("", reservationHandler.GetReservations)
+	reservationGroup.DELETE("/:id", reservationHandler.CancelReservation)
 
 	port := os.Getenv("PORT")
 	if port == "" {
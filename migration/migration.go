@@ -0,0 +1,275 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"pojok-baca-api/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Run brings the schema up to date and backfills the data newer tables depend on.
+// Every step is safe to run on each start.
+func Run(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&model.User{},
+		&model.Author{},
+		&model.Category{},
+		&model.Book{},
+		&model.BookCopy{},
+		&model.Rental{},
+		&model.RentalEvent{},
+		&model.DepositTransaction{},
+		&model.DepositNotification{},
+		&model.ReconciliationReport{},
+		&model.Reservation{},
+		&model.Checkout{},
+		&model.PricingPlan{},
+		&model.LedgerEntry{},
+		&model.Withdrawal{},
+		&model.WithdrawalEvent{},
+		&model.BalanceAdjustment{},
+		&model.Voucher{},
+		&model.VoucherRedemption{},
+		&model.MembershipPlan{},
+		&model.Membership{},
+	); err != nil {
+		return err
+	}
+
+	if err := setupBookSearch(db); err != nil {
+		return err
+	}
+	if err := normalizeCategories(db); err != nil {
+		return err
+	}
+	if err := backfillBookCopies(db); err != nil {
+		return err
+	}
+	return backfillOpeningBalances(db)
+}
+
+// setupBookSearch enables trigram matching for typo-tolerant search, indexes book names
+// for it and fills in the full-text document of books stored before search existed.
+func setupBookSearch(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return err
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_books_name_trgm ON books USING gin (name gin_trgm_ops)").Error; err != nil {
+		return err
+	}
+	return db.Exec("UPDATE books SET search_vector = " + model.BookSearchVectorSQL + " WHERE search_vector IS NULL").Error
+}
+
+// uncategorizedBook matches the books not filed under any category yet.
+const uncategorizedBook = "NOT EXISTS (SELECT 1 FROM book_categories WHERE book_categories.book_id = books.id)"
+
+// normalizeCategories turns the free-text categories of books into categories. A
+// spelling belongs to the category of that name, or else to the one with its slug, so
+// spellings with the same slug become one category named after the spelling most books
+// use. Books and pricing plans with any of the spellings are renamed to that name and
+// the books are filed under the category. Only books not filed under any category are
+// touched, so categories given to books since are never reset.
+func normalizeCategories(db *gorm.DB) error {
+	var spellings []struct {
+		Category string
+		Books    int
+	}
+	err := db.Model(&model.Book{}).
+		Select("category, COUNT(*) AS books").
+		Where(uncategorizedBook).
+		Group("category").
+		Order("books DESC, category").
+		Scan(&spellings).Error
+	if err != nil {
+		return err
+	}
+
+	for _, spelling := range spellings {
+		name := strings.Join(strings.Fields(spelling.Category), " ")
+		slug := model.Slugify(name)
+		if slug == "" {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var category model.Category
+			err := tx.Where("LOWER(name) = LOWER(?)", name).First(&category).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = tx.Where(model.Category{Slug: slug}).
+					Attrs(model.Category{Name: name}).
+					FirstOrCreate(&category).Error
+			}
+			if err != nil {
+				return err
+			}
+
+			var bookIDs []uint
+			err = tx.Model(&model.Book{}).Where("category = ?", spelling.Category).Where(uncategorizedBook).
+				Pluck("id", &bookIDs).Error
+			if err != nil || len(bookIDs) == 0 {
+				return err
+			}
+
+			err = tx.Exec("INSERT INTO book_categories (book_id, category_id) "+
+				"SELECT id, ? FROM books WHERE id IN ? ON CONFLICT DO NOTHING",
+				category.ID, bookIDs).Error
+			if err != nil {
+				return err
+			}
+			if spelling.Category == category.Name {
+				return nil
+			}
+
+			err = tx.Exec("UPDATE books SET category = ? WHERE id IN ?", category.Name, bookIDs).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec("UPDATE books SET search_vector = "+model.BookSearchVectorSQL+" WHERE id IN ?", bookIDs).Error
+			if err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE pricing_plans SET category = ? WHERE category = ?", category.Name, spelling.Category).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	//Plans of categories without books follow the spelling of a category with the same slug
+	var planCategories []string
+	err = db.Model(&model.PricingPlan{}).Where("category <> ''").Distinct().Pluck("category", &planCategories).Error
+	if err != nil {
+		return err
+	}
+	for _, planCategory := range planCategories {
+		var category model.Category
+		err := db.Where("slug = ?", model.Slugify(planCategory)).First(&category).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.Name == planCategory) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = db.Exec("UPDATE pricing_plans SET category = ? WHERE category = ?", category.Name, planCategory).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillOpeningBalances records the deposit each user had before the ledger existed
+// as an opening balance, so the ledger of every user adds up to their deposit.
+func backfillOpeningBalances(db *gorm.DB) error {
+	var users []model.User
+	err := db.Where("COALESCE(deposit, 0) <> 0").
+		Where("NOT EXISTS (SELECT 1 FROM ledger_entries WHERE ledger_entries.user_id = users.id)").
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		transactionID := fmt.Sprintf("LT-opening-%d", user.ID)
+		entries := []model.LedgerEntry{
+			{
+				TransactionID: transactionID,
+				UserID:        user.ID,
+				Account:       model.LedgerAccountWallet,
+				Amount:        *user.Deposit,
+				Type:          model.LedgerTypeOpeningBalance,
+				Description:   "Balance before the wallet ledger",
+			},
+			{
+				TransactionID: transactionID,
+				UserID:        user.ID,
+				Account:       model.LedgerAccountAdjustment,
+				Amount:        -*user.Deposit,
+				Type:          model.LedgerTypeOpeningBalance,
+				Description:   "Balance before the wallet ledger",
+			},
+		}
+		if err := db.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillBookCopies gives every book without copies its physical copies: one per unit
+// of stock, one per rental still out and one per ready reservation hold. Rentals and
+// holds are linked to their copy, so availability stays what it was.
+func backfillBookCopies(db *gorm.DB) error {
+	var books []model.Book
+	err := db.Where("NOT EXISTS (SELECT 1 FROM book_copies WHERE book_copies.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			seq := 0
+			newCopy := func(status string) (model.BookCopy, error) {
+				seq++
+				bookCopy := model.BookCopy{
+					BookID:    book.ID,
+					Barcode:   model.GenerateBarcode(book.ID, seq),
+					Condition: model.BookCopyConditionGood,
+					Status:    status,
+				}
+				err := tx.Omit("Book").Create(&bookCopy).Error
+				return bookCopy, err
+			}
+
+			for i := 0; i < book.Stok; i++ {
+				if _, err := newCopy(model.BookCopyStatusAvailable); err != nil {
+					return err
+				}
+			}
+
+			var rentals []model.Rental
+			err := tx.Where("book_id = ? AND book_copy_id IS NULL AND status IN ?", book.ID,
+				[]string{model.RentalStatusBorrowed, model.RentalStatusOverdue}).
+				Find(&rentals).Error
+			if err != nil {
+				return err
+			}
+			for _, rental := range rentals {
+				bookCopy, err := newCopy(model.BookCopyStatusOnLoan)
+				if err != nil {
+					return err
+				}
+				if err := tx.Model(&rental).Update("book_copy_id", bookCopy.ID).Error; err != nil {
+					return err
+				}
+			}
+
+			var holds []model.Reservation
+			err = tx.Where("book_id = ? AND book_copy_id IS NULL AND status = ?", book.ID, model.ReservationStatusReady).
+				Find(&holds).Error
+			if err != nil {
+				return err
+			}
+			for _, hold := range holds {
+				bookCopy, err := newCopy(model.BookCopyStatusOnHold)
+				if err != nil {
+					return err
+				}
+				if err := tx.Model(&hold).Update("book_copy_id", bookCopy.ID).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
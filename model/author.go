@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// Author wrote one or more books in the catalog. Authors are matched by name, so a
+// name is stored once however many books carry it.
+type Author struct {
+	gorm.Model
+	Name  string `gorm:"not null;uniqueIndex"`
+	Books []Book `gorm:"many2many:book_authors"`
+}
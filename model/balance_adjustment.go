@@ -0,0 +1,26 @@
+package model
+
+import "gorm.io/gorm"
+
+// Reason codes of a manual balance adjustment.
+const (
+	AdjustmentReasonCorrection = "correction"
+	AdjustmentReasonGoodwill   = "goodwill"
+	AdjustmentReasonChargeback = "chargeback"
+	AdjustmentReasonMigration  = "migration"
+	AdjustmentReasonOther      = "other"
+)
+
+// BalanceAdjustment records an admin crediting (positive amount) or debiting a user's
+// deposit by hand, why, and who did it. The money moves through the ledger like any
+// other posting; BalanceAfter is the deposit right after the adjustment.
+type BalanceAdjustment struct {
+	gorm.Model
+	UserID        uint   `gorm:"not null;index"`
+	AdminID       uint   `gorm:"not null;index"`
+	Amount        int    `gorm:"not null"`
+	ReasonCode    string `gorm:"not null"`
+	Note          string `gorm:"not null"`
+	AllowNegative bool   `gorm:"not null;default:false"`
+	BalanceAfter  int    `gorm:"not null"`
+}
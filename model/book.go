@@ -2,11 +2,35 @@ package model
 
 import "gorm.io/gorm"
 
+// BookSearchVectorSQL computes the full-text document of a book row. Names are indexed
+// both as written, for Indonesian titles, and stemmed as English; they weigh more than
+// the category.
+const BookSearchVectorSQL = "setweight(to_tsvector('simple', coalesce(name, '')), 'A') || " +
+	"setweight(to_tsvector('english', coalesce(name, '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(category, '')), 'B')"
+
+// Book is a title in the catalog. Stok caches the number of available copies.
+// Category caches the name of the first of its Categories, the one pricing plans and
+// vouchers match on.
+// ISBN is stored without separators and is nil for books without one. PublicationYear
+// and PageCount are zero when unknown. SearchVector is only ever written by the
+// database from BookSearchVectorSQL.
 type Book struct {
 	gorm.Model
-	Name       string   `gorm:"not null"`
-	Stok       int      `gorm:"not null"`
-	RentalCost int      `gorm:"not null"`
-	Category   string   `gorm:"not null"`
-	Rental     []Rental `gorm:"foreignKey:BookID"`
+	Name            string  `gorm:"not null"`
+	Stok            int     `gorm:"not null"`
+	RentalCost      int     `gorm:"not null"`
+	Category        string  `gorm:"not null"`
+	ISBN            *string `gorm:"uniqueIndex"`
+	Publisher       string
+	PublicationYear int
+	Language        string
+	PageCount       int
+	Synopsis        string `gorm:"type:text"`
+	CoverURL        string
+	SearchVector    string     `gorm:"type:tsvector;index:idx_books_search_vector,type:gin;->:false" json:"-"`
+	Authors         []Author   `gorm:"many2many:book_authors"`
+	Categories      []Category `gorm:"many2many:book_categories"`
+	Rental          []Rental   `gorm:"foreignKey:BookID"`
+	Copies          []BookCopy `gorm:"foreignKey:BookID"`
 }
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BookCopyStatusAvailable = "Available"
+	BookCopyStatusOnLoan    = "OnLoan"
+	BookCopyStatusOnHold    = "OnHold"
+	BookCopyStatusDamaged   = "Damaged"
+	BookCopyStatusLost      = "Lost"
+	BookCopyStatusRetired   = "Retired"
+)
+
+const (
+	BookCopyConditionGood    = "Good"
+	BookCopyConditionFair    = "Fair"
+	BookCopyConditionPoor    = "Poor"
+	BookCopyConditionDamaged = "Damaged"
+)
+
+// BookCopy is one physical copy of a book, identified by the barcode on its label.
+type BookCopy struct {
+	gorm.Model
+	BookID        uint   `gorm:"not null;index"`
+	Barcode       string `gorm:"not null;uniqueIndex"`
+	Condition     string `gorm:"not null;default:Good"`
+	ShelfLocation string
+	Status        string `gorm:"not null;index"`
+	Book          Book
+}
+
+// GenerateBarcode returns the barcode of the seq-th copy of a book, for copies that
+// come without a label of their own.
+func GenerateBarcode(bookID uint, seq int) string {
+	return fmt.Sprintf("PB%06d%04d", bookID, seq)
+}
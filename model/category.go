@@ -0,0 +1,54 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Category groups books in the catalog. Categories form a tree through ParentID and
+// are identified by their Slug, so names that differ only in case, spacing or
+// punctuation are the same category.
+type Category struct {
+	gorm.Model
+	Slug     string `gorm:"not null;uniqueIndex"`
+	Name     string `gorm:"not null"`
+	ParentID *uint  `gorm:"index"`
+	Parent   *Category
+	Books    []Book `gorm:"many2many:book_categories"`
+}
+
+// inCategories reports whether the category called name, matched by name or slug, is
+// one of categories.
+func inCategories(name string, categories []Category) bool {
+	slug := Slugify(name)
+	if slug == "" {
+		return false
+	}
+	for _, category := range categories {
+		if category.Slug == slug || Slugify(category.Name) == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// Slugify turns a category name into its slug: lower case letters and digits, with
+// every run of other characters replaced by a single hyphen.
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,14 @@
+package model
+
+import "gorm.io/gorm"
+
+// Checkout groups the rentals created together from one cart. TotalCost is what was
+// charged, after the Discount of the voucher used, if any.
+type Checkout struct {
+	gorm.Model
+	UserID    uint     `gorm:"not null;index"`
+	TotalCost int      `gorm:"not null"`
+	Discount  int      `gorm:"not null;default:0"`
+	VoucherID *uint    `gorm:"index"`
+	Rentals   []Rental `gorm:"foreignKey:CheckoutID"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "gorm.io/gorm"
+
+// What happened to a received payment notification.
+const (
+	NotificationOutcomeApplied  = "applied"
+	NotificationOutcomeIgnored  = "ignored"
+	NotificationOutcomeRejected = "rejected"
+)
+
+// Where a payment status came from.
+const (
+	NotificationSourceWebhook    = "webhook"
+	NotificationSourceReconciler = "reconciler"
+)
+
+// DepositNotification is the audit record of one payment status as it was received,
+// from the webhook or from the reconciler asking the gateway, whether or not it
+// changed the deposit transaction.
+type DepositNotification struct {
+	gorm.Model
+	OrderID           string `gorm:"not null;index"`
+	TransactionStatus string
+	FraudStatus       string
+	StatusCode        string
+	GrossAmount       string
+	FromStatus        string
+	Outcome           string `gorm:"not null"`
+	Reason            string
+	Source            string `gorm:"not null;default:'webhook'"`
+	Payload           string `gorm:"type:text"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "gorm.io/gorm"
+
+// Ledger accounts. Every user has a wallet, the others belong to the library.
+// Withdrawal holds money approved for a payout that has not been paid yet, promotion
+// pays for voucher bonus credit.
+const (
+	LedgerAccountWallet     = "wallet"
+	LedgerAccountCash       = "cash"
+	LedgerAccountRevenue    = "revenue"
+	LedgerAccountAdjustment = "adjustment"
+	LedgerAccountWithdrawal = "withdrawal"
+	LedgerAccountPromotion  = "promotion"
+)
+
+const (
+	LedgerTypeOpeningBalance    = "opening_balance"
+	LedgerTypeTopUp             = "top_up"
+	LedgerTypeRentalCharge      = "rental_charge"
+	LedgerTypeExtensionCharge   = "extension_charge"
+	LedgerTypeLateFee           = "late_fee"
+	LedgerTypeDamageFee         = "damage_fee"
+	LedgerTypeReplacementFee    = "replacement_fee"
+	LedgerTypeRefund            = "refund"
+	LedgerTypeAdjustment        = "adjustment"
+	LedgerTypeWithdrawalHold    = "withdrawal_hold"
+	LedgerTypeWithdrawalRelease = "withdrawal_release"
+	LedgerTypeWithdrawalPayout  = "withdrawal_payout"
+	LedgerTypeVoucherBonus      = "voucher_bonus"
+	LedgerTypeMembershipFee     = "membership_fee"
+)
+
+// LedgerEntry is one leg of a balanced money movement. The legs sharing a
+// TransactionID sum to zero. Entries are only ever appended, the wallet balance of a
+// user is the sum of the amounts on their wallet account.
+type LedgerEntry struct {
+	gorm.Model
+	TransactionID  string  `gorm:"not null;index"`
+	UserID         uint    `gorm:"not null;index"`
+	Account        string  `gorm:"not null;index"`
+	Amount         int     `gorm:"not null"`
+	Type           string  `gorm:"not null"`
+	DepositOrderID *string `gorm:"index"`
+	RentalID       *uint   `gorm:"index"`
+	WithdrawalID   *uint   `gorm:"index"`
+	AdjustmentID   *uint   `gorm:"index"`
+	Description    string
+}
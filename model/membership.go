@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MembershipStatusActive  = "active"
+	MembershipStatusRenewed = "renewed"
+	MembershipStatusExpired = "expired"
+)
+
+// MembershipPlan is a subscription a user buys from their deposit. RentalQuota is the
+// number of rentals covered per period, zero for unlimited.
+type MembershipPlan struct {
+	gorm.Model
+	Name         string `gorm:"not null"`
+	Price        int    `gorm:"not null"`
+	DurationDays int    `gorm:"not null"`
+	RentalQuota  int    `gorm:"not null;default:0"`
+	Active       bool   `gorm:"not null;default:true"`
+}
+
+// Membership is one paid period of a plan. The price and quota are copied from the plan
+// when the period starts, so changing a plan does not touch running periods. A renewal
+// starts a new period; the old one is kept as renewed.
+type Membership struct {
+	gorm.Model
+	UserID      uint      `gorm:"not null;index"`
+	PlanID      uint      `gorm:"not null"`
+	Price       int       `gorm:"not null"`
+	RentalQuota int       `gorm:"not null;default:0"`
+	RentalsUsed int       `gorm:"not null;default:0"`
+	StartsAt    time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	AutoRenew   bool      `gorm:"not null;default:false"`
+	Status      string    `gorm:"not null;index"`
+	Plan        MembershipPlan
+}
+
+// Unlimited reports whether the period covers any number of rentals.
+func (m Membership) Unlimited() bool {
+	return m.RentalQuota == 0
+}
+
+// RemainingQuota is the number of rentals the period still covers. It is meaningless
+// for an unlimited membership.
+func (m Membership) RemainingQuota() int {
+	if m.RentalsUsed >= m.RentalQuota {
+		return 0
+	}
+	return m.RentalQuota - m.RentalsUsed
+}
+
+// Covers reports whether one more rental is covered by the period.
+func (m Membership) Covers() bool {
+	return m.Unlimited() || m.RemainingQuota() > 0
+}
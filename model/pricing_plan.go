@@ -0,0 +1,24 @@
+package model
+
+import "gorm.io/gorm"
+
+// PricingPlan is a rental duration with its price. A plan belongs either to one
+// book (BookID set) or to every book of a category.
+type PricingPlan struct {
+	gorm.Model
+	BookID       *uint  `gorm:"index"`
+	Category     string `gorm:"index"`
+	Name         string `gorm:"not null"`
+	DurationDays int    `gorm:"not null"`
+	Price        int    `gorm:"not null"`
+}
+
+// AppliesTo reports whether the plan can be chosen when renting book. bookTree holds
+// the categories of the book and every category above them, so a category plan also
+// covers the books of its subcategories.
+func (p PricingPlan) AppliesTo(book Book, bookTree []Category) bool {
+	if p.BookID != nil {
+		return *p.BookID == book.ID
+	}
+	return inCategories(p.Category, bookTree)
+}
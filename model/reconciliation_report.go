@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReconciliationReport is the result of one run of the payment reconciler over the
+// pending deposits. Details has one line per deposit that was not left unchanged.
+type ReconciliationReport struct {
+	gorm.Model
+	StartedAt  time.Time `gorm:"not null"`
+	FinishedAt time.Time `gorm:"not null"`
+	Checked    int       `gorm:"not null;default:0"`
+	Updated    int       `gorm:"not null;default:0"`
+	Expired    int       `gorm:"not null;default:0"`
+	Unchanged  int       `gorm:"not null;default:0"`
+	Failed     int       `gorm:"not null;default:0"`
+	Details    string    `gorm:"type:text"`
+}
@@ -5,13 +5,34 @@ import (
 	"time"
 )
 
+const (
+	RentalStatusBorrowed  = "Borrowed"
+	RentalStatusOverdue   = "Overdue"
+	RentalStatusReturned  = "Returned"
+	RentalStatusLost      = "Lost"
+	RentalStatusCancelled = "Cancelled"
+)
+
 type Rental struct {
 	gorm.Model
-	UserID     uint      `gorm:"not null"`
-	BookID     uint      `gorm:"not null"`
-	RentDate   time.Time `gorm:"not null"`
-	ReturnDate *time.Time
-	Status     string `gorm:"not null"`
-	User       User
-	Book       Book
+	UserID        uint  `gorm:"not null"`
+	BookID        uint  `gorm:"not null"`
+	BookCopyID    *uint `gorm:"index"`
+	CheckoutID    *uint `gorm:"index"`
+	MembershipID  *uint `gorm:"index"`
+	PricingPlanID *uint
+	DurationDays  int       `gorm:"not null;default:7"`
+	Price         int       `gorm:"not null;default:0"`
+	Discount      int       `gorm:"not null;default:0"`
+	RentDate      time.Time `gorm:"not null"`
+	ReturnDate    *time.Time
+	ReturnedAt    *time.Time
+	LateFee       int    `gorm:"not null;default:0"`
+	DamageFee     int    `gorm:"not null;default:0"`
+	RenewalCount  int    `gorm:"not null;default:0"`
+	Status        string `gorm:"not null"`
+	User          User
+	Book          Book
+	BookCopy      BookCopy
+	Events        []RentalEvent `gorm:"foreignKey:RentalID"`
 }
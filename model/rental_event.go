@@ -0,0 +1,26 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	RentalEventReturned    = "returned"
+	RentalEventExtended    = "extended"
+	RentalEventForceClosed = "force_closed"
+	RentalEventMarkedLost  = "marked_lost"
+	RentalEventCancelled   = "cancelled"
+	RentalEventDamaged     = "damaged"
+)
+
+// RentalEvent records something that happened to a rental, who did it and why.
+// Amount is the change to the user's deposit, negative for a charge.
+type RentalEvent struct {
+	gorm.Model
+	RentalID   uint   `gorm:"not null;index"`
+	Type       string `gorm:"not null"`
+	FromStatus string `gorm:"not null"`
+	ToStatus   string `gorm:"not null"`
+	Reason     string `gorm:"not null;default:''"`
+	Condition  string
+	Amount     int  `gorm:"not null;default:0"`
+	ActorID    uint `gorm:"not null"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	ReservationStatusWaiting   = "Waiting"
+	ReservationStatusReady     = "Ready"
+	ReservationStatusFulfilled = "Fulfilled"
+	ReservationStatusCancelled = "Cancelled"
+	ReservationStatusExpired   = "Expired"
+)
+
+type Reservation struct {
+	gorm.Model
+	UserID        uint   `gorm:"not null;index"`
+	BookID        uint   `gorm:"not null;index"`
+	Status        string `gorm:"not null"`
+	HoldExpiresAt *time.Time
+	BookCopyID    *uint `gorm:"index"`
+	User          User
+	Book          Book
+}
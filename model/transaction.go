@@ -5,12 +5,32 @@ import (
 	"time"
 )
 
+// Deposit transaction statuses, as reported by Midtrans in transaction_status.
+const (
+	DepositStatusPending    = "pending"
+	DepositStatusCapture    = "capture"
+	DepositStatusSettlement = "settlement"
+	DepositStatusDeny       = "deny"
+	DepositStatusCancel     = "cancel"
+	DepositStatusExpire     = "expire"
+	DepositStatusFailure    = "failure"
+)
+
+// Outcomes of the Midtrans fraud check on a card payment, as reported in fraud_status.
+const (
+	FraudStatusAccept    = "accept"
+	FraudStatusChallenge = "challenge"
+	FraudStatusDeny      = "deny"
+)
+
 type DepositTransaction struct {
 	gorm.Model
 	UserID     uint
 	OrderID    string
 	PaymentRef string 
 	Deposit    int
+	VoucherID  *uint
+	Bonus      int `gorm:"not null;default:0"`
 	Status     string
 	PaidAt     *time.Time
 }
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Voucher types. A percentage voucher takes Value percent off a rental checkout or
+// adds it as bonus credit to a top-up, a fixed voucher takes Value off a checkout and
+// a bonus credit voucher adds Value to a top-up.
+const (
+	VoucherTypePercentage  = "percentage"
+	VoucherTypeFixed       = "fixed"
+	VoucherTypeBonusCredit = "bonus_credit"
+)
+
+// Voucher scopes, what a code can be redeemed on.
+const (
+	VoucherScopeRental = "rental"
+	VoucherScopeTopUp  = "top_up"
+)
+
+// Redemption statuses. A top-up redemption stays pending until the payment settles
+// and is voided when it fails; pending and applied redemptions count against the limits.
+const (
+	RedemptionStatusPending = "pending"
+	RedemptionStatusApplied = "applied"
+	RedemptionStatusVoided  = "voided"
+)
+
+// Voucher is a promo code. MaxDiscount caps a percentage, MinAmount is the smallest
+// checkout or top-up it can be used on, and a limit of zero means no limit.
+// Categories is a comma separated list of the names or slugs of the book categories a
+// rental voucher discounts; empty means every category.
+type Voucher struct {
+	gorm.Model
+	Code           string `gorm:"not null;uniqueIndex"`
+	Type           string `gorm:"not null"`
+	Scope          string `gorm:"not null"`
+	Value          int    `gorm:"not null"`
+	MaxDiscount    int    `gorm:"not null;default:0"`
+	MinAmount      int    `gorm:"not null;default:0"`
+	Categories     string `gorm:"not null;default:''"`
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	MaxUses        int  `gorm:"not null;default:0"`
+	MaxUsesPerUser int  `gorm:"not null;default:0"`
+	Active         bool `gorm:"not null;default:true"`
+}
+
+// CategoryList returns the categories of the voucher, empty for every category.
+func (v Voucher) CategoryList() []string {
+	var categories []string
+	for _, category := range strings.Split(v.Categories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// RenameCategory replaces category from, named by its name or slug, with the name of
+// to in the categories of the voucher. It reports whether the voucher named from.
+func (v *Voucher) RenameCategory(from Category, to Category) bool {
+	renamed := false
+	var categories []string
+	seen := make(map[string]bool)
+	for _, category := range v.CategoryList() {
+		if inCategories(category, []Category{from}) {
+			category = to.Name
+			renamed = true
+		}
+		//A merge can leave the voucher naming the same category twice
+		if !seen[Slugify(category)] {
+			seen[Slugify(category)] = true
+			categories = append(categories, category)
+		}
+	}
+	if renamed {
+		v.Categories = strings.Join(categories, ",")
+	}
+	return renamed
+}
+
+// AppliesTo reports whether a rental voucher discounts a book filed under the
+// categories of bookTree, the categories of the book and every category above them.
+// A voucher for a category so also discounts the books of its subcategories.
+func (v Voucher) AppliesTo(bookTree []Category) bool {
+	categories := v.CategoryList()
+	if len(categories) == 0 {
+		return true
+	}
+	for _, category := range categories {
+		if inCategories(category, bookTree) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAt reports whether the voucher can be redeemed at t.
+func (v Voucher) ValidAt(t time.Time) bool {
+	if !v.Active {
+		return false
+	}
+	if v.ValidFrom != nil && t.Before(*v.ValidFrom) {
+		return false
+	}
+	return v.ValidUntil == nil || t.Before(*v.ValidUntil)
+}
+
+// VoucherRedemption is one use of a voucher, on a checkout or on a deposit order, with
+// the discount or bonus it gave.
+type VoucherRedemption struct {
+	gorm.Model
+	VoucherID      uint    `gorm:"not null;index"`
+	UserID         uint    `gorm:"not null;index"`
+	CheckoutID     *uint   `gorm:"index"`
+	DepositOrderID *string `gorm:"index"`
+	Amount         int     `gorm:"not null"`
+	Status         string  `gorm:"not null"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	WithdrawalStatusPending  = "pending"
+	WithdrawalStatusApproved = "approved"
+	WithdrawalStatusPaid     = "paid"
+	WithdrawalStatusRejected = "rejected"
+)
+
+// Withdrawal is a user's request to get part or all of their deposit back. Payouts
+// happen outside the system; PayoutRef is the reference an admin records for it.
+type Withdrawal struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	Amount    int    `gorm:"not null"`
+	Status    string `gorm:"not null;index"`
+	Note      string
+	PayoutRef string
+	User      User
+	Events    []WithdrawalEvent `gorm:"foreignKey:WithdrawalID"`
+}
+
+// WithdrawalEvent records a status change of a withdrawal, who made it and why.
+type WithdrawalEvent struct {
+	gorm.Model
+	WithdrawalID uint   `gorm:"not null;index"`
+	FromStatus   string `gorm:"not null;default:''"`
+	ToStatus     string `gorm:"not null"`
+	Reason       string `gorm:"not null;default:''"`
+	ActorID      uint   `gorm:"not null"`
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+type BalanceAdjustmentRepository interface {
+	Create(adjustment model.BalanceAdjustment) (model.BalanceAdjustment, error)
+}
+
+type balanceAdjustmentRepository struct {
+	db *gorm.DB
+}
+
+func NewBalanceAdjustmentRepository(db *gorm.DB) BalanceAdjustmentRepository {
+	return &balanceAdjustmentRepository{db}
+}
+
+func (r *balanceAdjustmentRepository) Create(adjustment model.BalanceAdjustment) (model.BalanceAdjustment, error) {
+	err := r.db.Create(&adjustment).Error
+	return adjustment, err
+}
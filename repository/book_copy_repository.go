@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+)
+
+type BookCopyRepository interface {
+	Create(bookCopy model.BookCopy) (model.BookCopy, error)
+	GetByID(id uint) (model.BookCopy, error)
+	GetByIDForUpdate(id uint) (model.BookCopy, error)
+	GetByBarcode(barcode string) (model.BookCopy, error)
+	GetByBookID(bookID uint) ([]model.BookCopy, error)
+	GetAvailableForUpdate(bookID uint, limit int) ([]model.BookCopy, error)
+	CountByBookID(bookID uint) (int64, error)
+	CountByStatus(bookID uint, statuses []string) (int64, error)
+	Update(bookCopy model.BookCopy) (model.BookCopy, error)
+	Delete(id uint) error
+}
+
+type bookCopyRepository struct {
+	db *gorm.DB
+}
+
+func NewBookCopyRepository(db *gorm.DB) BookCopyRepository {
+	return &bookCopyRepository{db}
+}
+
+func (r *bookCopyRepository) Create(bookCopy model.BookCopy) (model.BookCopy, error) {
+	err := r.db.Omit(clause.Associations).Create(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) GetByID(id uint) (model.BookCopy, error) {
+	var bookCopy model.BookCopy
+	err := r.db.Preload("Book").Where("id = ?", id).First(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) GetByIDForUpdate(id uint) (model.BookCopy, error) {
+	var bookCopy model.BookCopy
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) GetByBarcode(barcode string) (model.BookCopy, error) {
+	var bookCopy model.BookCopy
+	err := r.db.Preload("Book").Where("barcode = ?", barcode).First(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) GetByBookID(bookID uint) ([]model.BookCopy, error) {
+	var copies []model.BookCopy
+	err := r.db.Where("book_id = ?", bookID).Order("id").Find(&copies).Error
+	return copies, err
+}
+
+// GetAvailableForUpdate locks up to limit available copies of a book, oldest first.
+func (r *bookCopyRepository) GetAvailableForUpdate(bookID uint, limit int) ([]model.BookCopy, error) {
+	var copies []model.BookCopy
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, model.BookCopyStatusAvailable).
+		Order("id").
+		Limit(limit).
+		Find(&copies).Error
+	return copies, err
+}
+
+// CountByBookID counts every copy a book ever had, deleted ones included, so
+// generated barcodes are never reused.
+func (r *bookCopyRepository) CountByBookID(bookID uint) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.BookCopy{}).Where("book_id = ?", bookID).Count(&count).Error
+	return count, err
+}
+
+// CountByStatus counts the copies of a book that are in one of statuses.
+func (r *bookCopyRepository) CountByStatus(bookID uint, statuses []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BookCopy{}).Where("book_id = ? AND status IN ?", bookID, statuses).Count(&count).Error
+	return count, err
+}
+
+func (r *bookCopyRepository) Update(bookCopy model.BookCopy) (model.BookCopy, error) {
+	err := r.db.Omit(clause.Associations).Save(&bookCopy).Error
+	return bookCopy, err
+}
+
+func (r *bookCopyRepository) Delete(id uint) error {
+	return r.db.Delete(&model.BookCopy{}, id).Error
+}
@@ -2,13 +2,58 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"pojok-baca-api/model"
+	"strings"
 )
 
+// BookFilter narrows and orders the catalog. Zero values mean no filter. Category is
+// the name or slug of a category and also matches the categories below it. Sort is one
+// of the keys of BookSortOrders.
+type BookFilter struct {
+	Keyword       string
+	Category      string
+	AvailableOnly bool
+	MinCost       int
+	MaxCost       int
+	Sort          string
+	Page          int
+	Limit         int
+}
+
+// BookSortOrders whitelists the orders the catalog can be sorted in, prefix with "-"
+// for the reverse order.
+var BookSortOrders = map[string]string{
+	"name":    "name",
+	"-name":   "name DESC",
+	"cost":    "rental_cost",
+	"-cost":   "rental_cost DESC",
+	"newest":  "created_at DESC",
+	"-newest": "created_at",
+}
+
+// categoryTreeSQL selects the IDs of the category with a given slug and of every
+// category below it.
+const categoryTreeSQL = "WITH RECURSIVE tree AS (" +
+	"SELECT id FROM categories WHERE slug = ? AND deleted_at IS NULL " +
+	"UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id WHERE categories.deleted_at IS NULL" +
+	") SELECT id FROM tree"
+
+// likePattern turns a keyword into a LIKE pattern matching it anywhere, with the LIKE
+// wildcards in it taken literally.
+func likePattern(keyword string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
+	return "%" + escaped + "%"
+}
+
 type BookRepository interface {
-	GetAll() ([]model.Book, error)
+	List(filter BookFilter) ([]model.Book, int64, error)
 	Create(book model.Book) (model.Book, error)
 	GetByID(id uint) (model.Book, error)
+	GetByISBN(isbn string) (model.Book, error)
+	Each(batchSize int, fn func(books []model.Book) error) error
+	GetByIDForUpdate(id uint) (model.Book, error)
+	SyncStock(id uint) error
 	Delete(id uint) error
 	Update(book model.Book, id uint) (model.Book, error)
 }
@@ -21,22 +66,150 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 	return &bookRepository{db}
 }
 
-func (r *bookRepository) GetAll() ([]model.Book, error) {
+// List returns one page of books matching the filter and the total number of matches.
+// The keyword is matched case-insensitively anywhere in the name.
+func (r *bookRepository) List(filter BookFilter) ([]model.Book, int64, error) {
+	query := r.db.Model(&model.Book{})
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		query = query.Where("name ILIKE ?", likePattern(keyword))
+	}
+	if filter.Category != "" {
+		query = query.Where("id IN (SELECT book_id FROM book_categories WHERE category_id IN ("+categoryTreeSQL+"))",
+			model.Slugify(filter.Category))
+	}
+	if filter.AvailableOnly {
+		query = query.Where("stok > 0")
+	}
+	if filter.MinCost > 0 {
+		query = query.Where("rental_cost >= ?", filter.MinCost)
+	}
+	if filter.MaxCost > 0 {
+		query = query.Where("rental_cost <= ?", filter.MaxCost)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := BookSortOrders[filter.Sort]
+	if !ok {
+		order = "id"
+	}
+
 	var books []model.Book
-	err := r.db.Find(&books).Error
-	return books, err
+	err := query.Order(order).Order("id").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&books).Error
+	return books, total, err
 }
 
+// refreshSearchVector recomputes the full-text document of a book after its name or
+// category changed.
+func refreshSearchVector(db *gorm.DB, id uint) error {
+	return db.Exec("UPDATE books SET search_vector = "+model.BookSearchVectorSQL+" WHERE id = ?", id).Error
+}
+
+// resolveAuthors looks up the authors by name, creating the ones not stored yet.
+func resolveAuthors(db *gorm.DB, authors []model.Author) ([]model.Author, error) {
+	resolved := make([]model.Author, 0, len(authors))
+	for _, author := range authors {
+		var stored model.Author
+		if err := db.Where(model.Author{Name: author.Name}).FirstOrCreate(&stored).Error; err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, stored)
+	}
+	return resolved, nil
+}
+
+// replaceAuthors makes authors, matched by name, the authors of the book.
+func replaceAuthors(db *gorm.DB, book *model.Book, authors []model.Author) error {
+	resolved, err := resolveAuthors(db, authors)
+	if err != nil {
+		return err
+	}
+	book.Authors = resolved
+	return db.Model(book).Omit("Authors.*").Association("Authors").Replace(resolved)
+}
+
+// replaceCategories files the book under categories, which must already be stored.
+func replaceCategories(db *gorm.DB, book *model.Book, categories []model.Category) error {
+	book.Categories = categories
+	return db.Model(book).Omit("Categories.*").Association("Categories").Replace(categories)
+}
+
+// Create stores the book with its authors and categories, together with Stok new copies labelled with
+// generated barcodes.
 func (r *bookRepository) Create(book model.Book) (model.Book, error) {
-	err := r.db.Create(&book).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		authors, categories := book.Authors, book.Categories
+		if err := tx.Omit("Copies", "Authors", "Categories").Create(&book).Error; err != nil {
+			return err
+		}
+		if err := replaceAuthors(tx, &book, authors); err != nil {
+			return err
+		}
+		if err := replaceCategories(tx, &book, categories); err != nil {
+			return err
+		}
+		if err := refreshSearchVector(tx, book.ID); err != nil {
+			return err
+		}
+		if book.Stok <= 0 {
+			return nil
+		}
+
+		copies := make([]model.BookCopy, 0, book.Stok)
+		for i := 1; i <= book.Stok; i++ {
+			copies = append(copies, model.BookCopy{
+				BookID:    book.ID,
+				Barcode:   model.GenerateBarcode(book.ID, i),
+				Condition: model.BookCopyConditionGood,
+				Status:    model.BookCopyStatusAvailable,
+			})
+		}
+		return tx.Create(&copies).Error
+	})
 	return book, err
 }
 func (r *bookRepository) GetByID(id uint) (model.Book, error) {
 	var book model.Book
-	err := r.db.Where("id = ?", id).First(&book).Error
+	err := r.db.Preload("Authors").Preload("Categories").Where("id = ?", id).First(&book).Error
+	return book, err
+}
+
+func (r *bookRepository) GetByISBN(isbn string) (model.Book, error) {
+	var book model.Book
+	err := r.db.Where("isbn = ?", isbn).First(&book).Error
 	return book, err
 }
 
+// Each walks the whole catalog in order of ID, batchSize books at a time with their
+// authors and categories, and stops at the first error fn returns.
+func (r *bookRepository) Each(batchSize int, fn func(books []model.Book) error) error {
+	var books []model.Book
+	return r.db.Preload("Authors").Preload("Categories").
+		FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(books)
+		}).Error
+}
+
+func (r *bookRepository) GetByIDForUpdate(id uint) (model.Book, error) {
+	var book model.Book
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&book).Error
+	return book, err
+}
+
+// SyncStock recounts the cached stock of a book from its available copies.
+func (r *bookRepository) SyncStock(id uint) error {
+	available := r.db.Model(&model.BookCopy{}).
+		Select("count(*)").
+		Where("book_id = ? AND status = ?", id, model.BookCopyStatusAvailable)
+	return r.db.Model(&model.Book{}).Where("id = ?", id).Update("stok", available).Error
+}
+
 func (r *bookRepository) Delete(id uint) error {
 	err := r.db.Where("id = ?", id).Delete(&model.Book{}).Error
 	if err != nil {
@@ -45,20 +218,36 @@ func (r *bookRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Book{}, id).Error
 }
 
+// Update replaces the details, authors and categories of a book. Its stock follows the copies and
+// is left alone.
 func (r *bookRepository) Update(book model.Book, id uint) (model.Book, error) {
 	var b model.Book
-	if err := r.db.First(&b, id).Error; err != nil {
-		return b, err
-	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&b, id).Error; err != nil {
+			return err
+		}
 
-	b.Name = book.Name
-	b.Stok = book.Stok
-	b.RentalCost = book.RentalCost
-	b.Category = book.Category
-	
-	if err := r.db.Save(&b).Error; err != nil {
-		return b, err
-	}
+		b.Name = book.Name
+		b.RentalCost = book.RentalCost
+		b.Category = book.Category
+		b.ISBN = book.ISBN
+		b.Publisher = book.Publisher
+		b.PublicationYear = book.PublicationYear
+		b.Language = book.Language
+		b.PageCount = book.PageCount
+		b.Synopsis = book.Synopsis
+		b.CoverURL = book.CoverURL
 
-	return b, nil
+		if err := tx.Omit(clause.Associations).Save(&b).Error; err != nil {
+			return err
+		}
+		if err := replaceAuthors(tx, &b, book.Authors); err != nil {
+			return err
+		}
+		if err := replaceCategories(tx, &b, book.Categories); err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, b.ID)
+	})
+	return b, err
 }
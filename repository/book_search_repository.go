@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+// bookTSQuery matches the search words as written and stemmed as English, like the
+// documents built by model.BookSearchVectorSQL. It expects the search as @q.
+const bookTSQuery = "(websearch_to_tsquery('simple', @q) || websearch_to_tsquery('english', @q))"
+
+// bookSnippetText is the text a snippet is made of, HTML-escaped so that the only
+// markup in a snippet is the <mark> around the matching words.
+const bookSnippetText = "replace(replace(replace(name || ' - ' || category, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+
+// BookSearchHit is a book found by a search, how well it matched, and an HTML snippet
+// of it with the matching words wrapped in <mark>.
+type BookSearchHit struct {
+	ID         uint
+	Name       string
+	Stok       int
+	RentalCost int
+	Category   string
+	Rank       float64
+	Snippet    string
+}
+
+type BookSearchRepository interface {
+	FullText(query string, page int, limit int) ([]BookSearchHit, int64, error)
+	Similar(query string, page int, limit int) ([]BookSearchHit, int64, error)
+}
+
+type bookSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewBookSearchRepository(db *gorm.DB) BookSearchRepository {
+	return &bookSearchRepository{db}
+}
+
+// FullText returns one page of the books whose full-text document matches query, best
+// ranked first, and the total number of matches.
+func (r *bookSearchRepository) FullText(query string, page int, limit int) ([]BookSearchHit, int64, error) {
+	q := sql.Named("q", query)
+	matches := r.db.Model(&model.Book{}).Where("search_vector @@ "+bookTSQuery, q)
+
+	var total int64
+	if err := matches.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []BookSearchHit
+	err := matches.
+		Select("id, name, stok, rental_cost, category, "+
+			"ts_rank(search_vector, "+bookTSQuery+") AS rank, "+
+			"ts_headline('english', "+bookSnippetText+", "+bookTSQuery+", 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet", q).
+		Order("rank DESC").Order("id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&hits).Error
+	return hits, total, err
+}
+
+// Similar returns one page of the books with a name close to query, for searches with
+// typos that match nothing word for word. Names are compared by trigram word similarity,
+// closest first.
+func (r *bookSearchRepository) Similar(query string, page int, limit int) ([]BookSearchHit, int64, error) {
+	q := sql.Named("q", query)
+	matches := r.db.Model(&model.Book{}).Where("@q <% name", q)
+
+	var total int64
+	if err := matches.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []BookSearchHit
+	err := matches.
+		Select("id, name, stok, rental_cost, category, word_similarity(@q, name) AS rank, "+bookSnippetText+" AS snippet", q).
+		Order("rank DESC").Order("id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&hits).Error
+	return hits, total, err
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+)
+
+// CategoryCount is a category with the number of books filed under it.
+type CategoryCount struct {
+	ID        uint
+	Slug      string
+	Name      string
+	ParentID  *uint
+	BookCount int64
+}
+
+type CategoryRepository interface {
+	Create(category model.Category) (model.Category, error)
+	GetByID(id uint) (model.Category, error)
+	GetBySlug(slug string) (model.Category, error)
+	GetByName(name string) (model.Category, error)
+	GetByIDs(ids []uint) ([]model.Category, error)
+	GetTreeOfBook(bookID uint) ([]model.Category, error)
+	Update(category model.Category) (model.Category, error)
+	Delete(id uint) error
+	ListWithCounts() ([]CategoryCount, error)
+	CountBooks(id uint) (int64, error)
+	Rename(from model.Category, to model.Category) error
+	MoveBooks(fromID uint, to model.Category) error
+	Reparent(fromID uint, toID *uint) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db}
+}
+
+func (r *categoryRepository) Create(category model.Category) (model.Category, error) {
+	err := r.db.Omit(clause.Associations).Create(&category).Error
+	return category, err
+}
+
+func (r *categoryRepository) GetByID(id uint) (model.Category, error) {
+	var category model.Category
+	err := r.db.Where("id = ?", id).First(&category).Error
+	return category, err
+}
+
+func (r *categoryRepository) GetBySlug(slug string) (model.Category, error) {
+	var category model.Category
+	err := r.db.Where("slug = ?", slug).First(&category).Error
+	return category, err
+}
+
+// GetTreeOfBook returns the categories a book is filed under together with every
+// category above them.
+func (r *categoryRepository) GetTreeOfBook(bookID uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Raw("WITH RECURSIVE tree AS ("+
+		"SELECT categories.* FROM categories JOIN book_categories ON book_categories.category_id = categories.id "+
+		"WHERE book_categories.book_id = ? AND categories.deleted_at IS NULL "+
+		"UNION SELECT categories.* FROM categories JOIN tree ON categories.id = tree.parent_id WHERE categories.deleted_at IS NULL"+
+		") SELECT * FROM tree ORDER BY id", bookID).
+		Scan(&categories).Error
+	return categories, err
+}
+
+// GetByName finds a category by its name, ignoring case.
+func (r *categoryRepository) GetByName(name string) (model.Category, error) {
+	var category model.Category
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&category).Error
+	return category, err
+}
+
+// GetByIDs returns the categories with the given IDs, in no particular order. IDs
+// without a category are left out.
+func (r *categoryRepository) GetByIDs(ids []uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) Update(category model.Category) (model.Category, error) {
+	err := r.db.Omit(clause.Associations).Save(&category).Error
+	return category, err
+}
+
+// Delete removes a category for good, so its slug can be used again.
+func (r *categoryRepository) Delete(id uint) error {
+	if err := r.db.Exec("DELETE FROM book_categories WHERE category_id = ?", id).Error; err != nil {
+		return err
+	}
+	return r.db.Unscoped().Delete(&model.Category{}, id).Error
+}
+
+// ListWithCounts returns every category, by name, with the number of books filed
+// directly under it.
+func (r *categoryRepository) ListWithCounts() ([]CategoryCount, error) {
+	var categories []CategoryCount
+	err := r.db.Model(&model.Category{}).
+		Select("categories.id, categories.slug, categories.name, categories.parent_id, COUNT(books.id) AS book_count").
+		Joins("LEFT JOIN book_categories ON book_categories.category_id = categories.id").
+		Joins("LEFT JOIN books ON books.id = book_categories.book_id AND books.deleted_at IS NULL").
+		Group("categories.id").
+		Order("categories.name").
+		Scan(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) CountBooks(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Book{}).
+		Where("id IN (SELECT book_id FROM book_categories WHERE category_id = ?)", id).
+		Count(&count).Error
+	return count, err
+}
+
+// Rename carries the name of to over to the books and pricing plans that match on the
+// name of from, and to the vouchers that name from by its name or slug.
+func (r *categoryRepository) Rename(from model.Category, to model.Category) error {
+	if from.Name != to.Name {
+		err := r.db.Exec("UPDATE books SET category = ? WHERE category = ?", to.Name, from.Name).Error
+		if err != nil {
+			return err
+		}
+		err = r.db.Exec("UPDATE books SET search_vector = "+model.BookSearchVectorSQL+" WHERE category = ?", to.Name).Error
+		if err != nil {
+			return err
+		}
+		err = r.db.Exec("UPDATE pricing_plans SET category = ? WHERE category = ?", to.Name, from.Name).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var vouchers []model.Voucher
+	if err := r.db.Where("categories <> ''").Find(&vouchers).Error; err != nil {
+		return err
+	}
+	for _, voucher := range vouchers {
+		if !voucher.RenameCategory(from, to) {
+			continue
+		}
+		if err := r.db.Model(&voucher).Update("categories", voucher.Categories).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveBooks files the books of one category under another, which becomes the primary
+// category of the books that had the first one as theirs.
+func (r *categoryRepository) MoveBooks(fromID uint, to model.Category) error {
+	var from model.Category
+	if err := r.db.Where("id = ?", fromID).First(&from).Error; err != nil {
+		return err
+	}
+	err := r.db.Exec("INSERT INTO book_categories (book_id, category_id) "+
+		"SELECT book_id, ? FROM book_categories WHERE category_id = ? ON CONFLICT DO NOTHING", to.ID, fromID).Error
+	if err != nil {
+		return err
+	}
+	if err := r.db.Exec("DELETE FROM book_categories WHERE category_id = ?", fromID).Error; err != nil {
+		return err
+	}
+	return r.Rename(from, to)
+}
+
+// Reparent moves the children of a category under another parent, or to the top when
+// toID is nil.
+func (r *categoryRepository) Reparent(fromID uint, toID *uint) error {
+	return r.db.Model(&model.Category{}).Where("parent_id = ?", fromID).Update("parent_id", toID).Error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+type CheckoutRepository interface {
+	Create(checkout model.Checkout) (model.Checkout, error)
+}
+
+type checkoutRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckoutRepository(db *gorm.DB) CheckoutRepository {
+	return &checkoutRepository{db}
+}
+
+func (r *checkoutRepository) Create(checkout model.Checkout) (model.Checkout, error) {
+	err := r.db.Omit("Rentals").Create(&checkout).Error
+	return checkout, err
+}
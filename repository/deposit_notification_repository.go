@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+type DepositNotificationRepository interface {
+	Create(notification model.DepositNotification) (model.DepositNotification, error)
+	GetByOrderID(orderID string) ([]model.DepositNotification, error)
+}
+
+type depositNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewDepositNotificationRepository(db *gorm.DB) DepositNotificationRepository {
+	return &depositNotificationRepository{db}
+}
+
+func (r *depositNotificationRepository) Create(notification model.DepositNotification) (model.DepositNotification, error) {
+	err := r.db.Create(&notification).Error
+	return notification, err
+}
+
+func (r *depositNotificationRepository) GetByOrderID(orderID string) ([]model.DepositNotification, error) {
+	var notifications []model.DepositNotification
+	err := r.db.Where("order_id = ?", orderID).Order("id").Find(&notifications).Error
+	return notifications, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"pojok-baca-api/model"
 	"time"
 )
@@ -10,6 +11,10 @@ type DepositTransactionRepository interface {
 	Create(deposit *model.DepositTransaction) (model.DepositTransaction, error)
 	UpdateStatus(orderID string, status string, paidAt *time.Time) error
 	GetByOrderID(orderID string) (model.DepositTransaction, error)
+	GetByOrderIDForUpdate(orderID string) (model.DepositTransaction, error)
+	UpdatePaymentRef(orderID string, paymentRef string) error
+	ListByUserID(userID uint, status string, page int, limit int) ([]model.DepositTransaction, int64, error)
+	GetPendingBefore(cutoff time.Time) ([]model.DepositTransaction, error)
 }
 
 type depositTransactionRepository struct {
@@ -36,3 +41,46 @@ func (r *depositTransactionRepository) GetByOrderID(orderID string) (model.Depos
 	err := r.db.Where("order_id = ?", orderID).First(&tx).Error
 	return tx, err
 }
+
+func (r *depositTransactionRepository) GetByOrderIDForUpdate(orderID string) (model.DepositTransaction, error) {
+	var tx model.DepositTransaction
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&tx).Error
+	return tx, err
+}
+
+func (r *depositTransactionRepository) UpdatePaymentRef(orderID string, paymentRef string) error {
+	return r.db.Model(&model.DepositTransaction{}).
+		Where("order_id = ?", orderID).
+		Update("payment_ref", paymentRef).Error
+}
+
+// ListByUserID returns one page of the deposits of a user, newest first, optionally
+// only those in status, together with the total number of matching deposits.
+func (r *depositTransactionRepository) ListByUserID(userID uint, status string, page int, limit int) ([]model.DepositTransaction, int64, error) {
+	query := r.db.Model(&model.DepositTransaction{}).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deposits []model.DepositTransaction
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deposits).Error
+	return deposits, total, err
+}
+
+// GetPendingBefore returns the deposits still pending that were created before cutoff,
+// oldest first.
+func (r *depositTransactionRepository) GetPendingBefore(cutoff time.Time) ([]model.DepositTransaction, error) {
+	var deposits []model.DepositTransaction
+	err := r.db.Where("status = ? AND created_at < ?", model.DepositStatusPending, cutoff).
+		Order("created_at, id").
+		Find(&deposits).Error
+	return deposits, err
+}
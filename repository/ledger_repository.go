@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+// WalletEntry is a wallet ledger entry with the balance right after it.
+type WalletEntry struct {
+	model.LedgerEntry
+	RunningBalance int
+}
+
+// WalletMismatch is a user whose cached deposit differs from their ledger balance.
+type WalletMismatch struct {
+	UserID        uint
+	Deposit       *int
+	LedgerBalance int
+}
+
+type LedgerRepository interface {
+	Create(entries []model.LedgerEntry) error
+	Balance(userID uint) (int, error)
+	GetWalletEntries(userID uint, page int, limit int) ([]WalletEntry, int64, error)
+	GetMismatchedWallets() ([]WalletMismatch, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db}
+}
+
+func (r *ledgerRepository) Create(entries []model.LedgerEntry) error {
+	return r.db.Create(&entries).Error
+}
+
+func (r *ledgerRepository) Balance(userID uint) (int, error) {
+	var balance int
+	err := r.db.Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND account = ?", userID, model.LedgerAccountWallet).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetWalletEntries returns one page of a user's wallet entries, newest first, each with
+// the balance after it.
+func (r *ledgerRepository) GetWalletEntries(userID uint, page int, limit int) ([]WalletEntry, int64, error) {
+	wallet := r.db.Model(&model.LedgerEntry{}).
+		Where("user_id = ? AND account = ?", userID, model.LedgerAccountWallet)
+
+	var total int64
+	if err := wallet.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	withBalance := r.db.Model(&model.LedgerEntry{}).
+		Select("ledger_entries.*, SUM(amount) OVER (ORDER BY id) AS running_balance").
+		Where("user_id = ? AND account = ?", userID, model.LedgerAccountWallet)
+
+	var entries []WalletEntry
+	err := r.db.Table("(?) AS wallet", withBalance).
+		Order("id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+func (r *ledgerRepository) GetMismatchedWallets() ([]WalletMismatch, error) {
+	var mismatches []WalletMismatch
+	err := r.db.Table("users").
+		Select("users.id AS user_id, users.deposit, COALESCE(SUM(ledger_entries.amount), 0) AS ledger_balance").
+		Joins("LEFT JOIN ledger_entries ON ledger_entries.user_id = users.id AND ledger_entries.account = ? AND ledger_entries.deleted_at IS NULL", model.LedgerAccountWallet).
+		Where("users.deleted_at IS NULL").
+		Group("users.id, users.deposit").
+		Having("COALESCE(users.deposit, 0) <> COALESCE(SUM(ledger_entries.amount), 0)").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
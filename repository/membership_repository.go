@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+	"time"
+)
+
+type MembershipRepository interface {
+	CreatePlan(plan model.MembershipPlan) (model.MembershipPlan, error)
+	GetPlanByID(id uint) (model.MembershipPlan, error)
+	ListPlans() ([]model.MembershipPlan, error)
+	Create(membership model.Membership) (model.Membership, error)
+	Update(membership model.Membership) (model.Membership, error)
+	GetByIDForUpdate(id uint) (model.Membership, error)
+	GetActiveByUserID(userID uint, now time.Time) (model.Membership, error)
+	GetActiveByUserIDForUpdate(userID uint, now time.Time) (model.Membership, error)
+	ListActiveByUserIDForUpdate(userID uint) ([]model.Membership, error)
+	GetDue(now time.Time) ([]model.Membership, error)
+}
+
+type membershipRepository struct {
+	db *gorm.DB
+}
+
+func NewMembershipRepository(db *gorm.DB) MembershipRepository {
+	return &membershipRepository{db}
+}
+
+func (r *membershipRepository) CreatePlan(plan model.MembershipPlan) (model.MembershipPlan, error) {
+	err := r.db.Create(&plan).Error
+	return plan, err
+}
+
+func (r *membershipRepository) GetPlanByID(id uint) (model.MembershipPlan, error) {
+	var plan model.MembershipPlan
+	err := r.db.Where("id = ?", id).First(&plan).Error
+	return plan, err
+}
+
+// ListPlans returns the plans that can be bought, cheapest first.
+func (r *membershipRepository) ListPlans() ([]model.MembershipPlan, error) {
+	var plans []model.MembershipPlan
+	err := r.db.Where("active = ?", true).Order("price ASC, id ASC").Find(&plans).Error
+	return plans, err
+}
+
+func (r *membershipRepository) Create(membership model.Membership) (model.Membership, error) {
+	err := r.db.Omit(clause.Associations).Create(&membership).Error
+	return membership, err
+}
+
+func (r *membershipRepository) Update(membership model.Membership) (model.Membership, error) {
+	err := r.db.Omit(clause.Associations).Save(&membership).Error
+	return membership, err
+}
+
+func (r *membershipRepository) GetByIDForUpdate(id uint) (model.Membership, error) {
+	var membership model.Membership
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&membership).Error
+	return membership, err
+}
+
+// GetActiveByUserID returns the running period of a user with its plan.
+func (r *membershipRepository) GetActiveByUserID(userID uint, now time.Time) (model.Membership, error) {
+	var membership model.Membership
+	err := r.db.Preload("Plan").
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, model.MembershipStatusActive, now).
+		First(&membership).Error
+	return membership, err
+}
+
+func (r *membershipRepository) GetActiveByUserIDForUpdate(userID uint, now time.Time) (model.Membership, error) {
+	var membership model.Membership
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, model.MembershipStatusActive, now).
+		First(&membership).Error
+	return membership, err
+}
+
+// ListActiveByUserIDForUpdate locks every period of a user still marked active,
+// including the ones that ran out but were not renewed or expired yet.
+func (r *membershipRepository) ListActiveByUserIDForUpdate(userID uint) ([]model.Membership, error) {
+	var memberships []model.Membership
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ?", userID, model.MembershipStatusActive).
+		Order("expires_at ASC").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// GetDue returns the active periods that have run out by now, to be renewed or expired.
+func (r *membershipRepository) GetDue(now time.Time) ([]model.Membership, error) {
+	var memberships []model.Membership
+	err := r.db.Where("status = ? AND expires_at <= ?", model.MembershipStatusActive, now).
+		Order("expires_at ASC").
+		Find(&memberships).Error
+	return memberships, err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+type PricingPlanRepository interface {
+	Create(plan model.PricingPlan) (model.PricingPlan, error)
+	GetByID(id uint) (model.PricingPlan, error)
+	GetForBookOrCategories(bookID uint) ([]model.PricingPlan, error)
+	Delete(id uint) error
+}
+
+type pricingPlanRepository struct {
+	db *gorm.DB
+}
+
+func NewPricingPlanRepository(db *gorm.DB) PricingPlanRepository {
+	return &pricingPlanRepository{db}
+}
+
+func (r *pricingPlanRepository) Create(plan model.PricingPlan) (model.PricingPlan, error) {
+	err := r.db.Create(&plan).Error
+	return plan, err
+}
+
+func (r *pricingPlanRepository) GetByID(id uint) (model.PricingPlan, error) {
+	var plan model.PricingPlan
+	err := r.db.Where("id = ?", id).First(&plan).Error
+	return plan, err
+}
+
+// GetForBookOrCategories returns the plans of the book itself and every category plan,
+// shortest first. Which category plans apply to the book is up to PricingPlan.AppliesTo.
+func (r *pricingPlanRepository) GetForBookOrCategories(bookID uint) ([]model.PricingPlan, error) {
+	var plans []model.PricingPlan
+	err := r.db.Where("book_id = ? OR book_id IS NULL", bookID).
+		Order("duration_days ASC, price ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+func (r *pricingPlanRepository) Delete(id uint) error {
+	return r.db.Delete(&model.PricingPlan{}, id).Error
+}
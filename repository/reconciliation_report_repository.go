@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"pojok-baca-api/model"
+)
+
+type ReconciliationReportRepository interface {
+	Create(report model.ReconciliationReport) (model.ReconciliationReport, error)
+}
+
+type reconciliationReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationReportRepository(db *gorm.DB) ReconciliationReportRepository {
+	return &reconciliationReportRepository{db}
+}
+
+func (r *reconciliationReportRepository) Create(report model.ReconciliationReport) (model.ReconciliationReport, error) {
+	err := r.db.Create(&report).Error
+	return report, err
+}
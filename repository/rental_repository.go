@@ -2,12 +2,46 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"pojok-baca-api/model"
+	"strings"
+	"time"
 )
 
+// RentalFilter narrows and orders the rental list. Zero values mean no filter.
+// Sort is a column name, prefixed with "-" for descending order.
+type RentalFilter struct {
+	Status      string
+	UserID      uint
+	BookID      uint
+	From        *time.Time
+	To          *time.Time
+	OverdueOnly bool
+	Sort        string
+	Page        int
+	Limit       int
+}
+
+// RentalSortColumns whitelists the columns the rental list can be sorted by.
+var RentalSortColumns = map[string]string{
+	"id":          "id",
+	"rent_date":   "rent_date",
+	"return_date": "return_date",
+	"status":      "status",
+	"price":       "price",
+}
+
 type RentalRepository interface {
 	Create(rental model.Rental) (model.Rental, error)
+	GetByID(id uint) (model.Rental, error)
+	GetByIDForUpdate(id uint) (model.Rental, error)
 	GetByUserID(userID uint) ([]model.Rental, error)
+	GetActiveByBookCopyID(bookCopyID uint) (model.Rental, error)
+	Update(rental model.Rental) (model.Rental, error)
+	CountDiscountedByCheckoutID(checkoutID uint) (int64, error)
+	MarkOverdue(now time.Time) (int64, error)
+	List(filter RentalFilter, now time.Time) ([]model.Rental, int64, error)
+	CreateEvent(event model.RentalEvent) (model.RentalEvent, error)
 }
 
 type rentalRepository struct {
@@ -23,8 +57,106 @@ func (r *rentalRepository) Create(rental model.Rental) (model.Rental, error) {
 	return rental, err
 }
 
+func (r *rentalRepository) GetByID(id uint) (model.Rental, error) {
+	var rental model.Rental
+	err := r.db.Preload("Book").Where("id = ?", id).First(&rental).Error
+	return rental, err
+}
+
+func (r *rentalRepository) GetByIDForUpdate(id uint) (model.Rental, error) {
+	var rental model.Rental
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&rental).Error
+	return rental, err
+}
+
 func (r *rentalRepository) GetByUserID(userID uint) ([]model.Rental, error) {
 	var rentals []model.Rental
-	err := r.db.Preload("Book").Where("user_id = ?", userID).Find(&rentals).Error
+	err := r.db.Preload("Book").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).
+		Find(&rentals).Error
 	return rentals, err
 }
+
+// GetActiveByBookCopyID returns the borrowed or overdue rental a copy is out on.
+func (r *rentalRepository) GetActiveByBookCopyID(bookCopyID uint) (model.Rental, error) {
+	var rental model.Rental
+	err := r.db.Preload("User").
+		Where("book_copy_id = ? AND status IN ?", bookCopyID,
+			[]string{model.RentalStatusBorrowed, model.RentalStatusOverdue}).
+		First(&rental).Error
+	return rental, err
+}
+
+func (r *rentalRepository) Update(rental model.Rental) (model.Rental, error) {
+	err := r.db.Omit(clause.Associations).Save(&rental).Error
+	return rental, err
+}
+
+// CountDiscountedByCheckoutID counts the rentals of a checkout that keep a voucher
+// discount, that is the discounted ones not cancelled.
+func (r *rentalRepository) CountDiscountedByCheckoutID(checkoutID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Rental{}).
+		Where("checkout_id = ? AND discount > 0 AND status <> ?", checkoutID, model.RentalStatusCancelled).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *rentalRepository) MarkOverdue(now time.Time) (int64, error) {
+	res := r.db.Model(&model.Rental{}).
+		Where("status = ? AND return_date < ?", model.RentalStatusBorrowed, now).
+		Update("status", model.RentalStatusOverdue)
+	return res.RowsAffected, res.Error
+}
+
+// List returns one page of rentals matching the filter and the total number of matches.
+// A rental counts as overdue when it is marked so, or still borrowed past its due date.
+func (r *rentalRepository) List(filter RentalFilter, now time.Time) ([]model.Rental, int64, error) {
+	query := r.db.Model(&model.Rental{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.BookID != 0 {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	if filter.From != nil {
+		query = query.Where("rent_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("rent_date < ?", *filter.To)
+	}
+	if filter.OverdueOnly {
+		query = query.Where("status = ? OR (status = ? AND return_date < ?)",
+			model.RentalStatusOverdue, model.RentalStatusBorrowed, now)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id DESC"
+	if column, ok := RentalSortColumns[strings.TrimPrefix(filter.Sort, "-")]; ok {
+		order = column
+		if strings.HasPrefix(filter.Sort, "-") {
+			order += " DESC"
+		}
+	}
+
+	var rentals []model.Rental
+	err := query.Preload("User").Preload("Book").
+		Order(order).
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&rentals).Error
+	return rentals, total, err
+}
+
+func (r *rentalRepository) CreateEvent(event model.RentalEvent) (model.RentalEvent, error) {
+	err := r.db.Create(&event).Error
+	return event, err
+}
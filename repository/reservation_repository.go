@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+	"time"
+)
+
+type ReservationRepository interface {
+	Create(reservation model.Reservation) (model.Reservation, error)
+	GetByID(id uint) (model.Reservation, error)
+	GetByIDForUpdate(id uint) (model.Reservation, error)
+	GetByUserID(userID uint) ([]model.Reservation, error)
+	GetActiveForUpdate(userID uint, bookID uint) (model.Reservation, error)
+	GetNextWaitingForUpdate(bookID uint) (model.Reservation, error)
+	CountWaiting(bookID uint) (int64, error)
+	QueuePosition(reservation model.Reservation) (int64, error)
+	GetExpiredHolds(now time.Time) ([]model.Reservation, error)
+	Update(reservation model.Reservation) (model.Reservation, error)
+}
+
+type reservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) ReservationRepository {
+	return &reservationRepository{db}
+}
+
+func (r *reservationRepository) Create(reservation model.Reservation) (model.Reservation, error) {
+	err := r.db.Create(&reservation).Error
+	return reservation, err
+}
+
+func (r *reservationRepository) GetByID(id uint) (model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.db.Where("id = ?", id).First(&reservation).Error
+	return reservation, err
+}
+
+func (r *reservationRepository) GetByIDForUpdate(id uint) (model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&reservation).Error
+	return reservation, err
+}
+
+func (r *reservationRepository) GetByUserID(userID uint) ([]model.Reservation, error) {
+	var reservations []model.Reservation
+	err := r.db.Preload("Book").Where("user_id = ?", userID).Order("id DESC").Find(&reservations).Error
+	return reservations, err
+}
+
+// GetActiveForUpdate returns the waiting or ready reservation a user holds on a book.
+func (r *reservationRepository) GetActiveForUpdate(userID uint, bookID uint) (model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND book_id = ? AND status IN ?", userID, bookID,
+			[]string{model.ReservationStatusWaiting, model.ReservationStatusReady}).
+		First(&reservation).Error
+	return reservation, err
+}
+
+// GetNextWaitingForUpdate returns the oldest waiting reservation of a book.
+func (r *reservationRepository) GetNextWaitingForUpdate(bookID uint) (model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, model.ReservationStatusWaiting).
+		Order("id ASC").
+		First(&reservation).Error
+	return reservation, err
+}
+
+func (r *reservationRepository) CountWaiting(bookID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Reservation{}).
+		Where("book_id = ? AND status = ?", bookID, model.ReservationStatusWaiting).
+		Count(&count).Error
+	return count, err
+}
+
+// QueuePosition returns the 1-based place of a waiting reservation in its book queue.
+func (r *reservationRepository) QueuePosition(reservation model.Reservation) (int64, error) {
+	var ahead int64
+	err := r.db.Model(&model.Reservation{}).
+		Where("book_id = ? AND status = ? AND id < ?", reservation.BookID, model.ReservationStatusWaiting, reservation.ID).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
+
+func (r *reservationRepository) GetExpiredHolds(now time.Time) ([]model.Reservation, error) {
+	var reservations []model.Reservation
+	err := r.db.Where("status = ? AND hold_expires_at < ?", model.ReservationStatusReady, now).
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *reservationRepository) Update(reservation model.Reservation) (model.Reservation, error) {
+	err := r.db.Omit(clause.Associations).Save(&reservation).Error
+	return reservation, err
+}
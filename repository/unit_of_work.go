@@ -0,0 +1,58 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories groups the repositories bound to a single database transaction.
+type Repositories struct {
+	User         UserRepository
+	Book         BookRepository
+	Rental       RentalRepository
+	Reservation  ReservationRepository
+	Checkout     CheckoutRepository
+	PricingPlan  PricingPlanRepository
+	BookCopy     BookCopyRepository
+	Ledger       LedgerRepository
+	Deposit      DepositTransactionRepository
+	Notification DepositNotificationRepository
+	Withdrawal   WithdrawalRepository
+	Adjustment   BalanceAdjustmentRepository
+	Voucher      VoucherRepository
+	Membership   MembershipRepository
+	Category     CategoryRepository
+}
+
+// UnitOfWork runs fn inside one database transaction. The transaction is committed
+// when fn returns nil and rolled back otherwise.
+type UnitOfWork interface {
+	Do(fn func(repos Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db}
+}
+
+func (u *unitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			User:         NewUserRepository(tx),
+			Book:         NewBookRepository(tx),
+			Rental:       NewRentalRepository(tx),
+			Reservation:  NewReservationRepository(tx),
+			Checkout:     NewCheckoutRepository(tx),
+			PricingPlan:  NewPricingPlanRepository(tx),
+			BookCopy:     NewBookCopyRepository(tx),
+			Ledger:       NewLedgerRepository(tx),
+			Deposit:      NewDepositTransactionRepository(tx),
+			Notification: NewDepositNotificationRepository(tx),
+			Withdrawal:   NewWithdrawalRepository(tx),
+			Adjustment:   NewBalanceAdjustmentRepository(tx),
+			Voucher:      NewVoucherRepository(tx),
+			Membership:   NewMembershipRepository(tx),
+			Category:     NewCategoryRepository(tx),
+		})
+	})
+}
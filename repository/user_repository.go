@@ -2,6 +2,7 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"pojok-baca-api/model"
 )
 
@@ -9,6 +10,7 @@ type UserRepository interface {
 	Create(user model.User) (model.User, error)
 	GetByEmail(email string) (model.User, error)
 	GetByID(id uint) (model.User, error)
+	GetByIDForUpdate(id uint) (model.User, error)
 	UpdateDeposit(saldo int, id uint) (model.User, error)
 }
 
@@ -37,6 +39,12 @@ func (r *userRepository) GetByID(id uint) (model.User, error) {
 	return user, err
 }
 
+func (r *userRepository) GetByIDForUpdate(id uint) (model.User, error) {
+	var user model.User
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&user).Error
+	return user, err
+}
+
 func (r *userRepository) UpdateDeposit(saldo int, id uint) (model.User, error) {
 
 	//Find User
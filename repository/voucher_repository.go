@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+)
+
+type VoucherRepository interface {
+	Create(voucher model.Voucher) (model.Voucher, error)
+	GetByID(id uint) (model.Voucher, error)
+	GetByCode(code string) (model.Voucher, error)
+	GetByCodeForUpdate(code string) (model.Voucher, error)
+	Update(voucher model.Voucher) (model.Voucher, error)
+	List(page int, limit int) ([]model.Voucher, int64, error)
+	CreateRedemption(redemption model.VoucherRedemption) (model.VoucherRedemption, error)
+	CountRedemptions(voucherID uint, userID uint) (int64, error)
+	UpdateRedemptionStatus(depositOrderID string, status string) error
+	GetCheckoutRedemptionForUpdate(checkoutID uint) (model.VoucherRedemption, error)
+	UpdateRedemption(redemption model.VoucherRedemption) (model.VoucherRedemption, error)
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db}
+}
+
+func (r *voucherRepository) Create(voucher model.Voucher) (model.Voucher, error) {
+	err := r.db.Create(&voucher).Error
+	return voucher, err
+}
+
+func (r *voucherRepository) GetByID(id uint) (model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("id = ?", id).First(&voucher).Error
+	return voucher, err
+}
+
+func (r *voucherRepository) GetByCode(code string) (model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("code = ?", code).First(&voucher).Error
+	return voucher, err
+}
+
+func (r *voucherRepository) GetByCodeForUpdate(code string) (model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&voucher).Error
+	return voucher, err
+}
+
+func (r *voucherRepository) Update(voucher model.Voucher) (model.Voucher, error) {
+	err := r.db.Save(&voucher).Error
+	return voucher, err
+}
+
+// List returns one page of vouchers, newest first, with the total number of vouchers.
+func (r *voucherRepository) List(page int, limit int) ([]model.Voucher, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Voucher{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var vouchers []model.Voucher
+	err := r.db.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&vouchers).Error
+	return vouchers, total, err
+}
+
+func (r *voucherRepository) CreateRedemption(redemption model.VoucherRedemption) (model.VoucherRedemption, error) {
+	err := r.db.Create(&redemption).Error
+	return redemption, err
+}
+
+// CountRedemptions counts the pending and applied redemptions of a voucher, by one
+// user when userID is set.
+func (r *voucherRepository) CountRedemptions(voucherID uint, userID uint) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.VoucherRedemption{}).
+		Where("voucher_id = ? AND status IN ?", voucherID, []string{model.RedemptionStatusPending, model.RedemptionStatusApplied})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// UpdateRedemptionStatus moves the pending redemption of a deposit order to status.
+func (r *voucherRepository) UpdateRedemptionStatus(depositOrderID string, status string) error {
+	return r.db.Model(&model.VoucherRedemption{}).
+		Where("deposit_order_id = ? AND status = ?", depositOrderID, model.RedemptionStatusPending).
+		Update("status", status).Error
+}
+
+// GetCheckoutRedemptionForUpdate returns the applied redemption of a checkout, locked.
+func (r *voucherRepository) GetCheckoutRedemptionForUpdate(checkoutID uint) (model.VoucherRedemption, error) {
+	var redemption model.VoucherRedemption
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("checkout_id = ? AND status = ?", checkoutID, model.RedemptionStatusApplied).
+		First(&redemption).Error
+	return redemption, err
+}
+
+func (r *voucherRepository) UpdateRedemption(redemption model.VoucherRedemption) (model.VoucherRedemption, error) {
+	err := r.db.Save(&redemption).Error
+	return redemption, err
+}
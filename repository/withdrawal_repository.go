@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pojok-baca-api/model"
+)
+
+type WithdrawalRepository interface {
+	Create(withdrawal model.Withdrawal) (model.Withdrawal, error)
+	GetByID(id uint) (model.Withdrawal, error)
+	GetByIDForUpdate(id uint) (model.Withdrawal, error)
+	Update(withdrawal model.Withdrawal) (model.Withdrawal, error)
+	CreateEvent(event model.WithdrawalEvent) (model.WithdrawalEvent, error)
+	CountOpenByUserID(userID uint) (int64, error)
+	List(userID uint, status string, page int, limit int) ([]model.Withdrawal, int64, error)
+}
+
+type withdrawalRepository struct {
+	db *gorm.DB
+}
+
+func NewWithdrawalRepository(db *gorm.DB) WithdrawalRepository {
+	return &withdrawalRepository{db}
+}
+
+func (r *withdrawalRepository) Create(withdrawal model.Withdrawal) (model.Withdrawal, error) {
+	err := r.db.Omit(clause.Associations).Create(&withdrawal).Error
+	return withdrawal, err
+}
+
+func (r *withdrawalRepository) GetByID(id uint) (model.Withdrawal, error) {
+	var withdrawal model.Withdrawal
+	err := r.db.Preload("User").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("id = ?", id).
+		First(&withdrawal).Error
+	return withdrawal, err
+}
+
+func (r *withdrawalRepository) GetByIDForUpdate(id uint) (model.Withdrawal, error) {
+	var withdrawal model.Withdrawal
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&withdrawal).Error
+	return withdrawal, err
+}
+
+func (r *withdrawalRepository) Update(withdrawal model.Withdrawal) (model.Withdrawal, error) {
+	err := r.db.Omit(clause.Associations).Save(&withdrawal).Error
+	return withdrawal, err
+}
+
+func (r *withdrawalRepository) CreateEvent(event model.WithdrawalEvent) (model.WithdrawalEvent, error) {
+	err := r.db.Create(&event).Error
+	return event, err
+}
+
+// CountOpenByUserID counts the withdrawals of a user that are still pending or approved.
+func (r *withdrawalRepository) CountOpenByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Withdrawal{}).
+		Where("user_id = ? AND status IN ?", userID, []string{model.WithdrawalStatusPending, model.WithdrawalStatusApproved}).
+		Count(&count).Error
+	return count, err
+}
+
+// List returns one page of withdrawals, newest first, with the total number that
+// match. A zero userID or an empty status does not filter on it.
+func (r *withdrawalRepository) List(userID uint, status string, page int, limit int) ([]model.Withdrawal, int64, error) {
+	query := r.db.Model(&model.Withdrawal{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var withdrawals []model.Withdrawal
+	err := query.Preload("User").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&withdrawals).Error
+	return withdrawals, total, err
+}
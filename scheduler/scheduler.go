@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs job once per interval until ctx is cancelled. A failing run is logged
+// and the schedule carries on.
+func Every(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.Printf("Job %s failed: %v", name, err)
+			}
+		}
+	}
+}
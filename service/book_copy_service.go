@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookCopyNotFound = errors.New("book copy not found")
+	ErrBarcodeTaken     = errors.New("barcode already in use")
+	ErrInvalidBookCopy  = errors.New("invalid book copy condition or status")
+	ErrBookCopyInUse    = errors.New("book copy is on loan or on hold")
+)
+
+var validCopyConditions = []string{
+	model.BookCopyConditionGood, model.BookCopyConditionFair, model.BookCopyConditionPoor, model.BookCopyConditionDamaged,
+}
+
+// settableCopyStatuses are the statuses staff may put a copy in by hand. OnLoan and
+// OnHold are only set by rentals and reservations.
+var settableCopyStatuses = []string{
+	model.BookCopyStatusAvailable, model.BookCopyStatusDamaged, model.BookCopyStatusLost, model.BookCopyStatusRetired,
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// BookCopyDetail is a copy as seen at the front desk, with the rental it is out on.
+type BookCopyDetail struct {
+	model.BookCopy
+	Rental *model.Rental
+}
+
+// BookCopyUpdate holds the fields staff may change on a copy. Empty fields are kept.
+type BookCopyUpdate struct {
+	Condition     string
+	ShelfLocation string
+	Status        string
+}
+
+type BookCopyService interface {
+	GetCopiesByBookID(bookID uint) ([]model.BookCopy, error)
+	GetCopyByBarcode(barcode string) (BookCopyDetail, error)
+	CreateCopy(bookCopy model.BookCopy) (model.BookCopy, error)
+	UpdateCopy(id uint, update BookCopyUpdate) (model.BookCopy, error)
+	DeleteCopy(id uint) error
+}
+
+type bookCopyService struct {
+	repo       repository.BookCopyRepository
+	rentalRepo repository.RentalRepository
+	uow        repository.UnitOfWork
+	cfg        config.RentalConfig
+}
+
+func NewBookCopyService(repo repository.BookCopyRepository, rentalRepo repository.RentalRepository, uow repository.UnitOfWork, cfg config.RentalConfig) BookCopyService {
+	return &bookCopyService{repo: repo, rentalRepo: rentalRepo, uow: uow, cfg: cfg}
+}
+
+func (s *bookCopyService) GetCopiesByBookID(bookID uint) ([]model.BookCopy, error) {
+	return s.repo.GetByBookID(bookID)
+}
+
+// GetCopyByBarcode looks a scanned copy up, together with its rental when on loan.
+func (s *bookCopyService) GetCopyByBarcode(barcode string) (BookCopyDetail, error) {
+	bookCopy, err := s.repo.GetByBarcode(strings.TrimSpace(barcode))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return BookCopyDetail{}, ErrBookCopyNotFound
+	}
+	if err != nil {
+		return BookCopyDetail{}, err
+	}
+
+	detail := BookCopyDetail{BookCopy: bookCopy}
+	if bookCopy.Status == model.BookCopyStatusOnLoan {
+		rental, err := s.rentalRepo.GetActiveByBookCopyID(bookCopy.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return BookCopyDetail{}, err
+		}
+		if err == nil {
+			detail.Rental = &rental
+		}
+	}
+
+	return detail, nil
+}
+
+// CreateCopy adds a copy to a book. Without a barcode one is generated. The new copy
+// goes to the next reservation in line, or becomes available.
+func (s *bookCopyService) CreateCopy(bookCopy model.BookCopy) (model.BookCopy, error) {
+	bookCopy.Barcode = strings.TrimSpace(bookCopy.Barcode)
+	if bookCopy.Condition == "" {
+		bookCopy.Condition = model.BookCopyConditionGood
+	}
+	if !containsString(validCopyConditions, bookCopy.Condition) {
+		return model.BookCopy{}, ErrInvalidBookCopy
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if _, err := repos.Book.GetByIDForUpdate(bookCopy.BookID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookNotFound
+			}
+			return err
+		}
+
+		if bookCopy.Barcode == "" {
+			count, err := repos.BookCopy.CountByBookID(bookCopy.BookID)
+			if err != nil {
+				return err
+			}
+			bookCopy.Barcode = model.GenerateBarcode(bookCopy.BookID, int(count)+1)
+		}
+		if _, err := repos.BookCopy.GetByBarcode(bookCopy.Barcode); err == nil {
+			return ErrBarcodeTaken
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var err error
+		bookCopy.Status = model.BookCopyStatusAvailable
+		if bookCopy, err = repos.BookCopy.Create(bookCopy); err != nil {
+			return err
+		}
+		if err := releaseCopy(repos, &bookCopy.ID, s.cfg.HoldDuration); err != nil {
+			return err
+		}
+
+		bookCopy, err = repos.BookCopy.GetByIDForUpdate(bookCopy.ID)
+		return err
+	})
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	return bookCopy, nil
+}
+
+// UpdateCopy changes the condition, shelf location or status of a copy. A copy that
+// becomes available again goes to the next reservation in line.
+func (s *bookCopyService) UpdateCopy(id uint, update BookCopyUpdate) (model.BookCopy, error) {
+	if update.Condition != "" && !containsString(validCopyConditions, update.Condition) {
+		return model.BookCopy{}, ErrInvalidBookCopy
+	}
+	if update.Status != "" && !containsString(settableCopyStatuses, update.Status) {
+		return model.BookCopy{}, ErrInvalidBookCopy
+	}
+
+	found, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.BookCopy{}, ErrBookCopyNotFound
+	}
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	var bookCopy model.BookCopy
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		//Lock book before copy, same order as checkout
+		if _, err := repos.Book.GetByIDForUpdate(found.BookID); err != nil {
+			return err
+		}
+		bookCopy, err = repos.BookCopy.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if update.Condition != "" {
+			bookCopy.Condition = update.Condition
+		}
+		if update.ShelfLocation != "" {
+			bookCopy.ShelfLocation = update.ShelfLocation
+		}
+
+		released := false
+		if update.Status != "" && update.Status != bookCopy.Status {
+			if bookCopy.Status == model.BookCopyStatusOnLoan || bookCopy.Status == model.BookCopyStatusOnHold {
+				return ErrBookCopyInUse
+			}
+			bookCopy.Status = update.Status
+			released = update.Status == model.BookCopyStatusAvailable
+		}
+
+		if bookCopy, err = repos.BookCopy.Update(bookCopy); err != nil {
+			return err
+		}
+		if released {
+			if err := releaseCopy(repos, &bookCopy.ID, s.cfg.HoldDuration); err != nil {
+				return err
+			}
+			bookCopy, err = repos.BookCopy.GetByIDForUpdate(id)
+			return err
+		}
+		return repos.Book.SyncStock(bookCopy.BookID)
+	})
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	return bookCopy, nil
+}
+
+// DeleteCopy removes a copy that is neither on loan nor on hold.
+func (s *bookCopyService) DeleteCopy(id uint) error {
+	found, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrBookCopyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		if _, err := repos.Book.GetByIDForUpdate(found.BookID); err != nil {
+			return err
+		}
+		bookCopy, err := repos.BookCopy.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if bookCopy.Status == model.BookCopyStatusOnLoan || bookCopy.Status == model.BookCopyStatusOnHold {
+			return ErrBookCopyInUse
+		}
+		if err := repos.BookCopy.Delete(id); err != nil {
+			return err
+		}
+		return repos.Book.SyncStock(bookCopy.BookID)
+	})
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type BookCopyServiceMock struct {
+	mock.Mock
+}
+
+func (m *BookCopyServiceMock) GetCopiesByBookID(bookID uint) ([]model.BookCopy, error) {
+	args := m.Called(bookID)
+	return args.Get(0).([]model.BookCopy), args.Error(1)
+}
+
+func (m *BookCopyServiceMock) GetCopyByBarcode(barcode string) (BookCopyDetail, error) {
+	args := m.Called(barcode)
+	return args.Get(0).(BookCopyDetail), args.Error(1)
+}
+
+func (m *BookCopyServiceMock) CreateCopy(bookCopy model.BookCopy) (model.BookCopy, error) {
+	args := m.Called(bookCopy)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *BookCopyServiceMock) UpdateCopy(id uint, update BookCopyUpdate) (model.BookCopy, error) {
+	args := m.Called(id, update)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *BookCopyServiceMock) DeleteCopy(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
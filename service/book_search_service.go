@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/repository"
+	"strings"
+)
+
+var ErrEmptySearchQuery = errors.New("search query required")
+
+// BookSearchPage is one page of search results. Fuzzy is set when nothing matched word
+// for word and the books with similar names are returned instead.
+type BookSearchPage struct {
+	Hits  []repository.BookSearchHit
+	Total int64
+	Page  int
+	Limit int
+	Fuzzy bool
+}
+
+type BookSearchService interface {
+	SearchBooks(query string, page int, limit int) (BookSearchPage, error)
+}
+
+type bookSearchService struct {
+	repo repository.BookSearchRepository
+}
+
+func NewBookSearchService(repo repository.BookSearchRepository) BookSearchService {
+	return &bookSearchService{repo: repo}
+}
+
+// SearchBooks ranks the catalog against query by full-text relevance, falling back to
+// name similarity when the full-text search finds nothing.
+func (s *bookSearchService) SearchBooks(query string, page int, limit int) (BookSearchPage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return BookSearchPage{}, ErrEmptySearchQuery
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	hits, total, err := s.repo.FullText(query, page, limit)
+	if err != nil {
+		return BookSearchPage{}, err
+	}
+	if total > 0 {
+		return BookSearchPage{Hits: hits, Total: total, Page: page, Limit: limit}, nil
+	}
+
+	hits, total, err = s.repo.Similar(query, page, limit)
+	if err != nil {
+		return BookSearchPage{}, err
+	}
+	return BookSearchPage{Hits: hits, Total: total, Page: page, Limit: limit, Fuzzy: true}, nil
+}
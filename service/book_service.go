@@ -2,13 +2,39 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"pojok-baca-api/dto"
 	"pojok-baca-api/model"
 	"pojok-baca-api/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidBookFilter   = errors.New("invalid book filter")
+	ErrInvalidBook         = errors.New("name, stok, category, rental cost required")
+	ErrInvalidISBN         = errors.New("isbn must be a valid ISBN-10 or ISBN-13")
+	ErrISBNTaken           = errors.New("isbn already belongs to another book")
+	ErrInvalidBookMetadata = errors.New("publication year, page count, language or cover url is invalid")
 )
 
+// oldestPublicationYear is the earliest publication year a book may have.
+const oldestPublicationYear = 1450
+
+// BookPage is one page of the catalog. Page and Limit are the values actually used
+// after defaults were applied.
+type BookPage struct {
+	Books []model.Book
+	Total int64
+	Page  int
+	Limit int
+}
+
 type BookService interface {
-	GetBooks() ([]model.Book, error)
+	GetBooks(filter repository.BookFilter) (BookPage, error)
 	Create(book model.Book) (model.Book, error)
 	GetBookByID(id uint) (model.Book, error)
 	DeleteBookByID(id uint) error
@@ -16,21 +42,197 @@ type BookService interface {
 }
 
 type bookService struct {
-	repo repository.BookRepository
+	repo       repository.BookRepository
+	categories repository.CategoryRepository
+}
+
+func NewBookService(r repository.BookRepository, categories repository.CategoryRepository) BookService {
+	return &bookService{repo: r, categories: categories}
+}
+
+// GetBooks returns one page of the catalog. Pages hold at most maxPageSize books.
+func (s *bookService) GetBooks(filter repository.BookFilter) (BookPage, error) {
+	if filter.Sort != "" {
+		if _, ok := repository.BookSortOrders[filter.Sort]; !ok {
+			return BookPage{}, fmt.Errorf("%w: unknown sort %q", ErrInvalidBookFilter, filter.Sort)
+		}
+	}
+	if filter.MinCost < 0 || filter.MaxCost < 0 {
+		return BookPage{}, fmt.Errorf("%w: cost cannot be negative", ErrInvalidBookFilter)
+	}
+	if filter.MaxCost > 0 && filter.MinCost > filter.MaxCost {
+		return BookPage{}, fmt.Errorf("%w: min_cost must not exceed max_cost", ErrInvalidBookFilter)
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultPageSize
+	}
+	filter.Limit = min(filter.Limit, maxPageSize)
+
+	books, total, err := s.repo.List(filter)
+	if err != nil {
+		return BookPage{}, err
+	}
+	return BookPage{Books: books, Total: total, Page: filter.Page, Limit: filter.Limit}, nil
+}
+
+// normalizeBookMetadata tidies the bibliographic details of a book and checks them. An
+// empty ISBN is cleared, blank and repeated author names are dropped, and the language
+// code is lower-cased.
+func normalizeBookMetadata(book *model.Book) error {
+	if book.ISBN != nil && strings.TrimSpace(*book.ISBN) == "" {
+		book.ISBN = nil
+	}
+	if book.ISBN != nil {
+		isbn, err := normalizeISBN(*book.ISBN)
+		if err != nil {
+			return err
+		}
+		book.ISBN = &isbn
+	}
+
+	latestYear := time.Now().Year() + 1
+	if book.PublicationYear != 0 && (book.PublicationYear < oldestPublicationYear || book.PublicationYear > latestYear) {
+		return ErrInvalidBookMetadata
+	}
+	if book.PageCount < 0 {
+		return ErrInvalidBookMetadata
+	}
+
+	book.Language = strings.ToLower(strings.TrimSpace(book.Language))
+	if len(book.Language) > 35 {
+		return ErrInvalidBookMetadata
+	}
+
+	book.CoverURL = strings.TrimSpace(book.CoverURL)
+	if book.CoverURL != "" {
+		cover, err := url.Parse(book.CoverURL)
+		if err != nil || (cover.Scheme != "http" && cover.Scheme != "https") || cover.Host == "" {
+			return ErrInvalidBookMetadata
+		}
+	}
+
+	authors := make([]model.Author, 0, len(book.Authors))
+	seen := make(map[string]bool)
+	for _, author := range book.Authors {
+		name := strings.Join(strings.Fields(author.Name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		authors = append(authors, model.Author{Name: name})
+	}
+	book.Authors = authors
+
+	book.Publisher = strings.TrimSpace(book.Publisher)
+	book.Synopsis = strings.TrimSpace(book.Synopsis)
+	return nil
+}
+
+// checkISBNFree makes sure no book other than the one with id carries isbn.
+func (s *bookService) checkISBNFree(isbn *string, id uint) error {
+	if isbn == nil {
+		return nil
+	}
+	existing, err := s.repo.GetByISBN(*isbn)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != id {
+		return ErrISBNTaken
+	}
+	return nil
 }
 
-func NewBookService(r repository.BookRepository) BookService {
-	return &bookService{repo: r}
+// findCategory finds the stored category called name, by name or slug.
+func findCategory(repo repository.CategoryRepository, name string) (model.Category, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	category, err := repo.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		category, err = repo.GetBySlug(model.Slugify(name))
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Category{}, ErrCategoryNotFound
+	}
+	return category, err
 }
 
-func (s *bookService) GetBooks() ([]model.Book, error) {
-	return s.repo.GetAll()
+// categoryByName finds the category called name, by name or slug, and creates it when
+// there is none yet.
+func categoryByName(repo repository.CategoryRepository, name string) (model.Category, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if model.Slugify(name) == "" {
+		return model.Category{}, ErrInvalidBook
+	}
+	category, err := findCategory(repo, name)
+	if errors.Is(err, ErrCategoryNotFound) {
+		category, err = repo.Create(model.Category{Slug: model.Slugify(name), Name: name})
+	}
+	return category, err
+}
+
+// resolveCategories files book under the stored categories whose IDs it carries, in
+// that order. A book without category IDs is filed under the category named by
+// book.Category, found by name or slug and created when there is none yet. The first
+// category becomes book.Category.
+func resolveCategories(repo repository.CategoryRepository, book *model.Book) error {
+	var categories []model.Category
+	if len(book.Categories) > 0 {
+		ids := make([]uint, 0, len(book.Categories))
+		for _, category := range book.Categories {
+			ids = append(ids, category.ID)
+		}
+		stored, err := repo.GetByIDs(ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]model.Category, len(stored))
+		for _, category := range stored {
+			byID[category.ID] = category
+		}
+
+		seen := make(map[uint]bool)
+		for _, id := range ids {
+			category, ok := byID[id]
+			if !ok {
+				return ErrCategoryNotFound
+			}
+			if !seen[id] {
+				seen[id] = true
+				categories = append(categories, category)
+			}
+		}
+	} else {
+		category, err := categoryByName(repo, book.Category)
+		if err != nil {
+			return err
+		}
+		categories = []model.Category{category}
+	}
+
+	book.Categories = categories
+	book.Category = categories[0].Name
+	return nil
 }
 
 func (s *bookService) Create(book model.Book) (model.Book, error) {
 
-	if book.Name == "" || book.Stok == 0 || book.Category == "" || book.RentalCost == 0 {
-		return model.Book{}, errors.New("name, stok, category, rental cost required")
+	if book.Name == "" || book.Stok == 0 || (book.Category == "" && len(book.Categories) == 0) || book.RentalCost == 0 {
+		return model.Book{}, ErrInvalidBook
+	}
+	if err := resolveCategories(s.categories, &book); err != nil {
+		return model.Book{}, err
+	}
+	if err := normalizeBookMetadata(&book); err != nil {
+		return model.Book{}, err
+	}
+	if err := s.checkISBNFree(book.ISBN, 0); err != nil {
+		return model.Book{}, err
 	}
 
 	return s.repo.Create(book)
@@ -44,12 +246,72 @@ func (s *bookService) DeleteBookByID(id uint) error {
 	return s.repo.Delete(id)
 }
 
+// UpdateBookByID replaces the name, rental cost and categories of a book and the
+// bibliographic details the request carries. Details left out keep their stored values.
 func (s *bookService) UpdateBookByID(req dto.UpdateBookRequest, id uint) (model.Book, error) {
-	book := model.Book{
-		Name:       req.Name,
-		Stok:       req.Stok,
-		RentalCost: req.RentalCost,
-		Category:   req.Category,
+	book, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Book{}, ErrBookNotFound
+	}
+	if err != nil {
+		return model.Book{}, err
+	}
+
+	book.Name = req.Name
+	book.RentalCost = req.RentalCost
+	book.Category = req.Category
+	book.Categories = nil
+	applyBookMetadata(&book, req.UpdateBookMetadataRequest)
+	for _, id := range req.CategoryIDs {
+		book.Categories = append(book.Categories, model.Category{Model: gorm.Model{ID: id}})
+	}
+	if book.Category == "" && len(book.Categories) == 0 {
+		return model.Book{}, ErrInvalidBook
+	}
+	if err := resolveCategories(s.categories, &book); err != nil {
+		return model.Book{}, err
+	}
+	if err := normalizeBookMetadata(&book); err != nil {
+		return model.Book{}, err
+	}
+	if err := s.checkISBNFree(book.ISBN, id); err != nil {
+		return model.Book{}, err
+	}
+
+	updated, err := s.repo.Update(book, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Book{}, ErrBookNotFound
+	}
+	return updated, err
+}
+
+// applyBookMetadata overwrites the details of book that req carries.
+func applyBookMetadata(book *model.Book, req dto.UpdateBookMetadataRequest) {
+	if req.ISBN != nil {
+		book.ISBN = req.ISBN
+	}
+	if req.Authors != nil {
+		book.Authors = make([]model.Author, 0, len(req.Authors))
+		for _, name := range req.Authors {
+			book.Authors = append(book.Authors, model.Author{Name: name})
+		}
+	}
+	if req.Publisher != nil {
+		book.Publisher = *req.Publisher
+	}
+	if req.PublicationYear != nil {
+		book.PublicationYear = *req.PublicationYear
+	}
+	if req.Language != nil {
+		book.Language = *req.Language
+	}
+	if req.PageCount != nil {
+		book.PageCount = *req.PageCount
+	}
+	if req.Synopsis != nil {
+		book.Synopsis = *req.Synopsis
+	}
+	if req.CoverURL != nil {
+		book.CoverURL = *req.CoverURL
 	}
-	return s.repo.Update(book, id)
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"pojok-baca-api/dto"
 	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -11,9 +12,9 @@ type BookServiceMock struct {
 	mock.Mock
 }
 
-func (m *BookServiceMock) GetBooks() ([]model.Book, error) {
-	args := m.Called()
-	return args.Get(0).([]model.Book), args.Error(1)
+func (m *BookServiceMock) GetBooks(filter repository.BookFilter) (BookPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(BookPage), args.Error(1)
 }
 
 func (m *BookServiceMock) Create(book model.Book) (model.Book, error) {
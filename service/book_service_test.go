@@ -0,0 +1,195 @@
+package service_test
+
+import (
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookService_GetBooks_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{})
+	bookRepo := repository.NewBookRepository(db)
+	bookService := service.NewBookService(bookRepo, repository.NewCategoryRepository(db))
+
+	for _, book := range []model.Book{
+		{Name: "Atomic Habits", Stok: 2, RentalCost: 20000, Category: "Self Development"},
+		{Name: "The Power of Habit", Stok: 1, RentalCost: 15000, Category: "Self Development"},
+		{Name: "Clean Code", Stok: 3, RentalCost: 25000, Category: "Programming"},
+		{Name: "100%_Go", Stok: 1, RentalCost: 10000, Category: "Programming"},
+	} {
+		_, err := bookRepo.Create(book)
+		assert.NoError(t, err)
+	}
+	// Out of stock
+	assert.NoError(t, db.Model(&model.Book{}).Where("name = ?", "Clean Code").Update("stok", 0).Error)
+
+	page, err := bookService.GetBooks(repository.BookFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, 20, page.Limit)
+
+	// Larger pages are cut down to the most a page holds
+	page, err = bookService.GetBooks(repository.BookFilter{Limit: 500})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, page.Limit)
+
+	page, err = bookService.GetBooks(repository.BookFilter{Keyword: "HABIT", Sort: "cost"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Books, 2)
+	assert.Equal(t, "The Power of Habit", page.Books[0].Name)
+
+	page, err = bookService.GetBooks(repository.BookFilter{Category: "programming", AvailableOnly: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Books, 1)
+	assert.Equal(t, "100%_Go", page.Books[0].Name)
+
+	// Wildcards in the keyword are matched literally
+	page, err = bookService.GetBooks(repository.BookFilter{Keyword: "%_"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+
+	page, err = bookService.GetBooks(repository.BookFilter{MinCost: 15000, MaxCost: 20000, Sort: "-name", Page: 2, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Len(t, page.Books, 1)
+	assert.Equal(t, "Atomic Habits", page.Books[0].Name)
+
+	_, err = bookService.GetBooks(repository.BookFilter{Sort: "password"})
+	assert.ErrorIs(t, err, service.ErrInvalidBookFilter)
+	_, err = bookService.GetBooks(repository.BookFilter{MinCost: 20000, MaxCost: 10000})
+	assert.ErrorIs(t, err, service.ErrInvalidBookFilter)
+}
+
+func TestBookSearchService_SearchBooks_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{})
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		t.Skipf("pg_trgm not available: %v", err)
+	}
+	bookRepo := repository.NewBookRepository(db)
+	searchService := service.NewBookSearchService(repository.NewBookSearchRepository(db))
+
+	for _, book := range []model.Book{
+		{Name: "Atomic Habits", Stok: 2, RentalCost: 20000, Category: "Self Development"},
+		{Name: "The Power of Habit", Stok: 1, RentalCost: 15000, Category: "Self Development"},
+		{Name: "Laskar Pelangi", Stok: 3, RentalCost: 10000, Category: "Novel"},
+	} {
+		_, err := bookRepo.Create(book)
+		assert.NoError(t, err)
+	}
+
+	// English words are stemmed, so habits also finds habit
+	page, err := searchService.SearchBooks("habits", 1, 10)
+	assert.NoError(t, err)
+	assert.False(t, page.Fuzzy)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Contains(t, page.Hits[0].Snippet, "<mark>")
+
+	page, err = searchService.SearchBooks("pelangi", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "Laskar Pelangi", page.Hits[0].Name)
+
+	// The search document follows renames
+	laskar := page.Hits[0]
+	_, err = bookRepo.Update(model.Book{Name: "Sang Pemimpi", RentalCost: 10000, Category: "Novel"}, laskar.ID)
+	assert.NoError(t, err)
+	page, err = searchService.SearchBooks("pemimpi", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+
+	// Markup in a book is escaped, only the matches are marked
+	_, err = bookRepo.Create(model.Book{Name: "<b>Bold</b> Moves", Stok: 1, RentalCost: 10000, Category: "Novel"})
+	assert.NoError(t, err)
+	page, err = searchService.SearchBooks("moves", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;b&gt;Bold&lt;/b&gt; <mark>Moves</mark> - Novel", page.Hits[0].Snippet)
+
+	// A typo matches nothing word for word, but the name is close enough
+	page, err = searchService.SearchBooks("atomik", 1, 10)
+	assert.NoError(t, err)
+	assert.True(t, page.Fuzzy)
+	assert.Equal(t, "Atomic Habits", page.Hits[0].Name)
+
+	_, err = searchService.SearchBooks("  ", 1, 10)
+	assert.ErrorIs(t, err, service.ErrEmptySearchQuery)
+}
+
+func TestBookService_Metadata_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{})
+	bookService := service.NewBookService(repository.NewBookRepository(db), repository.NewCategoryRepository(db))
+
+	isbn := "978-0-7352-1129-2"
+	book, err := bookService.Create(model.Book{
+		Name: "Atomic Habits", Stok: 1, RentalCost: 20000, Category: "Self Development",
+		ISBN: &isbn, Language: " EN ", PublicationYear: 2018,
+		Authors: []model.Author{{Name: "James  Clear"}, {Name: "james clear"}, {Name: " "}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "9780735211292", *book.ISBN)
+	assert.Equal(t, "en", book.Language)
+	assert.Len(t, book.Authors, 1)
+	assert.Equal(t, "James Clear", book.Authors[0].Name)
+
+	// The same ISBN, written differently, is still taken
+	duplicate := "9780735211292"
+	_, err = bookService.Create(model.Book{Name: "Atomic Habits (copy)", Stok: 1, RentalCost: 20000, Category: "Self Development", ISBN: &duplicate})
+	assert.ErrorIs(t, err, service.ErrISBNTaken)
+
+	invalid := "978-0-7352-1129-3"
+	_, err = bookService.Create(model.Book{Name: "Broken", Stok: 1, RentalCost: 1000, Category: "Misc", ISBN: &invalid})
+	assert.ErrorIs(t, err, service.ErrInvalidISBN)
+	_, err = bookService.Create(model.Book{Name: "Broken", Stok: 1, RentalCost: 1000, Category: "Misc", CoverURL: "javascript:alert(1)"})
+	assert.ErrorIs(t, err, service.ErrInvalidBookMetadata)
+
+	// Books without an ISBN do not collide
+	empty := ""
+	_, err = bookService.Create(model.Book{Name: "Zine", Stok: 1, RentalCost: 1000, Category: "Misc", ISBN: &empty})
+	assert.NoError(t, err)
+	_, err = bookService.Create(model.Book{Name: "Pamphlet", Stok: 1, RentalCost: 1000, Category: "Misc"})
+	assert.NoError(t, err)
+
+	// Updating keeps the book's own ISBN and replaces its authors, reusing stored ones
+	isbn = "9780735211292"
+	pageCount := 320
+	_, err = bookService.UpdateBookByID(dto.UpdateBookRequest{
+		Name: "Atomic Habits", RentalCost: 25000, Category: "Self Development",
+		UpdateBookMetadataRequest: dto.UpdateBookMetadataRequest{ISBN: &isbn, Authors: []string{"James Clear", "Unknown Editor"}, PageCount: &pageCount},
+	}, book.ID)
+	assert.NoError(t, err)
+
+	stored, err := bookService.GetBookByID(book.ID)
+	assert.NoError(t, err)
+	assert.Len(t, stored.Authors, 2)
+	assert.Equal(t, 320, stored.PageCount)
+	assert.Equal(t, 1, stored.Stok)
+
+	var authors int64
+	db.Model(&model.Author{}).Count(&authors)
+	assert.Equal(t, int64(2), authors)
+
+	// Details left out of an update keep their values
+	_, err = bookService.UpdateBookByID(dto.UpdateBookRequest{Name: "Atomic Habits", RentalCost: 20000, Category: "Self Development"}, book.ID)
+	assert.NoError(t, err)
+	stored, err = bookService.GetBookByID(book.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "9780735211292", *stored.ISBN)
+	assert.Len(t, stored.Authors, 2)
+	assert.Equal(t, 320, stored.PageCount)
+	assert.Equal(t, "en", stored.Language)
+
+	// while details sent empty are cleared
+	_, err = bookService.UpdateBookByID(dto.UpdateBookRequest{Name: "Atomic Habits", RentalCost: 20000, Category: "Self Development",
+		UpdateBookMetadataRequest: dto.UpdateBookMetadataRequest{ISBN: &empty, Authors: []string{}}}, book.ID)
+	assert.NoError(t, err)
+	stored, err = bookService.GetBookByID(book.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.ISBN)
+	assert.Empty(t, stored.Authors)
+
+	_, err = bookService.UpdateBookByID(dto.UpdateBookRequest{Name: "Ghost", RentalCost: 1000, Category: "Misc"}, book.ID+100)
+	assert.ErrorIs(t, err, service.ErrBookNotFound)
+}
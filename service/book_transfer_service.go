@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyImport  = errors.New("import has no rows")
+	ErrImportFailed = errors.New("import has invalid rows, nothing was imported")
+)
+
+// errImportRolledBack ends the import transaction of a dry run or of an import with
+// invalid rows without saving anything.
+var errImportRolledBack = errors.New("import rolled back")
+
+// exportBatchSize is how many books an export reads from the database at a time.
+const exportBatchSize = 200
+
+// BookImportRow is one book read from an import file. Line is the line the row starts
+// on and Err is set when the row could not be read.
+type BookImportRow struct {
+	Line int
+	Book model.Book
+	Err  error
+}
+
+// BookImportRowError is the reason a row of an import was rejected.
+type BookImportRowError struct {
+	Line int
+	Err  error
+}
+
+// BookImportReport counts what an import did, or would have done on a dry run.
+type BookImportReport struct {
+	DryRun  bool
+	Rows    int
+	Created int
+	Updated int
+	Errors  []BookImportRowError
+}
+
+type BookTransferService interface {
+	ImportBooks(rows []BookImportRow, dryRun bool) (BookImportReport, error)
+	ExportBooks(fn func(book model.Book) error) error
+}
+
+type bookTransferService struct {
+	repo repository.BookRepository
+	uow  repository.UnitOfWork
+	cfg  config.RentalConfig
+}
+
+func NewBookTransferService(repo repository.BookRepository, uow repository.UnitOfWork, cfg config.RentalConfig) BookTransferService {
+	return &bookTransferService{repo: repo, uow: uow, cfg: cfg}
+}
+
+// ImportBooks stores the rows in one transaction. A row whose ISBN belongs to a stored
+// book updates that book and adds the copies it is short of Stok available or held
+// ones, never removing any, so importing the same file again changes nothing; any other
+// row creates a book. When a row is invalid nothing is stored and ErrImportFailed is returned with
+// the report listing every invalid row. A dry run checks every row the same way and
+// stores nothing.
+func (s *bookTransferService) ImportBooks(rows []BookImportRow, dryRun bool) (BookImportReport, error) {
+	if len(rows) == 0 {
+		return BookImportReport{}, ErrEmptyImport
+	}
+
+	var report BookImportReport
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		report = BookImportReport{DryRun: dryRun, Rows: len(rows)}
+		for _, row := range rows {
+			if row.Err != nil {
+				report.Errors = append(report.Errors, BookImportRowError{Line: row.Line, Err: row.Err})
+				continue
+			}
+			created, err := s.importRow(repos, row.Book)
+			if isImportRowError(err) {
+				report.Errors = append(report.Errors, BookImportRowError{Line: row.Line, Err: err})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if created {
+				report.Created++
+			} else {
+				report.Updated++
+			}
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		return BookImportReport{}, err
+	}
+	if len(report.Errors) > 0 {
+		return report, ErrImportFailed
+	}
+	return report, nil
+}
+
+// isImportRowError tells the mistakes in a row, which are reported, from failures that
+// abort the whole import.
+func isImportRowError(err error) bool {
+	return errors.Is(err, ErrInvalidBook) || errors.Is(err, ErrInvalidISBN) || errors.Is(err, ErrInvalidBookMetadata)
+}
+
+// importRow stores the book of one row and reports whether it created a book. The
+// categories of the book are named rather than stored ones, the first being its
+// primary category.
+func (s *bookTransferService) importRow(repos repository.Repositories, book model.Book) (bool, error) {
+	if book.Name == "" || book.Stok < 0 || len(book.Categories) == 0 || book.RentalCost <= 0 {
+		return false, ErrInvalidBook
+	}
+	if err := normalizeBookMetadata(&book); err != nil {
+		return false, err
+	}
+	named := book.Categories
+	book.Categories = make([]model.Category, 0, len(named))
+	for _, category := range named {
+		stored, err := categoryByName(repos.Category, category.Name)
+		if err != nil {
+			return false, err
+		}
+		book.Categories = append(book.Categories, stored)
+	}
+	if err := resolveCategories(repos.Category, &book); err != nil {
+		return false, err
+	}
+
+	if book.ISBN != nil {
+		existing, err := repos.Book.GetByISBN(*book.ISBN)
+		if err == nil {
+			//Lock the book so copies created meanwhile cannot take the same barcodes
+			if _, err := repos.Book.GetByIDForUpdate(existing.ID); err != nil {
+				return false, err
+			}
+			if _, err := repos.Book.Update(book, existing.ID); err != nil {
+				return false, err
+			}
+			shelved, err := repos.BookCopy.CountByStatus(existing.ID, []string{model.BookCopyStatusAvailable, model.BookCopyStatusOnHold})
+			if err != nil {
+				return false, err
+			}
+			return false, s.addCopies(repos, existing.ID, book.Stok-int(shelved))
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+	}
+
+	_, err := repos.Book.Create(book)
+	return true, err
+}
+
+// addCopies adds count copies to a locked book. Each one goes to the next reservation in
+// line, or becomes available.
+func (s *bookTransferService) addCopies(repos repository.Repositories, bookID uint, count int) error {
+	for i := 0; i < count; i++ {
+		number, err := repos.BookCopy.CountByBookID(bookID)
+		if err != nil {
+			return err
+		}
+		bookCopy, err := repos.BookCopy.Create(model.BookCopy{
+			BookID:    bookID,
+			Barcode:   model.GenerateBarcode(bookID, int(number)+1),
+			Condition: model.BookCopyConditionGood,
+			Status:    model.BookCopyStatusAvailable,
+		})
+		if err != nil {
+			return err
+		}
+		if err := releaseCopy(repos, &bookCopy.ID, s.cfg.HoldDuration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportBooks hands every book of the catalog to fn in order of ID, with its authors and
+// categories, reading them from the database in batches.
+func (s *bookTransferService) ExportBooks(fn func(book model.Book) error) error {
+	return s.repo.Each(exportBatchSize, func(books []model.Book) error {
+		for _, book := range books {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type BookTransferServiceMock struct {
+	mock.Mock
+}
+
+func (m *BookTransferServiceMock) ImportBooks(rows []BookImportRow, dryRun bool) (BookImportReport, error) {
+	args := m.Called(rows, dryRun)
+	return args.Get(0).(BookImportReport), args.Error(1)
+}
+
+// ExportBooks hands the books given as the first return value to fn.
+func (m *BookTransferServiceMock) ExportBooks(fn func(book model.Book) error) error {
+	args := m.Called()
+	for _, book := range args.Get(0).([]model.Book) {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
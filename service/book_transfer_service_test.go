@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func importedBook(name, isbn string, stok int, categories ...string) model.Book {
+	book := model.Book{Name: name, ISBN: &isbn, Stok: stok, RentalCost: 15000}
+	for _, category := range categories {
+		book.Categories = append(book.Categories, model.Category{Name: category})
+	}
+	return book
+}
+
+func TestBookTransferService_Import_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Reservation{})
+	bookRepo := repository.NewBookRepository(db)
+	transferService := service.NewBookTransferService(bookRepo, repository.NewUnitOfWork(db), config.RentalConfig{HoldDuration: time.Hour})
+
+	rows := []service.BookImportRow{
+		{Line: 2, Book: importedBook("Laskar Pelangi", "978-979-3062-79-1", 2, "Novel", "Sastra")},
+		{Line: 3, Book: importedBook("Bumi Manusia", "", 1, "novel")},
+	}
+
+	// A dry run reports what would happen and stores nothing
+	report, err := transferService.ImportBooks(rows, true)
+	assert.NoError(t, err)
+	assert.Equal(t, service.BookImportReport{DryRun: true, Rows: 2, Created: 2}, report)
+	var count int64
+	assert.NoError(t, db.Model(&model.Book{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+	assert.NoError(t, db.Model(&model.Category{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	// One invalid row keeps the whole file out
+	invalid := append(rows, service.BookImportRow{Line: 4, Book: importedBook("Ghost", "123", 1, "Novel")})
+	report, err = transferService.ImportBooks(invalid, false)
+	assert.ErrorIs(t, err, service.ErrImportFailed)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, 4, report.Errors[0].Line)
+	assert.ErrorIs(t, report.Errors[0].Err, service.ErrInvalidISBN)
+	assert.NoError(t, db.Model(&model.Book{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	report, err = transferService.ImportBooks(rows, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Created)
+
+	laskar, err := bookRepo.GetByISBN("9789793062791")
+	assert.NoError(t, err)
+	laskar, err = bookRepo.GetByID(laskar.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, laskar.Stok)
+	assert.Equal(t, "Novel", laskar.Category)
+	assert.Len(t, laskar.Categories, 2)
+
+	// Importing the same ISBN again updates the book and adds copies up to the new stock,
+	// the first of them going to the reader waiting for the book
+	user := model.User{Name: "Reader", Email: "reader@example.com", Password: "secret", Role: "user"}
+	assert.NoError(t, db.Create(&user).Error)
+	reservation := model.Reservation{UserID: user.ID, BookID: laskar.ID, Status: model.ReservationStatusWaiting}
+	assert.NoError(t, db.Create(&reservation).Error)
+
+	update := importedBook("Laskar Pelangi (Edisi Baru)", "9789793062791", 4, "Novel", "Sastra")
+	report, err = transferService.ImportBooks([]service.BookImportRow{{Line: 2, Book: update}}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+
+	laskar, err = bookRepo.GetByID(laskar.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Laskar Pelangi (Edisi Baru)", laskar.Name)
+	assert.Equal(t, 3, laskar.Stok)
+	assert.NoError(t, db.Model(&model.BookCopy{}).Where("book_id = ?", laskar.ID).Count(&count).Error)
+	assert.Equal(t, int64(4), count)
+	assert.NoError(t, db.First(&reservation, reservation.ID).Error)
+	assert.Equal(t, model.ReservationStatusReady, reservation.Status)
+
+	// Importing the same file again adds nothing, though the held copy is out of stock
+	// and another reader is waiting
+	other := model.User{Name: "Other", Email: "other@example.com", Password: "secret", Role: "user"}
+	assert.NoError(t, db.Create(&other).Error)
+	assert.NoError(t, db.Create(&model.Reservation{UserID: other.ID, BookID: laskar.ID, Status: model.ReservationStatusWaiting}).Error)
+	_, err = transferService.ImportBooks([]service.BookImportRow{{Line: 2, Book: update}}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(&model.BookCopy{}).Where("book_id = ?", laskar.ID).Count(&count).Error)
+	assert.Equal(t, int64(4), count)
+
+	// Importing the export back changes nothing
+	var exported []service.BookImportRow
+	assert.NoError(t, transferService.ExportBooks(func(book model.Book) error {
+		if book.ISBN == nil {
+			return nil
+		}
+		row := importedBook(book.Name, *book.ISBN, book.Stok, book.Category)
+		row.Categories = append(row.Categories, book.Categories...)
+		exported = append(exported, service.BookImportRow{Line: len(exported) + 2, Book: row})
+		return nil
+	}))
+	assert.Len(t, exported, 1)
+	report, err = transferService.ImportBooks(exported, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	assert.NoError(t, db.Model(&model.BookCopy{}).Where("book_id = ?", laskar.ID).Count(&count).Error)
+	assert.Equal(t, int64(4), count)
+}
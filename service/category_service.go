@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidCategory  = errors.New("name and a parent outside the category's own subtree are required")
+	ErrCategoryTaken    = errors.New("category name or slug already exists")
+	ErrCategoryInUse    = errors.New("category still has books, merge it into another category")
+)
+
+type CategoryService interface {
+	ListCategories() ([]repository.CategoryCount, error)
+	CreateCategory(category model.Category) (model.Category, error)
+	UpdateCategory(id uint, category model.Category) (model.Category, error)
+	DeleteCategory(id uint, mergeInto *uint) error
+}
+
+type categoryService struct {
+	repo repository.CategoryRepository
+	uow  repository.UnitOfWork
+}
+
+func NewCategoryService(repo repository.CategoryRepository, uow repository.UnitOfWork) CategoryService {
+	return &categoryService{repo: repo, uow: uow}
+}
+
+func (s *categoryService) ListCategories() ([]repository.CategoryCount, error) {
+	return s.repo.ListWithCounts()
+}
+
+// checkCategory tidies the name and slug of category, which defaults to the slug of
+// its name, and makes sure no other category than the one with id uses either and its
+// parent is not the category itself or one below it.
+func checkCategory(repo repository.CategoryRepository, id uint, category *model.Category) error {
+	category.Name = strings.Join(strings.Fields(category.Name), " ")
+	if category.Slug == "" {
+		category.Slug = category.Name
+	}
+	category.Slug = model.Slugify(category.Slug)
+	if category.Name == "" || category.Slug == "" {
+		return ErrInvalidCategory
+	}
+
+	for _, lookup := range []func() (model.Category, error){
+		func() (model.Category, error) { return repo.GetBySlug(category.Slug) },
+		func() (model.Category, error) { return repo.GetByName(category.Name) },
+	} {
+		existing, err := lookup()
+		if err == nil && existing.ID != id {
+			return ErrCategoryTaken
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	//Walk up from the parent, the category itself must not be on the way
+	visited := make(map[uint]bool)
+	for parentID := category.ParentID; parentID != nil; {
+		if *parentID == id || visited[*parentID] {
+			return ErrInvalidCategory
+		}
+		visited[*parentID] = true
+
+		parent, err := repo.GetByID(*parentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCategory
+		}
+		if err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func (s *categoryService) CreateCategory(category model.Category) (model.Category, error) {
+	if err := checkCategory(s.repo, 0, &category); err != nil {
+		return model.Category{}, err
+	}
+	return s.repo.Create(category)
+}
+
+// UpdateCategory renames, re-slugs or moves a category. A new name is carried over to
+// the books filed under it first, to the pricing plans of the category and to the
+// vouchers restricted to it.
+func (s *categoryService) UpdateCategory(id uint, category model.Category) (model.Category, error) {
+	var updated model.Category
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		existing, err := repos.Category.GetByID(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkCategory(repos.Category, id, &category); err != nil {
+			return err
+		}
+
+		old := existing
+		existing.Name = category.Name
+		existing.Slug = category.Slug
+		existing.ParentID = category.ParentID
+		updated, err = repos.Category.Update(existing)
+		if err != nil {
+			return err
+		}
+		if old.Name == updated.Name && old.Slug == updated.Slug {
+			return nil
+		}
+		return repos.Category.Rename(old, updated)
+	})
+	return updated, err
+}
+
+// DeleteCategory removes a category and moves its children up to its parent. A category
+// with books can only go when mergeInto names the category its books move to.
+func (s *categoryService) DeleteCategory(id uint, mergeInto *uint) error {
+	return s.uow.Do(func(repos repository.Repositories) error {
+		category, err := repos.Category.GetByID(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if mergeInto != nil {
+			if *mergeInto == id {
+				return ErrInvalidCategory
+			}
+			target, err := repos.Category.GetByID(*mergeInto)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if err := repos.Category.MoveBooks(id, target); err != nil {
+				return err
+			}
+		} else {
+			books, err := repos.Category.CountBooks(id)
+			if err != nil {
+				return err
+			}
+			if books > 0 {
+				return ErrCategoryInUse
+			}
+		}
+
+		if err := repos.Category.Reparent(id, category.ParentID); err != nil {
+			return err
+		}
+		return repos.Category.Delete(id)
+	})
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type CategoryServiceMock struct {
+	mock.Mock
+}
+
+func (m *CategoryServiceMock) ListCategories() ([]repository.CategoryCount, error) {
+	args := m.Called()
+	return args.Get(0).([]repository.CategoryCount), args.Error(1)
+}
+
+func (m *CategoryServiceMock) CreateCategory(category model.Category) (model.Category, error) {
+	args := m.Called(category)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *CategoryServiceMock) UpdateCategory(id uint, category model.Category) (model.Category, error) {
+	args := m.Called(id, category)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *CategoryServiceMock) DeleteCategory(id uint, mergeInto *uint) error {
+	args := m.Called(id, mergeInto)
+	return args.Error(0)
+}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"pojok-baca-api/dto"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCategoryService_Taxonomy_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.PricingPlan{}, &model.Voucher{})
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := service.NewCategoryService(categoryRepo, repository.NewUnitOfWork(db))
+	bookService := service.NewBookService(repository.NewBookRepository(db), categoryRepo)
+
+	sastra, err := categoryService.CreateCategory(model.Category{Name: " Sastra "})
+	assert.NoError(t, err)
+	assert.Equal(t, "sastra", sastra.Slug)
+	novel, err := categoryService.CreateCategory(model.Category{Name: "Novel", ParentID: &sastra.ID})
+	assert.NoError(t, err)
+
+	_, err = categoryService.CreateCategory(model.Category{Name: "NOVEL"})
+	assert.ErrorIs(t, err, service.ErrCategoryTaken)
+	_, err = categoryService.CreateCategory(model.Category{Name: "Novels", Slug: "Novel"})
+	assert.ErrorIs(t, err, service.ErrCategoryTaken)
+
+	// A category cannot move below itself
+	_, err = categoryService.UpdateCategory(sastra.ID, model.Category{Name: "Sastra", ParentID: &novel.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidCategory)
+
+	// Books name their category by ID, or by a spelling of its name
+	laskar, err := bookService.Create(model.Book{Name: "Laskar Pelangi", Stok: 1, RentalCost: 10000,
+		Categories: []model.Category{{Model: gorm.Model{ID: novel.ID}}, {Model: gorm.Model{ID: sastra.ID}}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Novel", laskar.Category)
+	assert.Len(t, laskar.Categories, 2)
+
+	bumi, err := bookService.Create(model.Book{Name: "Bumi Manusia", Stok: 1, RentalCost: 10000, Category: "novel"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Novel", bumi.Category)
+
+	_, err = bookService.Create(model.Book{Name: "Ghost", Stok: 1, RentalCost: 10000,
+		Categories: []model.Category{{Model: gorm.Model{ID: novel.ID + 100}}}})
+	assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+
+	// The filter on a parent also finds the books of its subcategories
+	page, err := bookService.GetBooks(repository.BookFilter{Category: "Sastra"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+
+	categories, err := categoryService.ListCategories()
+	assert.NoError(t, err)
+	counts := make(map[string]int64)
+	for _, category := range categories {
+		counts[category.Slug] = category.BookCount
+	}
+	assert.Equal(t, map[string]int64{"sastra": 1, "novel": 2}, counts)
+
+	// Renaming carries over to books, pricing plans and vouchers
+	assert.NoError(t, db.Create(&model.PricingPlan{Category: "Novel", Name: "14 days", DurationDays: 14, Price: 15000}).Error)
+	voucher := model.Voucher{Code: "NOVEL50", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 50, Categories: "novel,Komik,Sastra", Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+	_, err = categoryService.UpdateCategory(novel.ID, model.Category{Name: "Novel Indonesia", Slug: "novel", ParentID: &sastra.ID})
+	assert.NoError(t, err)
+	stored, err := bookService.GetBookByID(bumi.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Novel Indonesia", stored.Category)
+	var plan model.PricingPlan
+	assert.NoError(t, db.First(&plan).Error)
+	assert.Equal(t, "Novel Indonesia", plan.Category)
+	assert.NoError(t, db.First(&voucher, voucher.ID).Error)
+	assert.Equal(t, "Novel Indonesia,Komik,Sastra", voucher.Categories)
+
+	// A category with books only goes by merging it
+	assert.ErrorIs(t, categoryService.DeleteCategory(novel.ID, nil), service.ErrCategoryInUse)
+	assert.NoError(t, categoryService.DeleteCategory(novel.ID, &sastra.ID))
+	stored, err = bookService.GetBookByID(bumi.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sastra", stored.Category)
+	assert.Len(t, stored.Categories, 1)
+	assert.NoError(t, db.First(&voucher, voucher.ID).Error)
+	assert.Equal(t, "Sastra,Komik", voucher.Categories)
+
+	// The slug is free again
+	_, err = categoryService.CreateCategory(model.Category{Name: "Novel"})
+	assert.NoError(t, err)
+
+	_, err = bookService.UpdateBookByID(dto.UpdateBookRequest{Name: "Bumi Manusia", RentalCost: 10000}, bumi.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidBook)
+}
@@ -1,92 +1,366 @@
 package service
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
-	"os"
+	"gorm.io/gorm"
+	"log"
+	"pojok-baca-api/gateway"
 	"pojok-baca-api/model"
 	"pojok-baca-api/repository"
+	"strconv"
 	"time"
 )
 
+var (
+	ErrDepositOrderNotFound = errors.New("deposit transaction not found")
+	ErrInvalidSignature     = errors.New("invalid notification signature")
+	ErrGrossAmountMismatch  = errors.New("gross amount does not match the deposit")
+	ErrInvalidDepositStatus = errors.New("unknown deposit status")
+)
+
+// DepositNotification is a payment status notification as posted by Midtrans, with
+// the raw body it arrived in.
+type DepositNotification struct {
+	OrderID           string
+	StatusCode        string
+	GrossAmount       string
+	SignatureKey      string
+	TransactionStatus string
+	FraudStatus       string
+	Payload           string
+}
+
+var depositStatuses = []string{
+	model.DepositStatusPending, model.DepositStatusCapture, model.DepositStatusSettlement, model.DepositStatusDeny,
+	model.DepositStatusCancel, model.DepositStatusExpire, model.DepositStatusFailure,
+}
+
+// depositTransitions lists, for every deposit status, the statuses a notification may
+// move it to. A captured card payment the fraud check accepted is already paid and
+// settles later; every other status besides pending is final. Notifications outside
+// this table are ignored.
+var depositTransitions = map[string][]string{
+	model.DepositStatusPending: {
+		model.DepositStatusCapture, model.DepositStatusSettlement, model.DepositStatusDeny,
+		model.DepositStatusCancel, model.DepositStatusExpire, model.DepositStatusFailure,
+	},
+	model.DepositStatusCapture: {model.DepositStatusSettlement},
+}
+
+func canTransitionDeposit(from, to string) bool {
+	for _, next := range depositTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// depositStatusOf is the status a notification moves a deposit to. A card capture only
+// counts once the fraud check accepted it: a challenged capture leaves the deposit
+// pending until Midtrans settles or denies it, and a denied one is denied.
+func depositStatusOf(transactionStatus, fraudStatus string) string {
+	if transactionStatus != model.DepositStatusCapture {
+		return transactionStatus
+	}
+	switch fraudStatus {
+	case model.FraudStatusAccept:
+		return model.DepositStatusCapture
+	case model.FraudStatusDeny:
+		return model.DepositStatusDeny
+	}
+	return model.DepositStatusPending
+}
+
+// isDepositFailed reports whether a deposit in status will never be paid.
+func isDepositFailed(status string) bool {
+	switch status {
+	case model.DepositStatusDeny, model.DepositStatusCancel, model.DepositStatusExpire, model.DepositStatusFailure:
+		return true
+	}
+	return false
+}
+
+// isDepositPaid reports whether a deposit in status has been paid. The wallet is
+// credited once, when the deposit first enters a paid status.
+func isDepositPaid(status string) bool {
+	return status == model.DepositStatusCapture || status == model.DepositStatusSettlement
+}
+
+// DepositPage is one page of a user's deposit transactions.
+type DepositPage struct {
+	Deposits []model.DepositTransaction
+	Total    int64
+	Page     int
+	Limit    int
+}
+
 type DepositTransactionService interface {
-	CreateTransaction(userID uint, amount int) (*snap.Response, error)
-	HandleWebhook(orderID, transactionStatus string) error
+	CreateTransaction(userID uint, amount int, voucherCode string) (gateway.Charge, error)
+	HandleWebhook(notification DepositNotification) error
+	ListTransactions(userID uint, status string, page int, limit int) (DepositPage, error)
+	GetTransaction(userID uint, orderID string) (model.DepositTransaction, error)
 }
 
 type depositTransactionService struct {
-	repo     repository.DepositTransactionRepository
-	userRepo repository.UserRepository
+	repo      repository.DepositTransactionRepository
+	uow       repository.UnitOfWork
+	gateway   gateway.PaymentGateway
+	serverKey string
 }
 
-func NewDepositService(repo repository.DepositTransactionRepository, userRepo repository.UserRepository) DepositTransactionService {
-	return &depositTransactionService{repo, userRepo}
+func NewDepositService(repo repository.DepositTransactionRepository, uow repository.UnitOfWork, paymentGateway gateway.PaymentGateway, serverKey string) DepositTransactionService {
+	return &depositTransactionService{repo, uow, paymentGateway, serverKey}
 }
 
-func (s *depositTransactionService) CreateTransaction(userID uint, amount int) (*snap.Response, error) {
-	orderID := fmt.Sprintf("ORDER-%d-%d", userID, time.Now().Unix())
+// CreateTransaction opens a deposit order and a charge for it at the payment gateway.
+// An optional voucher code is checked and redeemed together with the order; its bonus
+// is credited when the payment settles and the redemption is voided if it fails. When
+// the gateway cannot create the charge the order fails at once, giving the voucher use
+// back.
+func (s *depositTransactionService) CreateTransaction(userID uint, amount int, voucherCode string) (gateway.Charge, error) {
+	orderID := fmt.Sprintf("ORDER-%d-%d", userID, time.Now().UnixNano())
 
-	// Simpan transaksi ke DB
-	tx := &model.DepositTransaction{
-		UserID:     userID,
-		OrderID:    orderID,
-		Deposit:    amount,
-		Status:     "pending",
-		PaymentRef: "", // belum tahu tokennya
-	}
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Simpan transaksi ke DB
+		tx := &model.DepositTransaction{
+			UserID:     userID,
+			OrderID:    orderID,
+			Deposit:    amount,
+			Status:     model.DepositStatusPending,
+			PaymentRef: "", // diisi token dari gateway setelah charge dibuat
+		}
+
+		var voucher model.Voucher
+		if voucherCode != "" {
+			var err error
+			voucher, err = lockVoucher(repos, voucherCode, userID, model.VoucherScopeTopUp, time.Now())
+			if err != nil {
+				return err
+			}
+			if amount < voucher.MinAmount {
+				return ErrVoucherMinAmount
+			}
+			tx.VoucherID = &voucher.ID
+			tx.Bonus = voucherAmount(voucher, amount)
+		}
 
-	_, err := s.repo.Create(tx)
+		if _, err := repos.Deposit.Create(tx); err != nil {
+			return err
+		}
+		if tx.VoucherID == nil {
+			return nil
+		}
+		_, err := repos.Voucher.CreateRedemption(model.VoucherRedemption{
+			VoucherID:      voucher.ID,
+			UserID:         userID,
+			DepositOrderID: &tx.OrderID,
+			Amount:         tx.Bonus,
+			Status:         model.RedemptionStatusPending,
+		})
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return gateway.Charge{}, err
 	}
 
-	// Setup Midtrans
-	midtrans.ServerKey = os.Getenv("MIDTRANS_SERVER_KEY")
-	midtrans.Environment = midtrans.Sandbox
+	charge, err := s.gateway.CreateCharge(orderID, amount)
+	if err != nil {
+		failErr := s.uow.Do(func(repos repository.Repositories) error {
+			if err := repos.Deposit.UpdateStatus(orderID, model.DepositStatusFailure, nil); err != nil {
+				return err
+			}
+			return repos.Voucher.UpdateRedemptionStatus(orderID, model.RedemptionStatusVoided)
+		})
+		if failErr != nil {
+			return gateway.Charge{}, fmt.Errorf("%w (marking order %s failed: %v)", err, orderID, failErr)
+		}
+		return gateway.Charge{}, err
+	}
 
-	snapReq := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  orderID,
-			GrossAmt: int64(amount),
-		},
+	if err := s.repo.UpdatePaymentRef(orderID, charge.Token); err != nil {
+		return gateway.Charge{}, err
 	}
+	return charge, nil
+}
 
-	snapRes, midErr := snap.CreateTransaction(snapReq)
-	if midErr != nil {
-		return nil, fmt.Errorf("midtrans error: %v", midErr.Message)
+func (s *depositTransactionService) ListTransactions(userID uint, status string, page int, limit int) (DepositPage, error) {
+	if status != "" && !containsString(depositStatuses, status) {
+		return DepositPage{}, ErrInvalidDepositStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
 	}
 
-	return snapRes, nil
+	deposits, total, err := s.repo.ListByUserID(userID, status, page, limit)
+	if err != nil {
+		return DepositPage{}, err
+	}
+	return DepositPage{Deposits: deposits, Total: total, Page: page, Limit: limit}, nil
 }
 
-func (s *depositTransactionService) HandleWebhook(orderID, transactionStatus string) error {
+// GetTransaction returns one deposit of a user. Deposits of other users are reported
+// as not found.
+func (s *depositTransactionService) GetTransaction(userID uint, orderID string) (model.DepositTransaction, error) {
 	tx, err := s.repo.GetByOrderID(orderID)
-	if err != nil {
-		return err
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && tx.UserID != userID) {
+		return model.DepositTransaction{}, ErrDepositOrderNotFound
 	}
+	return tx, err
+}
+
+// verifySignature checks the signature_key of a notification, the SHA512 hex digest of
+// order_id, status_code, gross_amount and the server key. Without a server key nothing
+// can be verified, so every notification is rejected.
+func (s *depositTransactionService) verifySignature(notification DepositNotification) bool {
+	if s.serverKey == "" {
+		return false
+	}
+
+	expected := gateway.Signature(notification.OrderID, notification.StatusCode, notification.GrossAmount, s.serverKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(notification.SignatureKey)) == 1
+}
 
-	var paidAt *time.Time
+// grossAmountMatches reports whether the gross_amount of a notification, sent as a
+// decimal string such as "50000.00", is exactly the deposit amount.
+func grossAmountMatches(grossAmount string, deposit int) bool {
+	amount, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil {
+		return false
+	}
+	return amount == float64(deposit)
+}
 
-	if transactionStatus == "settlement" || transactionStatus == "capture" {
+// HandleWebhook applies a payment notification to its deposit transaction. Every
+// notification with a valid signature is stored for audit; the others are only logged,
+// so unauthenticated callers cannot fill the audit trail. Repeated and out-of-order
+// notifications are acknowledged but ignored, so retries from Midtrans never credit a
+// deposit twice.
+func (s *depositTransactionService) HandleWebhook(notification DepositNotification) error {
+	if !s.verifySignature(notification) {
+		log.Printf("Rejected deposit notification for order %q with status %q: %v",
+			notification.OrderID, notification.TransactionStatus, ErrInvalidSignature)
+		return ErrInvalidSignature
+	}
 
-		now := time.Now()
-		paidAt = &now
+	record := model.DepositNotification{
+		OrderID:           notification.OrderID,
+		TransactionStatus: notification.TransactionStatus,
+		FraudStatus:       notification.FraudStatus,
+		StatusCode:        notification.StatusCode,
+		GrossAmount:       notification.GrossAmount,
+		Source:            model.NotificationSourceWebhook,
+		Payload:           notification.Payload,
+	}
+	_, err := applyDepositStatus(s.uow, record)
+	return err
+}
 
-		user, err := s.userRepo.GetByID(tx.UserID)
+// applyDepositStatus moves the deposit of record.OrderID to the status depositStatusOf
+// gives for record as depositTransitions allows, credits the wallet when the deposit is first paid, and
+// stores record with what came of it. It returns that outcome.
+func applyDepositStatus(uow repository.UnitOfWork, record model.DepositNotification) (string, error) {
+	err := uow.Do(func(repos repository.Repositories) error {
+		tx, err := repos.Deposit.GetByOrderIDForUpdate(record.OrderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDepositOrderNotFound
+		}
 		if err != nil {
 			return err
 		}
-		newDeposit := 0
-		if user.Deposit != nil {
-			newDeposit = *user.Deposit
+		record.FromStatus = tx.Status
+		if !grossAmountMatches(record.GrossAmount, tx.Deposit) {
+			return ErrGrossAmountMismatch
 		}
-		total := newDeposit + tx.Deposit
-		_, err = s.userRepo.UpdateDeposit(total, tx.UserID)
-		if err != nil {
+
+		status := depositStatusOf(record.TransactionStatus, record.FraudStatus)
+		if !canTransitionDeposit(tx.Status, status) {
+			record.Outcome = model.NotificationOutcomeIgnored
+			record.Reason = fmt.Sprintf("%s cannot follow %s", status, tx.Status)
+			switch {
+			case record.TransactionStatus == model.DepositStatusCapture && status == model.DepositStatusPending:
+				record.Reason = fmt.Sprintf("capture with fraud_status %q is not paid", record.FraudStatus)
+			case tx.Status == status:
+				record.Reason = "duplicate notification"
+			}
+			_, err := repos.Notification.Create(record)
 			return err
 		}
+
+		paidAt := tx.PaidAt
+		if isDepositPaid(status) && !isDepositPaid(tx.Status) {
+			now := time.Now()
+			paidAt = &now
+
+			user, err := repos.User.GetByIDForUpdate(tx.UserID)
+			if err != nil {
+				return err
+			}
+			err = postToWallet(repos, &user, Posting{
+				Amount:         tx.Deposit,
+				Type:           model.LedgerTypeTopUp,
+				DepositOrderID: &tx.OrderID,
+				Description:    "Deposit top-up " + tx.OrderID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if tx.VoucherID != nil {
+				err = postToWallet(repos, &user, Posting{
+					Amount:         tx.Bonus,
+					Type:           model.LedgerTypeVoucherBonus,
+					DepositOrderID: &tx.OrderID,
+					Description:    "Voucher bonus on top-up " + tx.OrderID,
+				})
+				if err != nil {
+					return err
+				}
+				if err := repos.Voucher.UpdateRedemptionStatus(tx.OrderID, model.RedemptionStatusApplied); err != nil {
+					return err
+				}
+			}
+		}
+		//A failed payment gives the voucher use back
+		if tx.VoucherID != nil && isDepositFailed(status) {
+			if err := repos.Voucher.UpdateRedemptionStatus(tx.OrderID, model.RedemptionStatusVoided); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Deposit.UpdateStatus(tx.OrderID, status, paidAt); err != nil {
+			return err
+		}
+
+		record.Outcome = model.NotificationOutcomeApplied
+		_, err = repos.Notification.Create(record)
+		return err
+	})
+	if errors.Is(err, ErrDepositOrderNotFound) || errors.Is(err, ErrGrossAmountMismatch) {
+		return model.NotificationOutcomeRejected, rejectDepositStatus(uow, record, err)
+	}
+	if err != nil {
+		return "", err
 	}
+	return record.Outcome, nil
+}
+
+// rejectDepositStatus stores a status update that was turned away and returns reason.
+func rejectDepositStatus(uow repository.UnitOfWork, record model.DepositNotification, reason error) error {
+	record.Outcome = model.NotificationOutcomeRejected
+	record.Reason = reason.Error()
 
-	return s.repo.UpdateStatus(orderID, transactionStatus, paidAt)
+	err := uow.Do(func(repos repository.Repositories) error {
+		_, err := repos.Notification.Create(record)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return reason
 }
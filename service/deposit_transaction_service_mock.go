@@ -0,0 +1,32 @@
+package service
+
+import (
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type DepositTransactionServiceMock struct {
+	mock.Mock
+}
+
+func (m *DepositTransactionServiceMock) CreateTransaction(userID uint, amount int, voucherCode string) (gateway.Charge, error) {
+	args := m.Called(userID, amount, voucherCode)
+	return args.Get(0).(gateway.Charge), args.Error(1)
+}
+
+func (m *DepositTransactionServiceMock) HandleWebhook(notification DepositNotification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *DepositTransactionServiceMock) ListTransactions(userID uint, status string, page int, limit int) (DepositPage, error) {
+	args := m.Called(userID, status, page, limit)
+	return args.Get(0).(DepositPage), args.Error(1)
+}
+
+func (m *DepositTransactionServiceMock) GetTransaction(userID uint, orderID string) (model.DepositTransaction, error) {
+	args := m.Called(userID, orderID)
+	return args.Get(0).(model.DepositTransaction), args.Error(1)
+}
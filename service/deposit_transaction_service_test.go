@@ -0,0 +1,276 @@
+package service_test
+
+import (
+	"net/http/httptest"
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testServerKey = "SB-Mid-server-test-key"
+
+// signedNotification builds a notification signed the way Midtrans signs it.
+func signedNotification(orderID, statusCode, grossAmount, transactionStatus string) service.DepositNotification {
+	return service.DepositNotification{
+		OrderID:           orderID,
+		StatusCode:        statusCode,
+		GrossAmount:       grossAmount,
+		SignatureKey:      gateway.Signature(orderID, statusCode, grossAmount, testServerKey),
+		TransactionStatus: transactionStatus,
+	}
+}
+
+// seedPendingDeposit creates a user with an empty wallet and a pending deposit order.
+func seedPendingDeposit(t *testing.T, db *gorm.DB, orderID string, amount int) model.User {
+	t.Helper()
+
+	deposit := 0
+	user := model.User{Name: "Rina", Email: "rina@example.com", Password: "secret", Role: "user", Deposit: &deposit}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	_, err := repository.NewDepositTransactionRepository(db).Create(&model.DepositTransaction{
+		UserID: user.ID, OrderID: orderID, Deposit: amount, Status: model.DepositStatusPending,
+	})
+	if err != nil {
+		t.Fatalf("failed to seed deposit: %v", err)
+	}
+	return user
+}
+
+func newTestDepositService(db *gorm.DB, serverKey string) service.DepositTransactionService {
+	return service.NewDepositService(repository.NewDepositTransactionRepository(db), repository.NewUnitOfWork(db), nil, serverKey)
+}
+
+func TestDepositService_HandleWebhook_RejectsBadSignature(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+
+	notification := signedNotification("ORDER-1-1", "200", "50000.00", "settlement")
+	notification.GrossAmount = "5000000.00"
+	assert.ErrorIs(t, depositService.HandleWebhook(notification), service.ErrInvalidSignature)
+
+	notification = signedNotification("ORDER-1-1", "200", "50000.00", "settlement")
+	notification.SignatureKey = "forged"
+	assert.ErrorIs(t, depositService.HandleWebhook(notification), service.ErrInvalidSignature)
+
+	// Without a server key nothing can be verified
+	assert.ErrorIs(t, newTestDepositService(db, "").HandleWebhook(signedNotification("ORDER-1-1", "200", "50000.00", "settlement")),
+		service.ErrInvalidSignature)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 0, *checkedUser.Deposit)
+
+	// Notifications that cannot be verified are not stored
+	notifications, err := repository.NewDepositNotificationRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Empty(t, notifications)
+}
+
+func TestDepositService_HandleWebhook_Postgres(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositRepo := repository.NewDepositTransactionRepository(db)
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+
+	err := depositService.HandleWebhook(signedNotification("ORDER-404", "200", "50000.00", "settlement"))
+	assert.ErrorIs(t, err, service.ErrDepositOrderNotFound)
+
+	// Correctly signed, but for a different amount than was ordered
+	err = depositService.HandleWebhook(signedNotification("ORDER-1-1", "200", "5000000.00", "settlement"))
+	assert.ErrorIs(t, err, service.ErrGrossAmountMismatch)
+
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-1-1", "200", "50000.00", "settlement")))
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+
+	tx, err := depositRepo.GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusSettlement, tx.Status)
+	assert.NotNil(t, tx.PaidAt)
+}
+
+func TestDepositService_HandleWebhook_CreditsOnce(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+
+	// A card payment is captured, then settles, and Midtrans retries both
+	for _, status := range []string{"capture", "capture", "settlement", "settlement", "expire"} {
+		notification := signedNotification("ORDER-1-1", "200", "50000.00", status)
+		if status == model.DepositStatusCapture {
+			notification.FraudStatus = model.FraudStatusAccept
+		}
+		assert.NoError(t, depositService.HandleWebhook(notification))
+	}
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+
+	var topUps int64
+	db.Model(&model.LedgerEntry{}).Where("type = ? AND account = ?", model.LedgerTypeTopUp, model.LedgerAccountWallet).Count(&topUps)
+	assert.Equal(t, int64(1), topUps)
+
+	tx, err := repository.NewDepositTransactionRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusSettlement, tx.Status)
+
+	notifications, err := repository.NewDepositNotificationRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	outcomes := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		outcomes = append(outcomes, notification.Outcome)
+	}
+	assert.Equal(t, []string{
+		model.NotificationOutcomeApplied, model.NotificationOutcomeIgnored,
+		model.NotificationOutcomeApplied, model.NotificationOutcomeIgnored,
+		model.NotificationOutcomeIgnored,
+	}, outcomes)
+}
+
+func TestDepositService_HandleWebhook_ChallengedCapture(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+	depositRepo := repository.NewDepositTransactionRepository(db)
+
+	// A capture held for fraud review pays nothing and leaves the deposit pending
+	challenged := signedNotification("ORDER-1-1", "201", "50000.00", "capture")
+	challenged.FraudStatus = model.FraudStatusChallenge
+	assert.NoError(t, depositService.HandleWebhook(challenged))
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 0, *checkedUser.Deposit)
+	tx, err := depositRepo.GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusPending, tx.Status)
+	assert.Nil(t, tx.PaidAt)
+
+	notifications, err := repository.NewDepositNotificationRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, model.NotificationOutcomeIgnored, notifications[0].Outcome)
+	assert.Equal(t, model.FraudStatusChallenge, notifications[0].FraudStatus)
+
+	// Once the review passes the payment settles and is credited
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-1-1", "200", "50000.00", "settlement")))
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+}
+
+func TestDepositService_HandleWebhook_DeniedCapture(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+
+	denied := signedNotification("ORDER-1-1", "200", "50000.00", "capture")
+	denied.FraudStatus = model.FraudStatusDeny
+	assert.NoError(t, depositService.HandleWebhook(denied))
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 0, *checkedUser.Deposit)
+
+	tx, err := repository.NewDepositTransactionRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusDeny, tx.Status)
+	assert.Nil(t, tx.PaidAt)
+}
+
+func TestDepositService_HandleWebhook_SettlementAfterExpire(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-1-1", "407", "50000.00", "expire")))
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-1-1", "200", "50000.00", "settlement")))
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 0, *checkedUser.Deposit)
+
+	tx, err := repository.NewDepositTransactionRepository(db).GetByOrderID("ORDER-1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusExpire, tx.Status)
+	assert.Nil(t, tx.PaidAt)
+}
+
+func TestDepositService_FakeGatewayEndToEnd(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+
+	// The webhook is served over HTTP, the way the fake gateway reaches it in development
+	e := echo.New()
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	fake := gateway.NewFakeGateway(server.URL, server.URL+"/webhook/deposit", testServerKey)
+	depositService := service.NewDepositService(repository.NewDepositTransactionRepository(db), repository.NewUnitOfWork(db), fake, testServerKey)
+	e.POST("/webhook/deposit", handler.NewDepositTransactionHandler(depositService).Webhook)
+
+	deposit := 0
+	user := model.User{Name: "Rina", Email: "rina@example.com", Password: "secret", Role: "user", Deposit: &deposit}
+	assert.NoError(t, db.Create(&user).Error)
+
+	paid, err := depositService.CreateTransaction(user.ID, 50000, "")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/fake-pay/"+paid.OrderID, paid.RedirectURL)
+	assert.NoError(t, fake.Settle(paid.OrderID))
+	assert.NoError(t, fake.Settle(paid.OrderID))
+
+	abandoned, err := depositService.CreateTransaction(user.ID, 20000, "")
+	assert.NoError(t, err)
+	assert.NoError(t, fake.Expire(abandoned.OrderID))
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+
+	tx, err := repository.NewDepositTransactionRepository(db).GetByOrderID(abandoned.OrderID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DepositStatusExpire, tx.Status)
+}
+
+func TestDepositService_ListAndGetTransactions(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{})
+	depositService := newTestDepositService(db, testServerKey)
+	user := seedPendingDeposit(t, db, "ORDER-1-1", 50000)
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-1-1", "200", "50000.00", "settlement")))
+	_, err := repository.NewDepositTransactionRepository(db).Create(&model.DepositTransaction{
+		UserID: user.ID, OrderID: "ORDER-1-2", Deposit: 20000, Status: model.DepositStatusPending,
+	})
+	assert.NoError(t, err)
+
+	page, err := depositService.ListTransactions(user.ID, "", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, 20, page.Limit)
+
+	page, err = depositService.ListTransactions(user.ID, model.DepositStatusSettlement, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, page.Deposits, 1)
+	assert.Equal(t, "ORDER-1-1", page.Deposits[0].OrderID)
+
+	_, err = depositService.ListTransactions(user.ID, "paid", 1, 10)
+	assert.ErrorIs(t, err, service.ErrInvalidDepositStatus)
+
+	tx, err := depositService.GetTransaction(user.ID, "ORDER-1-1")
+	assert.NoError(t, err)
+	assert.NotNil(t, tx.PaidAt)
+
+	// Someone else's order looks the same as a missing one
+	_, err = depositService.GetTransaction(user.ID+1, "ORDER-1-1")
+	assert.ErrorIs(t, err, service.ErrDepositOrderNotFound)
+}
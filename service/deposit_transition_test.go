@@ -0,0 +1,47 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionDeposit(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{model.DepositStatusPending, model.DepositStatusSettlement, true},
+		{model.DepositStatusPending, model.DepositStatusCapture, true},
+		{model.DepositStatusPending, model.DepositStatusExpire, true},
+		{model.DepositStatusCapture, model.DepositStatusSettlement, true},
+		{model.DepositStatusSettlement, model.DepositStatusSettlement, false},
+		{model.DepositStatusSettlement, model.DepositStatusExpire, false},
+		{model.DepositStatusExpire, model.DepositStatusSettlement, false},
+		{model.DepositStatusDeny, model.DepositStatusCapture, false},
+		{model.DepositStatusPending, "refund", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, canTransitionDeposit(tc.from, tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
+
+func TestDepositStatusOf(t *testing.T) {
+	cases := []struct {
+		transactionStatus, fraudStatus string
+		want                           string
+	}{
+		{model.DepositStatusCapture, model.FraudStatusAccept, model.DepositStatusCapture},
+		{model.DepositStatusCapture, model.FraudStatusChallenge, model.DepositStatusPending},
+		{model.DepositStatusCapture, model.FraudStatusDeny, model.DepositStatusDeny},
+		{model.DepositStatusCapture, "", model.DepositStatusPending},
+		{model.DepositStatusSettlement, "", model.DepositStatusSettlement},
+		{model.DepositStatusDeny, model.FraudStatusDeny, model.DepositStatusDeny},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, depositStatusOf(tc.transactionStatus, tc.fraudStatus), "%s/%s", tc.transactionStatus, tc.fraudStatus)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionCost(t *testing.T) {
+	membershipID := uint(1)
+	tests := []struct {
+		name    string
+		rental  model.Rental
+		percent int
+		want    int
+	}{
+		{"standard terms", model.Rental{DurationDays: 7, Price: 10000}, 100, 10000},
+		{"half price", model.Rental{DurationDays: 7, Price: 10000}, 50, 5000},
+		{"fourteen day plan", model.Rental{DurationDays: 14, Price: 18000}, 100, 9000},
+		{"covered by a membership", model.Rental{DurationDays: 7, MembershipID: &membershipID}, 100, 0},
+		{"no duration recorded", model.Rental{Price: 10000}, 100, 10000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extensionCost(tt.rental, 7, tt.percent))
+		})
+	}
+}
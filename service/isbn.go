@@ -0,0 +1,45 @@
+package service
+
+import "strings"
+
+// normalizeISBN strips the hyphens and spaces from an ISBN-10 or ISBN-13 and checks its
+// check digit. An ISBN-10 may end in X, which is returned in upper case.
+func normalizeISBN(raw string) (string, error) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(raw))
+
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, r := range isbn {
+			digit := int(r - '0')
+			if r == 'X' && i == 9 {
+				digit = 10
+			} else if r < '0' || r > '9' {
+				return "", ErrInvalidISBN
+			}
+			sum += (10 - i) * digit
+		}
+		if sum%11 != 0 {
+			return "", ErrInvalidISBN
+		}
+	case 13:
+		sum := 0
+		for i, r := range isbn {
+			if r < '0' || r > '9' {
+				return "", ErrInvalidISBN
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += weight * int(r-'0')
+		}
+		if sum%10 != 0 {
+			return "", ErrInvalidISBN
+		}
+	default:
+		return "", ErrInvalidISBN
+	}
+
+	return isbn, nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	valid := map[string]string{
+		"978-0-7352-1129-2": "9780735211292",
+		"9786022911937":     "9786022911937",
+		"0-306-40615-2":     "0306406152",
+		"0 8044 2957 x":     "080442957X",
+	}
+	for raw, want := range valid {
+		isbn, err := normalizeISBN(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, want, isbn)
+	}
+
+	for _, raw := range []string{"", "978-0-7352-1129-3", "0-306-40615-3", "X306406152", "97807352112A2", "12345"} {
+		_, err := normalizeISBN(raw)
+		assert.ErrorIs(t, err, ErrInvalidISBN, raw)
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"pojok-baca-api/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateLateFee(t *testing.T) {
+	dueDate := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		cfg        config.RentalConfig
+		returnedAt time.Time
+		want       int
+	}{
+		{"returned early", config.RentalConfig{LateFeePerDay: 2000}, dueDate.Add(-time.Hour), 0},
+		{"returned on due date", config.RentalConfig{LateFeePerDay: 2000}, dueDate, 0},
+		{"started day is charged", config.RentalConfig{LateFeePerDay: 2000}, dueDate.Add(time.Hour), 2000},
+		{"three days late", config.RentalConfig{LateFeePerDay: 2000}, dueDate.Add(72 * time.Hour), 6000},
+		{"percentage of rental cost", config.RentalConfig{LateFeePerDay: 2000, LateFeePercent: 10}, dueDate.Add(48 * time.Hour), 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calculateLateFee(tt.cfg, 20000, &dueDate, tt.returnedAt))
+		})
+	}
+}
+
+func TestCalculateLateFee_NoDueDate(t *testing.T) {
+	assert.Equal(t, 0, calculateLateFee(config.RentalConfig{LateFeePerDay: 2000}, 20000, nil, time.Now()))
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMembershipPlanNotFound = errors.New("membership plan not found")
+	ErrInvalidMembershipPlan  = errors.New("name, a price, duration days and a quota of zero or more are required")
+	ErrMembershipNotFound     = errors.New("no active membership")
+	ErrMembershipActive       = errors.New("a membership is already active")
+)
+
+// newMembershipPeriod is a period of plan for userID starting at start.
+func newMembershipPeriod(userID uint, plan model.MembershipPlan, start time.Time, autoRenew bool) model.Membership {
+	return model.Membership{
+		UserID:      userID,
+		PlanID:      plan.ID,
+		Price:       plan.Price,
+		RentalQuota: plan.RentalQuota,
+		StartsAt:    start,
+		ExpiresAt:   start.AddDate(0, 0, plan.DurationDays),
+		AutoRenew:   autoRenew,
+		Status:      model.MembershipStatusActive,
+	}
+}
+
+// startMembershipPeriod creates a period of plan for a locked user and debits its
+// price from the deposit.
+func startMembershipPeriod(repos repository.Repositories, user *model.User, plan model.MembershipPlan, start time.Time, autoRenew bool) (model.Membership, error) {
+	membership, err := repos.Membership.Create(newMembershipPeriod(user.ID, plan, start, autoRenew))
+	if err != nil {
+		return model.Membership{}, err
+	}
+
+	err = postToWallet(repos, user, Posting{
+		Amount:      -plan.Price,
+		Type:        model.LedgerTypeMembershipFee,
+		Description: "Membership " + plan.Name + " until " + membership.ExpiresAt.Format("2006-01-02"),
+	})
+	return membership, err
+}
+
+// closeMembershipPeriod ends a period of a locked user that ran out. With auto-renewal
+// on, a new period of the same plan starts and is paid from the deposit; when the plan
+// was withdrawn or the deposit does not cover it, the membership expires. It returns
+// the status the period was closed with.
+func closeMembershipPeriod(repos repository.Repositories, user *model.User, membership model.Membership, now time.Time) (string, error) {
+	plan, err := repos.Membership.GetPlanByID(membership.PlanID)
+	if err != nil {
+		return "", err
+	}
+
+	membership.Status = model.MembershipStatusExpired
+	canRenew := membership.AutoRenew && plan.Active && user.Deposit != nil && *user.Deposit >= plan.Price
+	if canRenew {
+		membership.Status = model.MembershipStatusRenewed
+	}
+	if _, err := repos.Membership.Update(membership); err != nil {
+		return "", err
+	}
+
+	if canRenew {
+		if _, err := startMembershipPeriod(repos, user, plan, now, true); err != nil {
+			return "", err
+		}
+	}
+	return membership.Status, nil
+}
+
+type MembershipService interface {
+	ListPlans() ([]model.MembershipPlan, error)
+	CreatePlan(plan model.MembershipPlan) (model.MembershipPlan, error)
+	Subscribe(userID uint, planID uint, autoRenew bool) (model.Membership, error)
+	GetActiveMembership(userID uint) (model.Membership, error)
+	SetAutoRenew(userID uint, autoRenew bool) (model.Membership, error)
+	RenewMemberships() (int, int, error)
+}
+
+type membershipService struct {
+	repo repository.MembershipRepository
+	uow  repository.UnitOfWork
+}
+
+func NewMembershipService(repo repository.MembershipRepository, uow repository.UnitOfWork) MembershipService {
+	return &membershipService{repo: repo, uow: uow}
+}
+
+func (s *membershipService) ListPlans() ([]model.MembershipPlan, error) {
+	return s.repo.ListPlans()
+}
+
+func (s *membershipService) CreatePlan(plan model.MembershipPlan) (model.MembershipPlan, error) {
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.Active = true
+	if plan.Name == "" || plan.Price <= 0 || plan.DurationDays <= 0 || plan.RentalQuota < 0 {
+		return model.MembershipPlan{}, ErrInvalidMembershipPlan
+	}
+	return s.repo.CreatePlan(plan)
+}
+
+// Subscribe buys a period of a plan for a user, paid from the deposit. A user has at
+// most one active membership. A period that ran out but was not closed by
+// RenewMemberships yet is closed first, so it cannot be renewed next to the new one.
+func (s *membershipService) Subscribe(userID uint, planID uint, autoRenew bool) (model.Membership, error) {
+	active := false
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		//Lock user before membership, every writer takes them in this order
+		user, err := repos.User.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		periods, err := repos.Membership.ListActiveByUserIDForUpdate(user.ID)
+		if err != nil {
+			return err
+		}
+		for _, period := range periods {
+			if period.ExpiresAt.After(now) {
+				active = true
+				return nil
+			}
+			status, err := closeMembershipPeriod(repos, &user, period, now)
+			if err != nil {
+				return err
+			}
+			//A renewal is kept, the user is a member again
+			if status == model.MembershipStatusRenewed {
+				active = true
+				return nil
+			}
+		}
+
+		plan, err := repos.Membership.GetPlanByID(planID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !plan.Active) {
+			return ErrMembershipPlanNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.Deposit != nil && *user.Deposit < 0 {
+			return ErrOutstandingBalance
+		}
+		if user.Deposit == nil || *user.Deposit < plan.Price {
+			return ErrInsufficientDeposit
+		}
+
+		_, err = startMembershipPeriod(repos, &user, plan, now, autoRenew)
+		return err
+	})
+	if err != nil {
+		return model.Membership{}, err
+	}
+	if active {
+		return model.Membership{}, ErrMembershipActive
+	}
+
+	return s.GetActiveMembership(userID)
+}
+
+// GetActiveMembership returns the running period of a user with its plan.
+func (s *membershipService) GetActiveMembership(userID uint) (model.Membership, error) {
+	membership, err := s.repo.GetActiveByUserID(userID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Membership{}, ErrMembershipNotFound
+	}
+	return membership, err
+}
+
+// SetAutoRenew turns renewing the active membership from the deposit on or off.
+func (s *membershipService) SetAutoRenew(userID uint, autoRenew bool) (model.Membership, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		membership, err := repos.Membership.GetActiveByUserIDForUpdate(userID, time.Now())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMembershipNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		membership.AutoRenew = autoRenew
+		_, err = repos.Membership.Update(membership)
+		return err
+	})
+	if err != nil {
+		return model.Membership{}, err
+	}
+
+	return s.GetActiveMembership(userID)
+}
+
+// RenewMemberships closes every active period that ran out, renewing or expiring it as
+// closeMembershipPeriod does. It returns how many were renewed and how many expired.
+func (s *membershipService) RenewMemberships() (int, int, error) {
+	due, err := s.repo.GetDue(time.Now())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	renewed, expired := 0, 0
+	for _, period := range due {
+		outcome := ""
+		err := s.uow.Do(func(repos repository.Repositories) error {
+			user, err := repos.User.GetByIDForUpdate(period.UserID)
+			if err != nil {
+				return err
+			}
+
+			//Read again under the lock, the user may have changed it in between
+			membership, err := repos.Membership.GetByIDForUpdate(period.ID)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			if membership.Status != model.MembershipStatusActive || membership.ExpiresAt.After(now) {
+				return nil
+			}
+
+			outcome, err = closeMembershipPeriod(repos, &user, membership, now)
+			return err
+		})
+		if err != nil {
+			return renewed, expired, err
+		}
+
+		switch outcome {
+		case model.MembershipStatusRenewed:
+			renewed++
+		case model.MembershipStatusExpired:
+			expired++
+		}
+	}
+
+	return renewed, expired, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MembershipServiceMock struct {
+	mock.Mock
+}
+
+func (m *MembershipServiceMock) ListPlans() ([]model.MembershipPlan, error) {
+	args := m.Called()
+	return args.Get(0).([]model.MembershipPlan), args.Error(1)
+}
+
+func (m *MembershipServiceMock) CreatePlan(plan model.MembershipPlan) (model.MembershipPlan, error) {
+	args := m.Called(plan)
+	return args.Get(0).(model.MembershipPlan), args.Error(1)
+}
+
+func (m *MembershipServiceMock) Subscribe(userID uint, planID uint, autoRenew bool) (model.Membership, error) {
+	args := m.Called(userID, planID, autoRenew)
+	return args.Get(0).(model.Membership), args.Error(1)
+}
+
+func (m *MembershipServiceMock) GetActiveMembership(userID uint) (model.Membership, error) {
+	args := m.Called(userID)
+	return args.Get(0).(model.Membership), args.Error(1)
+}
+
+func (m *MembershipServiceMock) SetAutoRenew(userID uint, autoRenew bool) (model.Membership, error) {
+	args := m.Called(userID, autoRenew)
+	return args.Get(0).(model.Membership), args.Error(1)
+}
+
+func (m *MembershipServiceMock) RenewMemberships() (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipService_QuotaCoversRentals(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.MembershipPlan{}, &model.Membership{})
+	uow := repository.NewUnitOfWork(db)
+	membershipService := service.NewMembershipService(repository.NewMembershipRepository(db), uow)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{})
+
+	user, book := seedUserAndBook(t, db, 60000, 5, 10000)
+	plan, err := membershipService.CreatePlan(model.MembershipPlan{Name: "Basic", Price: 50000, DurationDays: 30, RentalQuota: 2})
+	assert.NoError(t, err)
+
+	membership, err := membershipService.Subscribe(user.ID, plan.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic", membership.Plan.Name)
+	assert.Equal(t, 10000, depositOf(t, db, user.ID))
+
+	_, err = membershipService.Subscribe(user.ID, plan.ID, false)
+	assert.ErrorIs(t, err, service.ErrMembershipActive)
+
+	// Two rentals are covered, the third is paid
+	for i := 0; i < 2; i++ {
+		rental, err := rentalService.CreateRental(user.ID, book.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, rental.Price)
+		assert.Equal(t, &membership.ID, rental.MembershipID)
+	}
+	assert.Equal(t, 10000, depositOf(t, db, user.ID))
+
+	paid, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, paid.Price)
+	assert.Nil(t, paid.MembershipID)
+	assert.Equal(t, 0, depositOf(t, db, user.ID))
+
+	membership, err = membershipService.GetActiveMembership(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, membership.RemainingQuota())
+}
+
+func TestMembershipService_RenewMemberships(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.LedgerEntry{}, &model.MembershipPlan{}, &model.Membership{})
+	membershipService := service.NewMembershipService(repository.NewMembershipRepository(db), repository.NewUnitOfWork(db))
+
+	plan, err := membershipService.CreatePlan(model.MembershipPlan{Name: "Premium", Price: 40000, DurationDays: 30})
+	assert.NoError(t, err)
+
+	renewing := seedWallet(t, db, 100000)
+	_, err = membershipService.Subscribe(renewing.ID, plan.ID, true)
+	assert.NoError(t, err)
+
+	broke := model.User{Name: "Budi", Email: "budi@example.com", Password: "secret", Role: "user"}
+	deposit := 50000
+	broke.Deposit = &deposit
+	assert.NoError(t, db.Create(&broke).Error)
+	_, err = membershipService.Subscribe(broke.ID, plan.ID, true)
+	assert.NoError(t, err)
+
+	// Both periods ran out yesterday
+	yesterday := time.Now().Add(-24 * time.Hour)
+	assert.NoError(t, db.Model(&model.Membership{}).Where("status = ?", model.MembershipStatusActive).Update("expires_at", yesterday).Error)
+
+	renewed, expired, err := membershipService.RenewMemberships()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, renewed)
+	assert.Equal(t, 1, expired)
+
+	membership, err := membershipService.GetActiveMembership(renewing.ID)
+	assert.NoError(t, err)
+	assert.True(t, membership.ExpiresAt.After(time.Now()))
+	assert.Equal(t, 100000-2*40000, depositOf(t, db, renewing.ID))
+
+	_, err = membershipService.GetActiveMembership(broke.ID)
+	assert.ErrorIs(t, err, service.ErrMembershipNotFound)
+	assert.Equal(t, 10000, depositOf(t, db, broke.ID))
+
+	// Nothing is due any more
+	renewed, expired, err = membershipService.RenewMemberships()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, renewed+expired)
+}
+
+func TestMembershipService_SubscribeOverUnprocessedPeriod(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.LedgerEntry{}, &model.MembershipPlan{}, &model.Membership{})
+	membershipService := service.NewMembershipService(repository.NewMembershipRepository(db), repository.NewUnitOfWork(db))
+
+	basic, err := membershipService.CreatePlan(model.MembershipPlan{Name: "Basic", Price: 20000, DurationDays: 30})
+	assert.NoError(t, err)
+	premium, err := membershipService.CreatePlan(model.MembershipPlan{Name: "Premium", Price: 40000, DurationDays: 30})
+	assert.NoError(t, err)
+
+	user := seedWallet(t, db, 100000)
+	_, err = membershipService.Subscribe(user.ID, basic.ID, true)
+	assert.NoError(t, err)
+
+	// The period ran out, but RenewMemberships has not seen it yet
+	yesterday := time.Now().Add(-24 * time.Hour)
+	assert.NoError(t, db.Model(&model.Membership{}).Where("user_id = ?", user.ID).Update("expires_at", yesterday).Error)
+
+	// It renews on subscribing, which leaves the user with a membership already
+	_, err = membershipService.Subscribe(user.ID, premium.ID, true)
+	assert.ErrorIs(t, err, service.ErrMembershipActive)
+	assert.Equal(t, 100000-2*20000, depositOf(t, db, user.ID))
+
+	// Without auto-renewal it expires, and the new plan is bought in its place
+	assert.NoError(t, db.Model(&model.Membership{}).Where("user_id = ? AND status = ?", user.ID, model.MembershipStatusActive).
+		Updates(map[string]interface{}{"expires_at": yesterday, "auto_renew": false}).Error)
+	membership, err := membershipService.Subscribe(user.ID, premium.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, premium.ID, membership.PlanID)
+	assert.Equal(t, 100000-2*20000-40000, depositOf(t, db, user.ID))
+
+	var active int64
+	assert.NoError(t, db.Model(&model.Membership{}).Where("user_id = ? AND status = ?", user.ID, model.MembershipStatusActive).Count(&active).Error)
+	assert.Equal(t, int64(1), active)
+
+	// Nothing is left for the scheduler to renew a second time
+	renewed, expired, err := membershipService.RenewMemberships()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, renewed+expired)
+	assert.Equal(t, 100000-2*20000-40000, depositOf(t, db, user.ID))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pojok-baca-api/config"
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+	"time"
+)
+
+// PaymentReconciliationService catches up on deposits whose notification never
+// arrived, by asking the gateway for their status.
+type PaymentReconciliationService interface {
+	ReconcilePending() (model.ReconciliationReport, error)
+}
+
+type paymentReconciliationService struct {
+	depositRepo repository.DepositTransactionRepository
+	reportRepo  repository.ReconciliationReportRepository
+	uow         repository.UnitOfWork
+	gateway     gateway.PaymentGateway
+	cfg         config.PaymentConfig
+}
+
+func NewPaymentReconciliationService(depositRepo repository.DepositTransactionRepository, reportRepo repository.ReconciliationReportRepository,
+	uow repository.UnitOfWork, paymentGateway gateway.PaymentGateway, cfg config.PaymentConfig) PaymentReconciliationService {
+	return &paymentReconciliationService{depositRepo, reportRepo, uow, paymentGateway, cfg}
+}
+
+// ReconcilePending asks the gateway about every deposit pending for longer than
+// ReconcileAfter and applies what it reports with the same transitions as the webhook.
+// Deposits the gateway does not know are expired once older than ExpireAfter. A run
+// that checked any deposit is stored as a report.
+func (s *paymentReconciliationService) ReconcilePending() (model.ReconciliationReport, error) {
+	report := model.ReconciliationReport{StartedAt: time.Now()}
+
+	deposits, err := s.depositRepo.GetPendingBefore(report.StartedAt.Add(-s.cfg.ReconcileAfter))
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+
+	var details []string
+	for _, tx := range deposits {
+		report.Checked++
+
+		status, err := s.gateway.GetStatus(tx.OrderID)
+		if errors.Is(err, gateway.ErrTransactionNotFound) {
+			if tx.CreatedAt.After(report.StartedAt.Add(-s.cfg.ExpireAfter)) {
+				report.Unchanged++
+				continue
+			}
+			status = gateway.TransactionStatus{
+				OrderID:           tx.OrderID,
+				GrossAmount:       fmt.Sprintf("%d.00", tx.Deposit),
+				TransactionStatus: model.DepositStatusExpire,
+			}
+		} else if err != nil {
+			report.Failed++
+			details = append(details, fmt.Sprintf("%s: gateway error: %v", tx.OrderID, err))
+			continue
+		}
+
+		if status.TransactionStatus == tx.Status {
+			report.Unchanged++
+			continue
+		}
+
+		outcome, err := applyDepositStatus(s.uow, model.DepositNotification{
+			OrderID:           tx.OrderID,
+			TransactionStatus: status.TransactionStatus,
+			FraudStatus:       status.FraudStatus,
+			StatusCode:        status.StatusCode,
+			GrossAmount:       status.GrossAmount,
+			Source:            model.NotificationSourceReconciler,
+		})
+		switch {
+		case err != nil:
+			report.Failed++
+			details = append(details, fmt.Sprintf("%s: %s -> %s failed: %v", tx.OrderID, tx.Status, status.TransactionStatus, err))
+		case outcome != model.NotificationOutcomeApplied:
+			report.Unchanged++
+			details = append(details, fmt.Sprintf("%s: %s -> %s %s", tx.OrderID, tx.Status, status.TransactionStatus, outcome))
+		case status.TransactionStatus == model.DepositStatusExpire:
+			report.Expired++
+			details = append(details, fmt.Sprintf("%s: %s -> %s", tx.OrderID, tx.Status, status.TransactionStatus))
+		default:
+			report.Updated++
+			details = append(details, fmt.Sprintf("%s: %s -> %s", tx.OrderID, tx.Status, status.TransactionStatus))
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	report.Details = strings.Join(details, "\n")
+	if report.Checked == 0 {
+		return report, nil
+	}
+	return s.reportRepo.Create(report)
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pojok-baca-api/config"
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentReconciliationService_ReconcilePending(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{}, &model.ReconciliationReport{})
+
+	// A stub of the Midtrans status endpoint; orders not listed do not exist there
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodies := map[string]string{
+			"/v2/ORDER-PAID/status":    `{"status_code": "200", "order_id": "ORDER-PAID", "gross_amount": "50000.00", "transaction_status": "settlement"}`,
+			"/v2/ORDER-WAITING/status": `{"status_code": "201", "order_id": "ORDER-WAITING", "gross_amount": "10000.00", "transaction_status": "pending"}`,
+			"/v2/ORDER-LAPSED/status":  `{"status_code": "407", "order_id": "ORDER-LAPSED", "gross_amount": "10000.00", "transaction_status": "expire"}`,
+			"/v2/ORDER-WRONG/status":   `{"status_code": "200", "order_id": "ORDER-WRONG", "gross_amount": "99000.00", "transaction_status": "settlement"}`,
+		}
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			body = `{"status_code": "404", "status_message": "Transaction doesn't exist."}`
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer stub.Close()
+
+	cfg := config.PaymentConfig{ServerKey: testServerKey, ReconcileAfter: 15 * time.Minute, ExpireAfter: 24 * time.Hour}
+	reconciliationService := service.NewPaymentReconciliationService(
+		repository.NewDepositTransactionRepository(db), repository.NewReconciliationReportRepository(db),
+		repository.NewUnitOfWork(db), gateway.NewMidtransGateway(testServerKey, false, stub.URL), cfg)
+
+	user := seedPendingDeposit(t, db, "ORDER-PAID", 50000)
+	now := time.Now()
+	deposits := []struct {
+		orderID string
+		amount  int
+		age     time.Duration
+	}{
+		{"ORDER-WAITING", 10000, time.Hour},
+		{"ORDER-LAPSED", 10000, time.Hour},
+		{"ORDER-WRONG", 10000, time.Hour},
+		{"ORDER-ABANDONED", 10000, 48 * time.Hour},
+		{"ORDER-UNOPENED", 10000, time.Hour},
+		{"ORDER-FRESH", 10000, time.Minute},
+	}
+	for _, d := range deposits {
+		tx := model.DepositTransaction{UserID: user.ID, OrderID: d.orderID, Deposit: d.amount, Status: model.DepositStatusPending}
+		tx.CreatedAt = now.Add(-d.age)
+		assert.NoError(t, db.Create(&tx).Error)
+	}
+	assert.NoError(t, db.Model(&model.DepositTransaction{}).Where("order_id = ?", "ORDER-PAID").
+		Update("created_at", now.Add(-time.Hour)).Error)
+
+	report, err := reconciliationService.ReconcilePending()
+	assert.NoError(t, err)
+	assert.NotZero(t, report.ID)
+	assert.Equal(t, 6, report.Checked)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Expired)
+	assert.Equal(t, 2, report.Unchanged)
+	assert.Equal(t, 1, report.Failed)
+	assert.Contains(t, report.Details, "ORDER-PAID: pending -> settlement")
+	assert.Contains(t, report.Details, "ORDER-WRONG")
+
+	statuses := map[string]string{}
+	var all []model.DepositTransaction
+	assert.NoError(t, db.Find(&all).Error)
+	for _, tx := range all {
+		statuses[tx.OrderID] = tx.Status
+	}
+	assert.Equal(t, map[string]string{
+		"ORDER-PAID":      model.DepositStatusSettlement,
+		"ORDER-WAITING":   model.DepositStatusPending,
+		"ORDER-LAPSED":    model.DepositStatusExpire,
+		"ORDER-WRONG":     model.DepositStatusPending,
+		"ORDER-ABANDONED": model.DepositStatusExpire,
+		"ORDER-UNOPENED":  model.DepositStatusPending,
+		"ORDER-FRESH":     model.DepositStatusPending,
+	}, statuses)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+
+	// A second run finds nothing new to do and credits nothing twice
+	report, err = reconciliationService.ReconcilePending()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 0, report.Updated)
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000, *checkedUser.Deposit)
+
+	// A run with nothing pending leaves no report behind
+	assert.NoError(t, db.Model(&model.DepositTransaction{}).Where("status = ?", model.DepositStatusPending).
+		Update("status", model.DepositStatusExpire).Error)
+	report, err = reconciliationService.ReconcilePending()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Checked)
+	assert.Zero(t, report.ID)
+	var reports int64
+	assert.NoError(t, db.Model(&model.ReconciliationReport{}).Count(&reports).Error)
+	assert.Equal(t, int64(2), reports)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPricingPlan = errors.New("name, duration days, price and either book_id or category required")
+
+type PricingPlanService interface {
+	GetPlansForBook(bookID uint) ([]model.PricingPlan, error)
+	CreatePlan(plan model.PricingPlan) (model.PricingPlan, error)
+	DeletePlan(id uint) error
+}
+
+type pricingPlanService struct {
+	repo         repository.PricingPlanRepository
+	bookRepo     repository.BookRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewPricingPlanService(repo repository.PricingPlanRepository, bookRepo repository.BookRepository, categoryRepo repository.CategoryRepository) PricingPlanService {
+	return &pricingPlanService{repo: repo, bookRepo: bookRepo, categoryRepo: categoryRepo}
+}
+
+func (s *pricingPlanService) GetPlansForBook(bookID uint) ([]model.PricingPlan, error) {
+	book, err := s.bookRepo.GetByID(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.categoryRepo.GetTreeOfBook(book.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.repo.GetForBookOrCategories(book.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]model.PricingPlan, 0, len(candidates))
+	for _, plan := range candidates {
+		if plan.AppliesTo(book, tree) {
+			plans = append(plans, plan)
+		}
+	}
+	return plans, nil
+}
+
+// CreatePlan stores a plan for a book or for a stored category, named by its name or
+// slug. A category plan keeps the name of the category, as books and renames do.
+func (s *pricingPlanService) CreatePlan(plan model.PricingPlan) (model.PricingPlan, error) {
+	//A plan is either for one book or for a whole category
+	if plan.Name == "" || plan.DurationDays <= 0 || plan.Price < 0 || (plan.BookID == nil) == (plan.Category == "") {
+		return model.PricingPlan{}, ErrInvalidPricingPlan
+	}
+
+	if plan.BookID != nil {
+		_, err := s.bookRepo.GetByID(*plan.BookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.PricingPlan{}, ErrBookNotFound
+		}
+		if err != nil {
+			return model.PricingPlan{}, err
+		}
+	}
+	if plan.Category != "" {
+		category, err := findCategory(s.categoryRepo, plan.Category)
+		if err != nil {
+			return model.PricingPlan{}, err
+		}
+		plan.Category = category.Name
+	}
+
+	return s.repo.Create(plan)
+}
+
+func (s *pricingPlanService) DeletePlan(id uint) error {
+	return s.repo.Delete(id)
+}
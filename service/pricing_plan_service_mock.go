@@ -0,0 +1,26 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PricingPlanServiceMock struct {
+	mock.Mock
+}
+
+func (m *PricingPlanServiceMock) GetPlansForBook(bookID uint) ([]model.PricingPlan, error) {
+	args := m.Called(bookID)
+	return args.Get(0).([]model.PricingPlan), args.Error(1)
+}
+
+func (m *PricingPlanServiceMock) CreatePlan(plan model.PricingPlan) (model.PricingPlan, error) {
+	args := m.Called(plan)
+	return args.Get(0).(model.PricingPlan), args.Error(1)
+}
+
+func (m *PricingPlanServiceMock) DeletePlan(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
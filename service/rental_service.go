@@ -2,31 +2,901 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"pojok-baca-api/config"
 	"pojok-baca-api/model"
 	"pojok-baca-api/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRentalNotFound          = errors.New("rental not found")
+	ErrInvalidRentalTransition = errors.New("invalid rental status transition")
+	ErrBookNotFound            = errors.New("book not found")
+	ErrBookNotAvailable        = errors.New("book not available")
+	ErrDepositNotFound         = errors.New("deposit not found for this user")
+	ErrInsufficientDeposit     = errors.New("insufficient deposit")
+	ErrRentalNotExtendable     = errors.New("only borrowed rentals can be extended")
+	ErrRenewalLimitReached     = errors.New("rental renewal limit reached")
+	ErrEmptyCart               = errors.New("cart is empty")
+	ErrInvalidCartItem         = errors.New("every cart item needs a book_id and a quantity of at least 1")
+	ErrPricingPlanNotFound     = errors.New("pricing plan not available for this book")
+	ErrReasonRequired          = errors.New("a reason is required")
+	ErrInvalidRentalFilter     = errors.New("invalid rental filter")
+	ErrOutstandingBalance      = errors.New("deposit has an outstanding negative balance")
+	ErrInvalidDamageCondition  = errors.New("condition must be Fair, Poor or Damaged")
+)
+
+// standardRentalDays is the rental duration when no pricing plan is chosen.
+const standardRentalDays = 7
+
+// Page size of paginated lists when none or too large is asked for.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
 )
 
+// rentalTransitions lists, for every rental status, the statuses it may move to.
+// Returned, Lost and Cancelled are final.
+var rentalTransitions = map[string][]string{
+	model.RentalStatusBorrowed: {model.RentalStatusReturned, model.RentalStatusOverdue, model.RentalStatusLost, model.RentalStatusCancelled},
+	model.RentalStatusOverdue:  {model.RentalStatusReturned, model.RentalStatusLost, model.RentalStatusCancelled},
+}
+
+func canTransitionRental(from, to string) bool {
+	for _, next := range rentalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CartItem is one line of a checkout cart. PlanID is optional, the standard
+// price of the book applies without it.
+type CartItem struct {
+	BookID   uint
+	PlanID   *uint
+	Quantity int
+}
+
+// normalizeCart validates the cart, merges lines of the same book and plan, and
+// sorts the result by book ID.
+func normalizeCart(items []CartItem) ([]CartItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	type lineKey struct{ bookID, planID uint }
+	var (
+		merged []CartItem
+		index  = make(map[lineKey]int)
+	)
+	for _, item := range items {
+		if item.BookID == 0 || item.Quantity < 1 {
+			return nil, ErrInvalidCartItem
+		}
+
+		key := lineKey{bookID: item.BookID}
+		if item.PlanID != nil {
+			key.planID = *item.PlanID
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].BookID < merged[j].BookID })
+
+	return merged, nil
+}
+
+// rentalTerms is how long a rental runs and what it costs.
+type rentalTerms struct {
+	planID       *uint
+	durationDays int
+	price        int
+}
+
+// resolveTerms returns the terms of the chosen plan, or the standard terms of the
+// book when no plan is chosen. The plan must belong to the book, to one of its
+// categories or to a category above them.
+func resolveTerms(repos repository.Repositories, book model.Book, planID *uint) (rentalTerms, error) {
+	if planID == nil {
+		return rentalTerms{durationDays: standardRentalDays, price: book.RentalCost}, nil
+	}
+
+	plan, err := repos.PricingPlan.GetByID(*planID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rentalTerms{}, ErrPricingPlanNotFound
+	}
+	if err != nil {
+		return rentalTerms{}, err
+	}
+	tree, err := repos.Category.GetTreeOfBook(book.ID)
+	if err != nil {
+		return rentalTerms{}, err
+	}
+	if !plan.AppliesTo(book, tree) {
+		return rentalTerms{}, ErrPricingPlanNotFound
+	}
+
+	return rentalTerms{planID: &plan.ID, durationDays: plan.DurationDays, price: plan.Price}, nil
+}
+
+// RentalPage is one page of the rental list. Page and Limit are the values
+// actually used after defaults were applied.
+type RentalPage struct {
+	Rentals []model.Rental
+	Total   int64
+	Page    int
+	Limit   int
+}
+
 type RentalService interface {
-	CreateRental(rental model.Rental) (model.Rental, error)
+	CreateRental(userID uint, bookID uint, planID *uint) (model.Rental, error)
+	Checkout(userID uint, items []CartItem, voucherCode string) (model.Checkout, error)
 	GetRentalByUserID(userID uint) ([]model.Rental, error)
+	ReturnRental(id uint, userID uint) (model.Rental, error)
+	ExtendRental(id uint, userID uint) (model.Rental, int, error)
+	MarkOverdueRentals() (int64, error)
+	ListRentals(filter repository.RentalFilter) (RentalPage, error)
+	ForceCloseRental(id uint, adminID uint, reason string) (model.Rental, error)
+	MarkRentalLost(id uint, adminID uint, reason string) (model.Rental, error)
+	CancelRental(id uint, adminID uint, reason string) (model.Rental, error)
+	ReportDamage(id uint, adminID uint, condition string, notes string) (model.Rental, error)
 }
 
 type rentalService struct {
 	repo repository.RentalRepository
+	uow  repository.UnitOfWork
+	cfg  config.RentalConfig
+}
+
+func NewRentalService(repo repository.RentalRepository, uow repository.UnitOfWork, cfg config.RentalConfig) RentalService {
+	return &rentalService{repo: repo, uow: uow, cfg: cfg}
+}
+
+// listPrice is what a rental costs on its own terms before any membership: the price of
+// its plan or, for a rental covered by a membership, the rental cost of its book.
+func listPrice(rental model.Rental, book model.Book) int {
+	if rental.MembershipID != nil {
+		return book.RentalCost
+	}
+	return rental.Price
+}
+
+// extensionCost is what extending a rental by days costs: percent of its price for as
+// many days, prorated from the days its terms cover. A rental covered by a membership
+// was not charged, so its extension is not either.
+func extensionCost(rental model.Rental, days int, percent int) int {
+	durationDays := rental.DurationDays
+	if durationDays <= 0 {
+		durationDays = standardRentalDays
+	}
+	return rental.Price * days * percent / (durationDays * 100)
+}
+
+// calculateLateFee returns the fee for returning a book at returnedAt. Every started
+// day past the due date is charged, either flat or as a percentage of price, the list
+// price of the rental.
+func calculateLateFee(cfg config.RentalConfig, price int, dueDate *time.Time, returnedAt time.Time) int {
+	if dueDate == nil || !returnedAt.After(*dueDate) {
+		return 0
+	}
+
+	daysLate := int(math.Ceil(returnedAt.Sub(*dueDate).Hours() / 24))
+	feePerDay := cfg.LateFeePerDay
+	if cfg.LateFeePercent > 0 {
+		feePerDay = price * cfg.LateFeePercent / 100
+	}
+	return daysLate * feePerDay
+}
+
+// takeCopies lends quantity copies of a locked book to a user and returns them. The copy
+// held for the user's ready reservation is used first, the rest are available copies.
+func takeCopies(repos repository.Repositories, userID uint, book model.Book, quantity int) ([]model.BookCopy, error) {
+	reservation, err := repos.Reservation.GetActiveForUpdate(userID, book.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var copies []model.BookCopy
+	if err == nil && reservation.Status == model.ReservationStatusReady && reservation.BookCopyID != nil {
+		held, err := repos.BookCopy.GetByIDForUpdate(*reservation.BookCopyID)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, held)
+
+		reservation.Status = model.ReservationStatusFulfilled
+		if _, err := repos.Reservation.Update(reservation); err != nil {
+			return nil, err
+		}
+	}
+
+	if rest := quantity - len(copies); rest > 0 {
+		available, err := repos.BookCopy.GetAvailableForUpdate(book.ID, rest)
+		if err != nil {
+			return nil, err
+		}
+		if len(available) < rest {
+			return nil, ErrBookNotAvailable
+		}
+		copies = append(copies, available...)
+	}
+
+	for i := range copies {
+		copies[i].Status = model.BookCopyStatusOnLoan
+		if _, err := repos.BookCopy.Update(copies[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return copies, repos.Book.SyncStock(book.ID)
 }
 
-func NewRentalService(repo repository.RentalRepository) RentalService {
-	return &rentalService{repo: repo}
+func newRental(userID uint, bookCopy model.BookCopy, checkoutID *uint, terms rentalTerms, rentDate time.Time) model.Rental {
+	returnDate := rentDate.AddDate(0, 0, terms.durationDays)
+	return model.Rental{
+		UserID:        userID,
+		BookID:        bookCopy.BookID,
+		BookCopyID:    &bookCopy.ID,
+		CheckoutID:    checkoutID,
+		PricingPlanID: terms.planID,
+		DurationDays:  terms.durationDays,
+		Price:         terms.price,
+		RentDate:      rentDate,
+		ReturnDate:    &returnDate,
+		Status:        model.RentalStatusBorrowed,
+	}
 }
 
-func (s *rentalService) CreateRental(rent model.Rental) (model.Rental, error) {
-	if rent.BookID == 0 {
+// CreateRental checks a book out for a user. The deposit debit, the stock decrement
+// and the rental insert happen in one transaction with the user and book rows locked,
+// so concurrent checkouts can neither overdraw the deposit nor oversell the stock.
+// A rental on the standard terms is covered by the user's membership while its quota
+// lasts and is not charged; past the quota the book is paid as usual.
+func (s *rentalService) CreateRental(userID uint, bookID uint, planID *uint) (model.Rental, error) {
+	if bookID == 0 {
 		return model.Rental{}, errors.New("BookID is required")
 	}
 
-	return s.repo.Create(rent)
+	var rental model.Rental
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		//Lock user before membership and book, every writer takes them in this order
+		user, err := repos.User.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+
+		var membership *model.Membership
+		if planID == nil {
+			active, err := repos.Membership.GetActiveByUserIDForUpdate(user.ID, time.Now())
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil && active.Covers() {
+				membership = &active
+			}
+		}
+
+		book, err := repos.Book.GetByIDForUpdate(bookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		terms, err := resolveTerms(repos, book, planID)
+		if err != nil {
+			return err
+		}
+		if membership != nil {
+			terms.price = 0
+		}
+		copies, err := takeCopies(repos, user.ID, book, 1)
+		if err != nil {
+			return err
+		}
+		if user.Deposit == nil {
+			return ErrDepositNotFound
+		}
+		if *user.Deposit < 0 {
+			return ErrOutstandingBalance
+		}
+		if *user.Deposit < terms.price {
+			return ErrInsufficientDeposit
+		}
+
+		rental = newRental(user.ID, copies[0], nil, terms, time.Now())
+		if membership != nil {
+			rental.MembershipID = &membership.ID
+			membership.RentalsUsed++
+			if _, err := repos.Membership.Update(*membership); err != nil {
+				return err
+			}
+		}
+		if rental, err = repos.Rental.Create(rental); err != nil {
+			return err
+		}
+		return postToWallet(repos, &user, Posting{
+			Amount:      -terms.price,
+			Type:        model.LedgerTypeRentalCharge,
+			RentalID:    &rental.ID,
+			Description: "Rental of " + book.Name,
+		})
+	})
+	if err != nil {
+		return model.Rental{}, err
+	}
+
+	return rental, nil
+}
+
+// Checkout rents every book in the cart at once. Availability and the total cost
+// are checked for the whole cart, then all rentals are created under a single checkout
+// and charged, all in the same transaction. An optional voucher code takes its
+// discount off the books it applies to and is redeemed in the same transaction.
+func (s *rentalService) Checkout(userID uint, items []CartItem, voucherCode string) (model.Checkout, error) {
+	items, err := normalizeCart(items)
+	if err != nil {
+		return model.Checkout{}, err
+	}
+
+	var checkout model.Checkout
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		user, err := repos.User.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+
+		//Books are locked in ascending ID order so two carts cannot deadlock
+		total := 0
+		terms := make([]rentalTerms, len(items))
+		copies := make([][]model.BookCopy, len(items))
+		books := make([]model.Book, len(items))
+		discounted := make([]bool, len(items))
+		for i, item := range items {
+			book, err := repos.Book.GetByIDForUpdate(item.BookID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: book %d", ErrBookNotFound, item.BookID)
+			}
+			if err != nil {
+				return err
+			}
+
+			if terms[i], err = resolveTerms(repos, book, item.PlanID); err != nil {
+				return fmt.Errorf("%w: book %d", err, item.BookID)
+			}
+			if copies[i], err = takeCopies(repos, user.ID, book, item.Quantity); err != nil {
+				return fmt.Errorf("%w: book %d", err, item.BookID)
+			}
+			total += terms[i].price * item.Quantity
+			books[i] = book
+		}
+
+		//The voucher is locked last, after the user and the books
+		rentDate := time.Now()
+		var voucher *model.Voucher
+		discount := 0
+		if voucherCode != "" {
+			locked, err := lockVoucher(repos, voucherCode, user.ID, model.VoucherScopeRental, rentDate)
+			if err != nil {
+				return err
+			}
+			if total < locked.MinAmount {
+				return ErrVoucherMinAmount
+			}
+
+			eligible := 0
+			for i, item := range items {
+				tree, err := repos.Category.GetTreeOfBook(books[i].ID)
+				if err != nil {
+					return err
+				}
+				discounted[i] = locked.AppliesTo(tree)
+				if discounted[i] {
+					eligible += terms[i].price * item.Quantity
+				}
+			}
+			if eligible == 0 {
+				return ErrVoucherNotApplicable
+			}
+			voucher = &locked
+			discount = voucherAmount(locked, eligible)
+		}
+
+		if user.Deposit == nil {
+			return ErrDepositNotFound
+		}
+		if *user.Deposit < 0 {
+			return ErrOutstandingBalance
+		}
+		if *user.Deposit < total-discount {
+			return ErrInsufficientDeposit
+		}
+
+		checkout = model.Checkout{UserID: user.ID, TotalCost: total - discount, Discount: discount}
+		if voucher != nil {
+			checkout.VoucherID = &voucher.ID
+		}
+		if checkout, err = repos.Checkout.Create(checkout); err != nil {
+			return err
+		}
+
+		//The discount is spread over the eligible rentals in cart order, so a cancelled
+		//rental refunds exactly what was paid for it
+		remaining := discount
+		for i := range items {
+			for _, bookCopy := range copies[i] {
+				rental := newRental(user.ID, bookCopy, &checkout.ID, terms[i], rentDate)
+				if voucher != nil && discounted[i] {
+					rental.Discount = min(remaining, rental.Price)
+					remaining -= rental.Discount
+				}
+				rental, err := repos.Rental.Create(rental)
+				if err != nil {
+					return err
+				}
+				checkout.Rentals = append(checkout.Rentals, rental)
+
+				err = postToWallet(repos, &user, Posting{
+					Amount:      -(rental.Price - rental.Discount),
+					Type:        model.LedgerTypeRentalCharge,
+					RentalID:    &rental.ID,
+					Description: "Rental of " + books[i].Name,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if voucher == nil {
+			return nil
+		}
+		_, err = repos.Voucher.CreateRedemption(model.VoucherRedemption{
+			VoucherID:  voucher.ID,
+			UserID:     user.ID,
+			CheckoutID: &checkout.ID,
+			Amount:     discount,
+			Status:     model.RedemptionStatusApplied,
+		})
+		return err
+	})
+	if err != nil {
+		return model.Checkout{}, err
+	}
+
+	return checkout, nil
 }
 
 func (s *rentalService) GetRentalByUserID(userID uint) ([]model.Rental, error) {
 	return s.repo.GetByUserID(userID)
 }
+
+// settleReturn closes a locked rental as returned now and charges the late fee plus
+// damageFee. It returns the total amount charged.
+func (s *rentalService) settleReturn(repos repository.Repositories, rental *model.Rental, damageFee int) (int, error) {
+	//Lock order is user, then book, same as checkout
+	user, err := repos.User.GetByIDForUpdate(rental.UserID)
+	if err != nil {
+		return 0, err
+	}
+	book, err := repos.Book.GetByIDForUpdate(rental.BookID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	rental.Status = model.RentalStatusReturned
+	rental.ReturnedAt = &now
+	rental.LateFee = calculateLateFee(s.cfg, listPrice(*rental, book), rental.ReturnDate, now)
+	rental.DamageFee = damageFee
+
+	if *rental, err = repos.Rental.Update(*rental); err != nil {
+		return 0, err
+	}
+
+	//Fees are always charged, even when they leave the deposit below zero
+	err = postToWallet(repos, &user, Posting{
+		Amount:      -rental.LateFee,
+		Type:        model.LedgerTypeLateFee,
+		RentalID:    &rental.ID,
+		Description: "Late return of " + book.Name,
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = postToWallet(repos, &user, Posting{
+		Amount:      -rental.DamageFee,
+		Type:        model.LedgerTypeDamageFee,
+		RentalID:    &rental.ID,
+		Description: "Repair of " + book.Name,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rental.LateFee + rental.DamageFee, nil
+}
+
+// completeReturn closes a locked rental as returned and passes the copy on to the next
+// reservation in line, or back to stock. It returns the late fee charged.
+func (s *rentalService) completeReturn(repos repository.Repositories, rental *model.Rental) (int, error) {
+	charged, err := s.settleReturn(repos, rental, 0)
+	if err != nil {
+		return 0, err
+	}
+	return charged, releaseCopy(repos, rental.BookCopyID, s.cfg.HoldDuration)
+}
+
+func (s *rentalService) ReturnRental(id uint, userID uint) (model.Rental, error) {
+	var rental model.Rental
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		rental, err = repos.Rental.GetByIDForUpdate(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rental.UserID != userID) {
+			return ErrRentalNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionRental(rental.Status, model.RentalStatusReturned) {
+			return ErrInvalidRentalTransition
+		}
+
+		fromStatus := rental.Status
+		charged, err := s.completeReturn(repos, &rental)
+		if err != nil {
+			return err
+		}
+
+		_, err = repos.Rental.CreateEvent(model.RentalEvent{
+			RentalID:   rental.ID,
+			Type:       model.RentalEventReturned,
+			FromStatus: fromStatus,
+			ToStatus:   rental.Status,
+			Amount:     -charged,
+			ActorID:    userID,
+		})
+		return err
+	})
+	if err != nil {
+		return model.Rental{}, err
+	}
+
+	return rental, nil
+}
+
+// ExtendRental pushes the due date of a borrowed rental by the configured period and
+// debits the extension cost. It returns the updated rental and the amount charged.
+func (s *rentalService) ExtendRental(id uint, userID uint) (model.Rental, int, error) {
+	var (
+		rental model.Rental
+		cost   int
+	)
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		rental, err = repos.Rental.GetByIDForUpdate(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rental.UserID != userID) {
+			return ErrRentalNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if rental.Status != model.RentalStatusBorrowed {
+			return ErrRentalNotExtendable
+		}
+		if rental.RenewalCount >= s.cfg.MaxRenewals {
+			return ErrRenewalLimitReached
+		}
+
+		//Someone is waiting for this book, it has to come back on time
+		waiting, err := repos.Reservation.CountWaiting(rental.BookID)
+		if err != nil {
+			return err
+		}
+		if waiting > 0 {
+			return ErrBookReserved
+		}
+
+		user, err := repos.User.GetByIDForUpdate(rental.UserID)
+		if err != nil {
+			return err
+		}
+
+		book, err := repos.Book.GetByID(rental.BookID)
+		if err != nil {
+			return err
+		}
+
+		cost = extensionCost(rental, s.cfg.ExtensionDays, s.cfg.ExtensionCostPercent)
+		if user.Deposit != nil && *user.Deposit < 0 {
+			return ErrOutstandingBalance
+		}
+		if user.Deposit == nil || *user.Deposit < cost {
+			return ErrInsufficientDeposit
+		}
+		err = postToWallet(repos, &user, Posting{
+			Amount:      -cost,
+			Type:        model.LedgerTypeExtensionCharge,
+			RentalID:    &rental.ID,
+			Description: "Extension of " + book.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		returnDate := rental.ReturnDate.AddDate(0, 0, s.cfg.ExtensionDays)
+		rental.ReturnDate = &returnDate
+		rental.RenewalCount++
+
+		if rental, err = repos.Rental.Update(rental); err != nil {
+			return err
+		}
+
+		_, err = repos.Rental.CreateEvent(model.RentalEvent{
+			RentalID:   rental.ID,
+			Type:       model.RentalEventExtended,
+			FromStatus: rental.Status,
+			ToStatus:   rental.Status,
+			Amount:     -cost,
+			ActorID:    userID,
+		})
+		return err
+	})
+	if err != nil {
+		return model.Rental{}, 0, err
+	}
+
+	return rental, cost, nil
+}
+
+// MarkOverdueRentals moves every borrowed rental past its due date to Overdue.
+func (s *rentalService) MarkOverdueRentals() (int64, error) {
+	return s.repo.MarkOverdue(time.Now())
+}
+
+// ListRentals returns one page of all rentals for staff.
+func (s *rentalService) ListRentals(filter repository.RentalFilter) (RentalPage, error) {
+	if filter.Sort != "" {
+		if _, ok := repository.RentalSortColumns[strings.TrimPrefix(filter.Sort, "-")]; !ok {
+			return RentalPage{}, fmt.Errorf("%w: unknown sort %q", ErrInvalidRentalFilter, filter.Sort)
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return RentalPage{}, fmt.Errorf("%w: from must be before to", ErrInvalidRentalFilter)
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > maxPageSize {
+		filter.Limit = defaultPageSize
+	}
+
+	rentals, total, err := s.repo.List(filter, time.Now())
+	if err != nil {
+		return RentalPage{}, err
+	}
+
+	return RentalPage{Rentals: rentals, Total: total, Page: filter.Page, Limit: filter.Limit}, nil
+}
+
+// adminTransition locks a rental, checks that it may move to status, lets apply do the
+// work and records the action with the admin and reason, all in one transaction.
+// apply fills in the amount and condition of the event.
+func (s *rentalService) adminTransition(id uint, adminID uint, reason string, status string, eventType string,
+	apply func(repos repository.Repositories, rental *model.Rental, event *model.RentalEvent) error) (model.Rental, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.Rental{}, ErrReasonRequired
+	}
+
+	var rental model.Rental
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		rental, err = repos.Rental.GetByIDForUpdate(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRentalNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionRental(rental.Status, status) {
+			return ErrInvalidRentalTransition
+		}
+
+		event := model.RentalEvent{
+			RentalID:   rental.ID,
+			Type:       eventType,
+			FromStatus: rental.Status,
+			Reason:     reason,
+			ActorID:    adminID,
+		}
+		if err := apply(repos, &rental, &event); err != nil {
+			return err
+		}
+
+		event.ToStatus = rental.Status
+		_, err = repos.Rental.CreateEvent(event)
+		return err
+	})
+	if err != nil {
+		return model.Rental{}, err
+	}
+
+	return rental, nil
+}
+
+// ForceCloseRental returns a rental on behalf of its user, late fee included.
+func (s *rentalService) ForceCloseRental(id uint, adminID uint, reason string) (model.Rental, error) {
+	return s.adminTransition(id, adminID, reason, model.RentalStatusReturned, model.RentalEventForceClosed,
+		func(repos repository.Repositories, rental *model.Rental, event *model.RentalEvent) error {
+			charged, err := s.completeReturn(repos, rental)
+			event.Amount = -charged
+			return err
+		})
+}
+
+// MarkRentalLost closes a rental whose copy will not come back. The replacement fee
+// is charged and the copy is marked lost, so it stays out of stock.
+func (s *rentalService) MarkRentalLost(id uint, adminID uint, reason string) (model.Rental, error) {
+	return s.adminTransition(id, adminID, reason, model.RentalStatusLost, model.RentalEventMarkedLost,
+		func(repos repository.Repositories, rental *model.Rental, event *model.RentalEvent) error {
+			user, err := repos.User.GetByIDForUpdate(rental.UserID)
+			if err != nil {
+				return err
+			}
+			book, err := repos.Book.GetByIDForUpdate(rental.BookID)
+			if err != nil {
+				return err
+			}
+
+			if rental.BookCopyID != nil {
+				bookCopy, err := repos.BookCopy.GetByIDForUpdate(*rental.BookCopyID)
+				if err != nil {
+					return err
+				}
+				bookCopy.Status = model.BookCopyStatusLost
+				if _, err := repos.BookCopy.Update(bookCopy); err != nil {
+					return err
+				}
+			}
+
+			rental.Status = model.RentalStatusLost
+			rental.DamageFee = s.cfg.ReplacementFee
+			event.Amount = -s.cfg.ReplacementFee
+			if *rental, err = repos.Rental.Update(*rental); err != nil {
+				return err
+			}
+
+			return postToWallet(repos, &user, Posting{
+				Amount:      -s.cfg.ReplacementFee,
+				Type:        model.LedgerTypeReplacementFee,
+				RentalID:    &rental.ID,
+				Description: "Replacement of " + book.Name,
+			})
+		})
+}
+
+// CancelRental voids a rental made in error. What was paid for the rental, its price
+// less any voucher discount, is refunded to the deposit, the discount is taken off the
+// voucher redemption, a rental covered by a membership goes back to its quota, and the
+// copy goes back into circulation.
+func (s *rentalService) CancelRental(id uint, adminID uint, reason string) (model.Rental, error) {
+	return s.adminTransition(id, adminID, reason, model.RentalStatusCancelled, model.RentalEventCancelled,
+		func(repos repository.Repositories, rental *model.Rental, event *model.RentalEvent) error {
+			user, err := repos.User.GetByIDForUpdate(rental.UserID)
+			if err != nil {
+				return err
+			}
+			if rental.MembershipID != nil {
+				membership, err := repos.Membership.GetByIDForUpdate(*rental.MembershipID)
+				if err != nil {
+					return err
+				}
+				if membership.RentalsUsed > 0 {
+					membership.RentalsUsed--
+					if _, err := repos.Membership.Update(membership); err != nil {
+						return err
+					}
+				}
+			}
+			book, err := repos.Book.GetByIDForUpdate(rental.BookID)
+			if err != nil {
+				return err
+			}
+
+			refund := rental.Price - rental.Discount
+			err = postToWallet(repos, &user, Posting{
+				Amount:      refund,
+				Type:        model.LedgerTypeRefund,
+				RentalID:    &rental.ID,
+				Description: "Cancelled rental of " + book.Name,
+			})
+			if err != nil {
+				return err
+			}
+
+			rental.Status = model.RentalStatusCancelled
+			event.Amount = refund
+			if *rental, err = repos.Rental.Update(*rental); err != nil {
+				return err
+			}
+			if err := returnDiscount(repos, *rental); err != nil {
+				return err
+			}
+
+			return releaseCopy(repos, rental.BookCopyID, s.cfg.HoldDuration)
+		})
+}
+
+// returnDiscount takes the discount of a cancelled rental off the voucher redemption of
+// its checkout. The redemption is voided, giving the use of the voucher back, only once
+// no rental of the checkout keeps a discount.
+func returnDiscount(repos repository.Repositories, rental model.Rental) error {
+	if rental.Discount == 0 || rental.CheckoutID == nil {
+		return nil
+	}
+	redemption, err := repos.Voucher.GetCheckoutRedemptionForUpdate(*rental.CheckoutID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kept, err := repos.Rental.CountDiscountedByCheckoutID(*rental.CheckoutID)
+	if err != nil {
+		return err
+	}
+	redemption.Amount = max(redemption.Amount-rental.Discount, 0)
+	if kept == 0 {
+		redemption.Status = model.RedemptionStatusVoided
+	}
+	_, err = repos.Voucher.UpdateRedemption(redemption)
+	return err
+}
+
+// ReportDamage closes a rental whose copy came back damaged. The late fee and the
+// repair fee are charged, and the copy is taken out of circulation with its new
+// condition until staff make it available again.
+func (s *rentalService) ReportDamage(id uint, adminID uint, condition string, notes string) (model.Rental, error) {
+	if condition != model.BookCopyConditionFair && condition != model.BookCopyConditionPoor && condition != model.BookCopyConditionDamaged {
+		return model.Rental{}, ErrInvalidDamageCondition
+	}
+
+	return s.adminTransition(id, adminID, notes, model.RentalStatusReturned, model.RentalEventDamaged,
+		func(repos repository.Repositories, rental *model.Rental, event *model.RentalEvent) error {
+			charged, err := s.settleReturn(repos, rental, s.cfg.RepairFee)
+			if err != nil {
+				return err
+			}
+			event.Amount = -charged
+			event.Condition = condition
+
+			if rental.BookCopyID == nil {
+				return nil
+			}
+			bookCopy, err := repos.BookCopy.GetByIDForUpdate(*rental.BookCopyID)
+			if err != nil {
+				return err
+			}
+			bookCopy.Condition = condition
+			bookCopy.Status = model.BookCopyStatusDamaged
+			_, err = repos.BookCopy.Update(bookCopy)
+			return err
+		})
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -10,12 +11,57 @@ type RentalServiceMock struct {
 	mock.Mock
 }
 
-func (m *RentalServiceMock) CreateRental(rental model.Rental) (model.Rental, error) {
-	args := m.Called(rental)
+func (m *RentalServiceMock) CreateRental(userID uint, bookID uint, planID *uint) (model.Rental, error) {
+	args := m.Called(userID, bookID, planID)
 	return args.Get(0).(model.Rental), args.Error(1)
 }
 
+func (m *RentalServiceMock) Checkout(userID uint, items []CartItem, voucherCode string) (model.Checkout, error) {
+	args := m.Called(userID, items, voucherCode)
+	return args.Get(0).(model.Checkout), args.Error(1)
+}
+
 func (m *RentalServiceMock) GetRentalByUserID(userID uint) ([]model.Rental, error) {
 	args := m.Called(userID)
 	return args.Get(0).([]model.Rental), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *RentalServiceMock) ReturnRental(id uint, userID uint) (model.Rental, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(model.Rental), args.Error(1)
+}
+
+func (m *RentalServiceMock) MarkOverdueRentals() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *RentalServiceMock) ExtendRental(id uint, userID uint) (model.Rental, int, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(model.Rental), args.Int(1), args.Error(2)
+}
+
+func (m *RentalServiceMock) ListRentals(filter repository.RentalFilter) (RentalPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(RentalPage), args.Error(1)
+}
+
+func (m *RentalServiceMock) ForceCloseRental(id uint, adminID uint, reason string) (model.Rental, error) {
+	args := m.Called(id, adminID, reason)
+	return args.Get(0).(model.Rental), args.Error(1)
+}
+
+func (m *RentalServiceMock) MarkRentalLost(id uint, adminID uint, reason string) (model.Rental, error) {
+	args := m.Called(id, adminID, reason)
+	return args.Get(0).(model.Rental), args.Error(1)
+}
+
+func (m *RentalServiceMock) CancelRental(id uint, adminID uint, reason string) (model.Rental, error) {
+	args := m.Called(id, adminID, reason)
+	return args.Get(0).(model.Rental), args.Error(1)
+}
+
+func (m *RentalServiceMock) ReportDamage(id uint, adminID uint, condition string, notes string) (model.Rental, error) {
+	args := m.Called(id, adminID, condition, notes)
+	return args.Get(0).(model.Rental), args.Error(1)
+}
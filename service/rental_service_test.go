@@ -0,0 +1,396 @@
+package service_test
+
+import (
+	"os"
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestPostgresDB connects to the database of TEST_DATABASE_DSN and recreates the
+// given tables. The tables are dropped first, so the test is skipped unless a test
+// database is named explicitly; row locking cannot be faked, so it is also skipped when
+// that database is not reachable.
+func setupTestPostgresDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Skipf("postgres not available: %v", err)
+	}
+
+	//Drop children first so foreign keys do not get in the way, join tables included
+	if err := db.Migrator().DropTable("book_authors", "book_categories"); err != nil {
+		t.Fatalf("failed to drop join tables: %v", err)
+	}
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(models[i]); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate tables: %v", err)
+	}
+
+	return db
+}
+
+// seedCategory stores the category called name, below parentID when it is set.
+func seedCategory(t *testing.T, db *gorm.DB, name string, parentID *uint) model.Category {
+	t.Helper()
+
+	category := model.Category{Slug: model.Slugify(name), Name: name, ParentID: parentID}
+	if err := db.Where(model.Category{Slug: category.Slug}).FirstOrCreate(&category).Error; err != nil {
+		t.Fatalf("failed to seed category: %v", err)
+	}
+	return category
+}
+
+func seedUserAndBook(t *testing.T, db *gorm.DB, deposit, stok, cost int) (model.User, model.Book) {
+	t.Helper()
+
+	user := model.User{Name: "Rina", Email: "rina@example.com", Password: "secret", Role: "user", Deposit: &deposit}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	book, err := repository.NewBookRepository(db).Create(model.Book{Name: "Laskar Pelangi", Stok: stok, RentalCost: cost, Category: "Novel",
+		Categories: []model.Category{seedCategory(t, db, "Novel", nil)}})
+	if err != nil {
+		t.Fatalf("failed to seed book: %v", err)
+	}
+	return user, book
+}
+
+// checkoutConcurrently fires n checkouts at once and returns how many succeeded.
+func checkoutConcurrently(rentalService service.RentalService, userID, bookID uint, n int) int {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if _, err := rentalService.CreateRental(userID, bookID, nil); err == nil {
+				mu.Lock()
+				success++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	return success
+}
+
+func TestRentalService_CreateRental_ConcurrentNoOverdraft(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	// Deposit covers exactly three rentals, stock covers five
+	user, book := seedUserAndBook(t, db, 30000, 5, 10000)
+
+	success := checkoutConcurrently(rentalService, user.ID, book.ID, 10)
+	assert.Equal(t, 3, success)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 0, *checkedUser.Deposit)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 2, checkedBook.Stok)
+
+	var rentals int64
+	db.Model(&model.Rental{}).Where("user_id = ?", user.ID).Count(&rentals)
+	assert.Equal(t, int64(3), rentals)
+}
+
+func TestRentalService_CreateRental_ConcurrentNoNegativeStock(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	// Deposit covers every request, stock covers only three
+	user, book := seedUserAndBook(t, db, 1000000, 3, 10000)
+
+	success := checkoutConcurrently(rentalService, user.ID, book.ID, 10)
+	assert.Equal(t, 3, success)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 0, checkedBook.Stok)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 1000000-3*10000, *checkedUser.Deposit)
+}
+
+func TestRentalService_ReturnRental_ChargesLateFee(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{LateFeePerDay: 2000})
+
+	user, book := seedUserAndBook(t, db, 20000, 1, 10000)
+	rental, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	// Due date passed two and a half days ago
+	dueDate := time.Now().Add(-60 * time.Hour)
+	assert.NoError(t, db.Model(&model.Rental{}).Where("id = ?", rental.ID).Update("return_date", dueDate).Error)
+
+	count, err := rentalService.MarkOverdueRentals()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	returned, err := rentalService.ReturnRental(rental.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RentalStatusReturned, returned.Status)
+	assert.Equal(t, 6000, returned.LateFee)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 20000-10000-6000, *checkedUser.Deposit)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 1, checkedBook.Stok)
+}
+
+func TestRentalService_ReturnRental_LateFeePercentOfPrice(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.MembershipPlan{}, &model.Membership{})
+	uow := repository.NewUnitOfWork(db)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{LateFeePerDay: 2000, LateFeePercent: 10})
+	membershipService := service.NewMembershipService(repository.NewMembershipRepository(db), uow)
+
+	user, book := seedUserAndBook(t, db, 80000, 2, 10000)
+	paid, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+	plan, err := membershipService.CreatePlan(model.MembershipPlan{Name: "Basic", Price: 50000, DurationDays: 30, RentalQuota: 1})
+	assert.NoError(t, err)
+	_, err = membershipService.Subscribe(user.ID, plan.ID, false)
+	assert.NoError(t, err)
+	covered, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, covered.Price)
+
+	// The book costs more now
+	assert.NoError(t, db.Model(&model.Book{}).Where("id = ?", book.ID).Update("rental_cost", 50000).Error)
+	dueDate := time.Now().Add(-60 * time.Hour)
+	assert.NoError(t, db.Model(&model.Rental{}).Where("user_id = ?", user.ID).Update("return_date", dueDate).Error)
+
+	// A paid rental is charged on what it cost
+	returned, err := rentalService.ReturnRental(paid.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*1000, returned.LateFee)
+
+	// A rental covered by the membership is charged on the rental cost of the book
+	returned, err = rentalService.ReturnRental(covered.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*5000, returned.LateFee)
+}
+
+func TestRentalService_Checkout_AllOrNothing(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Checkout{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, first := seedUserAndBook(t, db, 100000, 3, 10000)
+	second, err := repository.NewBookRepository(db).Create(model.Book{Name: "Cantik Itu Luka", Stok: 1, RentalCost: 15000, Category: "Novel"})
+	assert.NoError(t, err)
+
+	// Second book has only one copy, nothing is rented
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: first.ID, Quantity: 2}, {BookID: second.ID, Quantity: 2}}, "")
+	assert.ErrorIs(t, err, service.ErrBookNotAvailable)
+
+	var checkedFirst model.Book
+	assert.NoError(t, db.First(&checkedFirst, first.ID).Error)
+	assert.Equal(t, 3, checkedFirst.Stok)
+
+	checkout, err := rentalService.Checkout(user.ID, []service.CartItem{{BookID: second.ID, Quantity: 1}, {BookID: first.ID, Quantity: 2}}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 35000, checkout.TotalCost)
+	assert.Len(t, checkout.Rentals, 3)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 100000-35000, *checkedUser.Deposit)
+
+	var rentals int64
+	db.Model(&model.Rental{}).Where("checkout_id = ?", checkout.ID).Count(&rentals)
+	assert.Equal(t, int64(3), rentals)
+}
+
+func TestRentalService_CreateRental_WithPricingPlan(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, book := seedUserAndBook(t, db, 50000, 2, 10000)
+	plan := model.PricingPlan{Category: book.Category, Name: "14 days", DurationDays: 14, Price: 18000}
+	assert.NoError(t, db.Create(&plan).Error)
+	otherBookID := book.ID + 100
+	foreignPlan := model.PricingPlan{BookID: &otherBookID, Name: "3 days", DurationDays: 3, Price: 4000}
+	assert.NoError(t, db.Create(&foreignPlan).Error)
+
+	_, err := rentalService.CreateRental(user.ID, book.ID, &foreignPlan.ID)
+	assert.ErrorIs(t, err, service.ErrPricingPlanNotFound)
+
+	rental, err := rentalService.CreateRental(user.ID, book.ID, &plan.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 14, rental.DurationDays)
+	assert.Equal(t, 18000, rental.Price)
+	assert.Equal(t, rental.RentDate.AddDate(0, 0, 14), *rental.ReturnDate)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 50000-18000, *checkedUser.Deposit)
+}
+
+func TestRentalService_CancelRental_RefundsAndRecordsReason(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, book := seedUserAndBook(t, db, 30000, 1, 10000)
+	rental, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	_, err = rentalService.CancelRental(rental.ID, 99, "  ")
+	assert.ErrorIs(t, err, service.ErrReasonRequired)
+
+	cancelled, err := rentalService.CancelRental(rental.ID, 99, "Scanned the wrong book")
+	assert.NoError(t, err)
+	assert.Equal(t, model.RentalStatusCancelled, cancelled.Status)
+
+	_, err = rentalService.MarkRentalLost(rental.ID, 99, "Too late")
+	assert.ErrorIs(t, err, service.ErrInvalidRentalTransition)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 30000, *checkedUser.Deposit)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 1, checkedBook.Stok)
+
+	var events []model.RentalEvent
+	assert.NoError(t, db.Where("rental_id = ?", rental.ID).Find(&events).Error)
+	assert.Len(t, events, 1)
+	assert.Equal(t, model.RentalEventCancelled, events[0].Type)
+	assert.Equal(t, model.RentalStatusBorrowed, events[0].FromStatus)
+	assert.Equal(t, "Scanned the wrong book", events[0].Reason)
+	assert.Equal(t, uint(99), events[0].ActorID)
+}
+
+func TestRentalService_ListRentals_Filters(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, book := seedUserAndBook(t, db, 100000, 3, 10000)
+	for i := 0; i < 3; i++ {
+		_, err := rentalService.CreateRental(user.ID, book.ID, nil)
+		assert.NoError(t, err)
+	}
+
+	// One rental is past its due date but not flagged yet
+	var first model.Rental
+	assert.NoError(t, db.Order("id").First(&first).Error)
+	assert.NoError(t, db.Model(&first).Update("return_date", time.Now().Add(-time.Hour)).Error)
+
+	page, err := rentalService.ListRentals(repository.RentalFilter{OverdueOnly: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, first.ID, page.Rentals[0].ID)
+
+	page, err = rentalService.ListRentals(repository.RentalFilter{UserID: user.ID, Sort: "id", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Len(t, page.Rentals, 2)
+	assert.Equal(t, first.ID, page.Rentals[0].ID)
+	assert.Equal(t, user.Email, page.Rentals[0].User.Email)
+
+	_, err = rentalService.ListRentals(repository.RentalFilter{Sort: "password"})
+	assert.ErrorIs(t, err, service.ErrInvalidRentalFilter)
+}
+
+func TestRentalService_RentalsTrackCopies(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, book := seedUserAndBook(t, db, 50000, 2, 10000)
+	first, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+	second, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, *first.BookCopyID, *second.BookCopyID)
+
+	_, err = rentalService.ReturnRental(first.ID, user.ID)
+	assert.NoError(t, err)
+	_, err = rentalService.MarkRentalLost(second.ID, 99, "Never came back")
+	assert.NoError(t, err)
+
+	var returned, lost model.BookCopy
+	assert.NoError(t, db.First(&returned, *first.BookCopyID).Error)
+	assert.NoError(t, db.First(&lost, *second.BookCopyID).Error)
+	assert.Equal(t, model.BookCopyStatusAvailable, returned.Status)
+	assert.Equal(t, model.BookCopyStatusLost, lost.Status)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 1, checkedBook.Stok)
+}
+
+func TestRentalService_ReportDamage_ChargesRepairAndBlocksRentals(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{RepairFee: 25000})
+
+	user, book := seedUserAndBook(t, db, 20000, 2, 10000)
+	rental, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	_, err = rentalService.ReportDamage(rental.ID, 99, model.BookCopyConditionGood, "Looks fine")
+	assert.ErrorIs(t, err, service.ErrInvalidDamageCondition)
+
+	damaged, err := rentalService.ReportDamage(rental.ID, 99, model.BookCopyConditionDamaged, "Water damage on the cover")
+	assert.NoError(t, err)
+	assert.Equal(t, model.RentalStatusReturned, damaged.Status)
+	assert.Equal(t, 25000, damaged.DamageFee)
+
+	// The charge goes through even though it leaves the deposit negative
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 20000-10000-25000, *checkedUser.Deposit)
+
+	var checkedCopy model.BookCopy
+	assert.NoError(t, db.First(&checkedCopy, *rental.BookCopyID).Error)
+	assert.Equal(t, model.BookCopyStatusDamaged, checkedCopy.Status)
+	assert.Equal(t, model.BookCopyConditionDamaged, checkedCopy.Condition)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 1, checkedBook.Stok)
+
+	_, err = rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.ErrorIs(t, err, service.ErrOutstandingBalance)
+
+	rentals, err := rentalService.GetRentalByUserID(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, rentals[0].Events, 1)
+	assert.Equal(t, model.RentalEventDamaged, rentals[0].Events[0].Type)
+	assert.Equal(t, -25000, rentals[0].Events[0].Amount)
+}
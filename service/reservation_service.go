@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReservationNotFound      = errors.New("reservation not found")
+	ErrReservationExists        = errors.New("book already reserved by this user")
+	ErrReservationNotCancelable = errors.New("reservation can no longer be cancelled")
+	ErrBookInStock              = errors.New("book is in stock and can be rented directly")
+	ErrBookReserved             = errors.New("book has a waiting reservation")
+)
+
+// ReservationView is a reservation together with its place in the book queue.
+// QueuePosition is zero when the reservation is not waiting.
+type ReservationView struct {
+	model.Reservation
+	QueuePosition int64
+}
+
+type ReservationService interface {
+	CreateReservation(userID uint, bookID uint) (ReservationView, error)
+	GetReservationsByUserID(userID uint) ([]ReservationView, error)
+	CancelReservation(id uint, userID uint) (model.Reservation, error)
+	ExpireHolds() (int, error)
+}
+
+type reservationService struct {
+	repo repository.ReservationRepository
+	uow  repository.UnitOfWork
+	cfg  config.RentalConfig
+}
+
+func NewReservationService(repo repository.ReservationRepository, uow repository.UnitOfWork, cfg config.RentalConfig) ReservationService {
+	return &reservationService{repo: repo, uow: uow, cfg: cfg}
+}
+
+// releaseCopy hands a copy that came back to the library to the next reservation in
+// line, or makes it available when nobody is waiting. The caller must hold the book
+// row lock.
+func releaseCopy(repos repository.Repositories, copyID *uint, holdDuration time.Duration) error {
+	if copyID == nil {
+		return ErrBookCopyNotFound
+	}
+	bookCopy, err := repos.BookCopy.GetByIDForUpdate(*copyID)
+	if err != nil {
+		return err
+	}
+
+	next, err := repos.Reservation.GetNextWaitingForUpdate(bookCopy.BookID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		bookCopy.Status = model.BookCopyStatusAvailable
+	case err != nil:
+		return err
+	default:
+		holdExpiresAt := time.Now().Add(holdDuration)
+		next.Status = model.ReservationStatusReady
+		next.HoldExpiresAt = &holdExpiresAt
+		next.BookCopyID = &bookCopy.ID
+		if _, err := repos.Reservation.Update(next); err != nil {
+			return err
+		}
+		bookCopy.Status = model.BookCopyStatusOnHold
+	}
+
+	if _, err := repos.BookCopy.Update(bookCopy); err != nil {
+		return err
+	}
+	return repos.Book.SyncStock(bookCopy.BookID)
+}
+
+func (s *reservationService) CreateReservation(userID uint, bookID uint) (ReservationView, error) {
+	if bookID == 0 {
+		return ReservationView{}, errors.New("BookID is required")
+	}
+
+	var reservation model.Reservation
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		book, err := repos.Book.GetByIDForUpdate(bookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if book.Stok > 0 {
+			return ErrBookInStock
+		}
+
+		_, err = repos.Reservation.GetActiveForUpdate(userID, bookID)
+		if err == nil {
+			return ErrReservationExists
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		reservation, err = repos.Reservation.Create(model.Reservation{
+			UserID: userID,
+			BookID: bookID,
+			Status: model.ReservationStatusWaiting,
+		})
+		return err
+	})
+	if err != nil {
+		return ReservationView{}, err
+	}
+
+	position, err := s.repo.QueuePosition(reservation)
+	if err != nil {
+		return ReservationView{}, err
+	}
+
+	return ReservationView{Reservation: reservation, QueuePosition: position}, nil
+}
+
+func (s *reservationService) GetReservationsByUserID(userID uint) ([]ReservationView, error) {
+	reservations, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ReservationView, 0, len(reservations))
+	for _, reservation := range reservations {
+		view := ReservationView{Reservation: reservation}
+		if reservation.Status == model.ReservationStatusWaiting {
+			if view.QueuePosition, err = s.repo.QueuePosition(reservation); err != nil {
+				return nil, err
+			}
+		}
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+func (s *reservationService) CancelReservation(id uint, userID uint) (model.Reservation, error) {
+	found, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && found.UserID != userID) {
+		return model.Reservation{}, ErrReservationNotFound
+	}
+	if err != nil {
+		return model.Reservation{}, err
+	}
+
+	var reservation model.Reservation
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		//Lock book before reservation, same order as checkout
+		if _, err := repos.Book.GetByIDForUpdate(found.BookID); err != nil {
+			return err
+		}
+
+		reservation, err = repos.Reservation.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		wasReady := reservation.Status == model.ReservationStatusReady
+		if reservation.Status != model.ReservationStatusWaiting && !wasReady {
+			return ErrReservationNotCancelable
+		}
+
+		reservation.Status = model.ReservationStatusCancelled
+		if reservation, err = repos.Reservation.Update(reservation); err != nil {
+			return err
+		}
+
+		//A held copy goes to the next in line
+		if wasReady {
+			return releaseCopy(repos, reservation.BookCopyID, s.cfg.HoldDuration)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// ExpireHolds expires every ready reservation whose hold has run out and passes the
+// copy on. It returns how many holds were expired.
+func (s *reservationService) ExpireHolds() (int, error) {
+	now := time.Now()
+	expired, err := s.repo.GetExpiredHolds(now)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, hold := range expired {
+		released := false
+		err := s.uow.Do(func(repos repository.Repositories) error {
+			if _, err := repos.Book.GetByIDForUpdate(hold.BookID); err != nil {
+				return err
+			}
+
+			reservation, err := repos.Reservation.GetByIDForUpdate(hold.ID)
+			if err != nil {
+				return err
+			}
+
+			//Checked out or cancelled in the meantime
+			if reservation.Status != model.ReservationStatusReady || !reservation.HoldExpiresAt.Before(now) {
+				return nil
+			}
+
+			reservation.Status = model.ReservationStatusExpired
+			if _, err := repos.Reservation.Update(reservation); err != nil {
+				return err
+			}
+
+			released = true
+			return releaseCopy(repos, reservation.BookCopyID, s.cfg.HoldDuration)
+		})
+		if err != nil {
+			return count, err
+		}
+		if released {
+			count++
+		}
+	}
+
+	return count, nil
+}
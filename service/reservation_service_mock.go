@@ -0,0 +1,31 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ReservationServiceMock struct {
+	mock.Mock
+}
+
+func (m *ReservationServiceMock) CreateReservation(userID uint, bookID uint) (ReservationView, error) {
+	args := m.Called(userID, bookID)
+	return args.Get(0).(ReservationView), args.Error(1)
+}
+
+func (m *ReservationServiceMock) GetReservationsByUserID(userID uint) ([]ReservationView, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]ReservationView), args.Error(1)
+}
+
+func (m *ReservationServiceMock) CancelReservation(id uint, userID uint) (model.Reservation, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(model.Reservation), args.Error(1)
+}
+
+func (m *ReservationServiceMock) ExpireHolds() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
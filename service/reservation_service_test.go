@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationService_ReturnedCopyIsHeldForNextInLine(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	uow := repository.NewUnitOfWork(db)
+	cfg := config.RentalConfig{HoldDuration: time.Hour}
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, cfg)
+	reservationService := service.NewReservationService(repository.NewReservationRepository(db), uow, cfg)
+
+	borrower, book := seedUserAndBook(t, db, 50000, 1, 10000)
+	deposit := 50000
+	waiter := model.User{Name: "Budi", Email: "budi@example.com", Password: "secret", Role: "user", Deposit: &deposit}
+	assert.NoError(t, db.Create(&waiter).Error)
+
+	rental, err := rentalService.CreateRental(borrower.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	// Out of stock, the second user joins the queue
+	_, err = rentalService.CreateRental(waiter.ID, book.ID, nil)
+	assert.ErrorIs(t, err, service.ErrBookNotAvailable)
+	reservation, err := reservationService.CreateReservation(waiter.ID, book.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), reservation.QueuePosition)
+
+	// Nobody can extend while someone is waiting
+	_, _, err = rentalService.ExtendRental(rental.ID, borrower.ID)
+	assert.ErrorIs(t, err, service.ErrBookReserved)
+
+	// The returned copy is held instead of going back to stock
+	_, err = rentalService.ReturnRental(rental.ID, borrower.ID)
+	assert.NoError(t, err)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 0, checkedBook.Stok)
+
+	views, err := reservationService.GetReservationsByUserID(waiter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReservationStatusReady, views[0].Status)
+
+	// The borrower cannot take the held copy, the waiter can
+	_, err = rentalService.CreateRental(borrower.ID, book.ID, nil)
+	assert.ErrorIs(t, err, service.ErrBookNotAvailable)
+	_, err = rentalService.CreateRental(waiter.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	views, err = reservationService.GetReservationsByUserID(waiter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReservationStatusFulfilled, views[0].Status)
+}
+
+func TestReservationService_ExpiredHoldGoesBackToStock(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{})
+	uow := repository.NewUnitOfWork(db)
+	cfg := config.RentalConfig{HoldDuration: time.Hour}
+	reservationService := service.NewReservationService(repository.NewReservationRepository(db), uow, cfg)
+
+	user, book := seedUserAndBook(t, db, 50000, 0, 10000)
+	reservation, err := reservationService.CreateReservation(user.ID, book.ID)
+	assert.NoError(t, err)
+
+	// A new copy was held for the reservation, but the hold ran out
+	held := model.BookCopy{BookID: book.ID, Barcode: "HOLD-1", Status: model.BookCopyStatusOnHold}
+	assert.NoError(t, db.Create(&held).Error)
+	expiredAt := time.Now().Add(-time.Minute)
+	assert.NoError(t, db.Model(&model.Reservation{}).Where("id = ?", reservation.ID).
+		Updates(map[string]interface{}{"status": model.ReservationStatusReady, "hold_expires_at": expiredAt, "book_copy_id": held.ID}).Error)
+
+	count, err := reservationService.ExpireHolds()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var checkedBook model.Book
+	assert.NoError(t, db.First(&checkedBook, book.ID).Error)
+	assert.Equal(t, 1, checkedBook.Stok)
+
+	var checkedCopy model.BookCopy
+	assert.NoError(t, db.First(&checkedCopy, held.ID).Error)
+	assert.Equal(t, model.BookCopyStatusAvailable, checkedCopy.Status)
+}
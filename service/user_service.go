@@ -8,7 +8,6 @@ import (
 type UserService interface {
 	CreateUser(user model.User) (model.User, error)
 	GetUserByEmail(email string) (model.User, error)
-	UpdateDepositUser(depo int, id uint) (model.User, error)
 	GetUserById(id uint) (model.User, error)
 }
 
@@ -28,10 +27,6 @@ func (r *userService) GetUserByEmail(email string) (model.User, error) {
 	return r.repo.GetByEmail(email)
 }
 
-func (r *userService) UpdateDepositUser(depo int, id uint) (model.User, error) {
-	return r.repo.UpdateDeposit(depo, id)
-}
-
 func (r *userService) GetUserById(id uint) (model.User, error) {
 	return r.repo.GetByID(id)
 }
@@ -20,11 +20,6 @@ func (m *UserServiceMock) GetUserByEmail(email string) (model.User, error) {
 	return args.Get(0).(model.User), args.Error(1)
 }
 
-func (m *UserServiceMock) UpdateDepositUser(depo int, id uint) (model.User, error) {
-	args := m.Called(depo, id)
-	return args.Get(0).(model.User), args.Error(1)
-}
-
 func (m *UserServiceMock) GetUserById(id uint) (model.User, error) {
 	args := m.Called(id)
 	return args.Get(0).(model.User), args.Error(1)
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucherAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		voucher model.Voucher
+		amount  int
+		want    int
+	}{
+		{"percentage off a checkout", model.Voucher{Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 10}, 35000, 3500},
+		{"percentage capped", model.Voucher{Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 50, MaxDiscount: 10000}, 35000, 10000},
+		{"free rental", model.Voucher{Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 100}, 15000, 15000},
+		{"fixed off a checkout", model.Voucher{Type: model.VoucherTypeFixed, Scope: model.VoucherScopeRental, Value: 5000}, 15000, 5000},
+		{"fixed never more than the checkout", model.Voucher{Type: model.VoucherTypeFixed, Scope: model.VoucherScopeRental, Value: 20000}, 15000, 15000},
+		{"percentage bonus on a top-up", model.Voucher{Type: model.VoucherTypePercentage, Scope: model.VoucherScopeTopUp, Value: 10}, 150000, 15000},
+		{"bonus credit on a top-up", model.Voucher{Type: model.VoucherTypeBonusCredit, Scope: model.VoucherScopeTopUp, Value: 25000}, 20000, 25000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, voucherAmount(tt.voucher, tt.amount))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVoucherNotFound      = errors.New("voucher not found")
+	ErrInvalidVoucher       = errors.New("code, a known type and scope, a positive value and a valid window are required")
+	ErrVoucherCodeTaken     = errors.New("voucher code already exists")
+	ErrVoucherNotActive     = errors.New("voucher is not active")
+	ErrVoucherNotApplicable = errors.New("voucher does not apply here")
+	ErrVoucherMinAmount     = errors.New("amount is below the voucher minimum")
+	ErrVoucherUsedUp        = errors.New("voucher has been used up")
+	ErrVoucherLimitReached  = errors.New("voucher already used the maximum number of times")
+)
+
+// voucherScopes lists, for every voucher type, the scopes it can be created for.
+var voucherScopes = map[string][]string{
+	model.VoucherTypePercentage:  {model.VoucherScopeRental, model.VoucherScopeTopUp},
+	model.VoucherTypeFixed:       {model.VoucherScopeRental},
+	model.VoucherTypeBonusCredit: {model.VoucherScopeTopUp},
+}
+
+// normalizeVoucherCode is how codes are stored and looked up, so they match regardless
+// of case and surrounding spaces.
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// voucherAmount returns what a voucher is worth on amount: the discount on a checkout
+// or the bonus on a top-up. A percentage is capped at MaxDiscount, and a discount is
+// never more than amount itself.
+func voucherAmount(voucher model.Voucher, amount int) int {
+	value := voucher.Value
+	if voucher.Type == model.VoucherTypePercentage {
+		value = amount * voucher.Value / 100
+		if voucher.MaxDiscount > 0 && value > voucher.MaxDiscount {
+			value = voucher.MaxDiscount
+		}
+	}
+	if voucher.Scope == model.VoucherScopeRental && value > amount {
+		value = amount
+	}
+	return value
+}
+
+// lockVoucher locks the voucher with code and checks that userID may redeem it on
+// scope now: it is active and in its window, and neither the global nor the per-user
+// limit is reached. Holding the lock until the redemption is recorded keeps concurrent
+// redemptions from going over the limits.
+func lockVoucher(repos repository.Repositories, code string, userID uint, scope string, now time.Time) (model.Voucher, error) {
+	voucher, err := repos.Voucher.GetByCodeForUpdate(normalizeVoucherCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Voucher{}, ErrVoucherNotFound
+	}
+	if err != nil {
+		return model.Voucher{}, err
+	}
+
+	if !voucher.ValidAt(now) {
+		return model.Voucher{}, ErrVoucherNotActive
+	}
+	if voucher.Scope != scope {
+		return model.Voucher{}, ErrVoucherNotApplicable
+	}
+
+	if voucher.MaxUses > 0 {
+		used, err := repos.Voucher.CountRedemptions(voucher.ID, 0)
+		if err != nil {
+			return model.Voucher{}, err
+		}
+		if used >= int64(voucher.MaxUses) {
+			return model.Voucher{}, ErrVoucherUsedUp
+		}
+	}
+	if voucher.MaxUsesPerUser > 0 {
+		used, err := repos.Voucher.CountRedemptions(voucher.ID, userID)
+		if err != nil {
+			return model.Voucher{}, err
+		}
+		if used >= int64(voucher.MaxUsesPerUser) {
+			return model.Voucher{}, ErrVoucherLimitReached
+		}
+	}
+
+	return voucher, nil
+}
+
+// VoucherPage is one page of vouchers.
+type VoucherPage struct {
+	Vouchers []model.Voucher
+	Total    int64
+	Page     int
+	Limit    int
+}
+
+type VoucherService interface {
+	CreateVoucher(voucher model.Voucher) (model.Voucher, error)
+	ListVouchers(page int, limit int) (VoucherPage, error)
+	DeactivateVoucher(id uint) (model.Voucher, error)
+}
+
+type voucherService struct {
+	repo repository.VoucherRepository
+}
+
+func NewVoucherService(repo repository.VoucherRepository) VoucherService {
+	return &voucherService{repo: repo}
+}
+
+func (s *voucherService) CreateVoucher(voucher model.Voucher) (model.Voucher, error) {
+	voucher.Code = normalizeVoucherCode(voucher.Code)
+	voucher.Categories = strings.Join(voucher.CategoryList(), ",")
+	voucher.Active = true
+
+	if voucher.Code == "" || !containsString(voucherScopes[voucher.Type], voucher.Scope) || voucher.Value <= 0 ||
+		voucher.MaxDiscount < 0 || voucher.MinAmount < 0 || voucher.MaxUses < 0 || voucher.MaxUsesPerUser < 0 {
+		return model.Voucher{}, ErrInvalidVoucher
+	}
+	if voucher.Type == model.VoucherTypePercentage && voucher.Value > 100 {
+		return model.Voucher{}, ErrInvalidVoucher
+	}
+	if voucher.ValidFrom != nil && voucher.ValidUntil != nil && !voucher.ValidFrom.Before(*voucher.ValidUntil) {
+		return model.Voucher{}, ErrInvalidVoucher
+	}
+	//Categories only narrow down which books a rental voucher discounts
+	if voucher.Scope != model.VoucherScopeRental && voucher.Categories != "" {
+		return model.Voucher{}, ErrInvalidVoucher
+	}
+
+	_, err := s.repo.GetByCode(voucher.Code)
+	if err == nil {
+		return model.Voucher{}, ErrVoucherCodeTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Voucher{}, err
+	}
+
+	return s.repo.Create(voucher)
+}
+
+func (s *voucherService) ListVouchers(page int, limit int) (VoucherPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	vouchers, total, err := s.repo.List(page, limit)
+	if err != nil {
+		return VoucherPage{}, err
+	}
+	return VoucherPage{Vouchers: vouchers, Total: total, Page: page, Limit: limit}, nil
+}
+
+// DeactivateVoucher stops a voucher from being redeemed. Past redemptions, including
+// pending top-up bonuses, are kept.
+func (s *voucherService) DeactivateVoucher(id uint) (model.Voucher, error) {
+	voucher, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Voucher{}, ErrVoucherNotFound
+	}
+	if err != nil {
+		return model.Voucher{}, err
+	}
+
+	voucher.Active = false
+	return s.repo.Update(voucher)
+}
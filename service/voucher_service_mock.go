@@ -0,0 +1,26 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type VoucherServiceMock struct {
+	mock.Mock
+}
+
+func (m *VoucherServiceMock) CreateVoucher(voucher model.Voucher) (model.Voucher, error) {
+	args := m.Called(voucher)
+	return args.Get(0).(model.Voucher), args.Error(1)
+}
+
+func (m *VoucherServiceMock) ListVouchers(page int, limit int) (VoucherPage, error) {
+	args := m.Called(page, limit)
+	return args.Get(0).(VoucherPage), args.Error(1)
+}
+
+func (m *VoucherServiceMock) DeactivateVoucher(id uint) (model.Voucher, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Voucher), args.Error(1)
+}
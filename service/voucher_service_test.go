@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"pojok-baca-api/config"
+	"pojok-baca-api/gateway"
+	"pojok-baca-api/handler"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucherService_CreateVoucher(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.Voucher{})
+	voucherService := service.NewVoucherService(repository.NewVoucherRepository(db))
+
+	voucher, err := voucherService.CreateVoucher(model.Voucher{Code: " firstfree ", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 100, Categories: "Novel, ,Komik"})
+	assert.NoError(t, err)
+	assert.Equal(t, "FIRSTFREE", voucher.Code)
+	assert.Equal(t, "Novel,Komik", voucher.Categories)
+	assert.True(t, voucher.Active)
+
+	_, err = voucherService.CreateVoucher(model.Voucher{Code: "FirstFree", Type: model.VoucherTypeFixed, Scope: model.VoucherScopeRental, Value: 5000})
+	assert.ErrorIs(t, err, service.ErrVoucherCodeTaken)
+
+	// A fixed discount cannot be put on a top-up
+	_, err = voucherService.CreateVoucher(model.Voucher{Code: "TOPUP5K", Type: model.VoucherTypeFixed, Scope: model.VoucherScopeTopUp, Value: 5000})
+	assert.ErrorIs(t, err, service.ErrInvalidVoucher)
+
+	deactivated, err := voucherService.DeactivateVoucher(voucher.ID)
+	assert.NoError(t, err)
+	assert.False(t, deactivated.Active)
+}
+
+func TestRentalService_Checkout_WithVoucher(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Checkout{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.Voucher{}, &model.VoucherRedemption{})
+	uow := repository.NewUnitOfWork(db)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{})
+
+	user, novel := seedUserAndBook(t, db, 100000, 3, 10000)
+	comic, err := repository.NewBookRepository(db).Create(model.Book{Name: "Si Juki", Stok: 2, RentalCost: 8000, Category: "Komik",
+		Categories: []model.Category{seedCategory(t, db, "Komik", nil)}})
+	assert.NoError(t, err)
+	voucher := model.Voucher{Code: "NOVEL50", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 50, Categories: "Novel", MaxUsesPerUser: 1, Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: comic.ID, Quantity: 1}}, "novel50")
+	assert.ErrorIs(t, err, service.ErrVoucherNotApplicable)
+
+	// Only the novels are discounted
+	checkout, err := rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 2}, {BookID: comic.ID, Quantity: 1}}, "novel50")
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, checkout.Discount)
+	assert.Equal(t, 28000-10000, checkout.TotalCost)
+	assert.Equal(t, &voucher.ID, checkout.VoucherID)
+	assert.Equal(t, 100000-18000, depositOf(t, db, user.ID))
+
+	discounts := 0
+	for _, rental := range checkout.Rentals {
+		discounts += rental.Discount
+	}
+	assert.Equal(t, 10000, discounts)
+
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 1}}, "NOVEL50")
+	assert.ErrorIs(t, err, service.ErrVoucherLimitReached)
+
+	// Cancelling a discounted rental refunds what was paid for it
+	_, err = rentalService.CancelRental(checkout.Rentals[0].ID, 1, "Wrong book")
+	assert.NoError(t, err)
+	assert.Equal(t, 100000-18000+checkout.Rentals[0].Price-checkout.Rentals[0].Discount, depositOf(t, db, user.ID))
+
+	// and gives the use of the voucher back
+	var redemptions int64
+	db.Model(&model.VoucherRedemption{}).Where("checkout_id = ? AND status = ?", checkout.ID, model.RedemptionStatusVoided).Count(&redemptions)
+	assert.Equal(t, int64(1), redemptions)
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 1}}, "NOVEL50")
+	assert.NoError(t, err)
+}
+
+func TestRentalService_CancelRental_PartOfVoucherCheckout(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Checkout{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.Voucher{}, &model.VoucherRedemption{})
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), repository.NewUnitOfWork(db), config.RentalConfig{})
+
+	user, novel := seedUserAndBook(t, db, 100000, 3, 10000)
+	voucher := model.Voucher{Code: "FREE", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 100, MaxUsesPerUser: 1, Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+
+	// Both rentals carry a share of the discount
+	checkout, err := rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 2}}, "FREE")
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, checkout.Rentals[0].Discount)
+	assert.Equal(t, 10000, checkout.Rentals[1].Discount)
+
+	// Cancelling one takes its share off the redemption, which still counts
+	_, err = rentalService.CancelRental(checkout.Rentals[0].ID, 1, "Wrong book")
+	assert.NoError(t, err)
+	var redemption model.VoucherRedemption
+	assert.NoError(t, db.Where("checkout_id = ?", checkout.ID).First(&redemption).Error)
+	assert.Equal(t, model.RedemptionStatusApplied, redemption.Status)
+	assert.Equal(t, 10000, redemption.Amount)
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 1}}, "FREE")
+	assert.ErrorIs(t, err, service.ErrVoucherLimitReached)
+
+	// Cancelling the other gives the use back
+	_, err = rentalService.CancelRental(checkout.Rentals[1].ID, 1, "Wrong book")
+	assert.NoError(t, err)
+	assert.NoError(t, db.First(&redemption, redemption.ID).Error)
+	assert.Equal(t, model.RedemptionStatusVoided, redemption.Status)
+	assert.Equal(t, 0, redemption.Amount)
+	_, err = rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 1}}, "FREE")
+	assert.NoError(t, err)
+}
+
+func TestDepositService_TopUpVoucher(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{}, &model.Voucher{}, &model.VoucherRedemption{})
+	e := echo.New()
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	fake := gateway.NewFakeGateway(server.URL, server.URL+"/webhook/deposit", testServerKey)
+	depositService := service.NewDepositService(repository.NewDepositTransactionRepository(db), repository.NewUnitOfWork(db), fake, testServerKey)
+	e.POST("/webhook/deposit", handler.NewDepositTransactionHandler(depositService).Webhook)
+
+	user := seedWallet(t, db, 0)
+	voucher := model.Voucher{Code: "TOPUP10", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeTopUp, Value: 10, MinAmount: 100000, MaxUses: 1, Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+
+	_, err := depositService.CreateTransaction(user.ID, 50000, "TOPUP10")
+	assert.ErrorIs(t, err, service.ErrVoucherMinAmount)
+
+	// The use is reserved while the payment is pending and given back when it fails
+	expired, err := depositService.CreateTransaction(user.ID, 150000, "TOPUP10")
+	assert.NoError(t, err)
+	_, err = depositService.CreateTransaction(user.ID, 150000, "TOPUP10")
+	assert.ErrorIs(t, err, service.ErrVoucherUsedUp)
+	assert.NoError(t, fake.Expire(expired.OrderID))
+
+	paid, err := depositService.CreateTransaction(user.ID, 150000, "TOPUP10")
+	assert.NoError(t, err)
+	assert.NoError(t, fake.Settle(paid.OrderID))
+	assert.NoError(t, fake.Settle(paid.OrderID))
+	assert.Equal(t, 165000, depositOf(t, db, user.ID))
+
+	balance, err := repository.NewLedgerRepository(db).Balance(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 165000, balance)
+
+	var redemptions []model.VoucherRedemption
+	assert.NoError(t, db.Order("id").Find(&redemptions).Error)
+	assert.Len(t, redemptions, 2)
+	assert.Equal(t, model.RedemptionStatusVoided, redemptions[0].Status)
+	assert.Equal(t, model.RedemptionStatusApplied, redemptions[1].Status)
+	assert.Equal(t, 15000, redemptions[1].Amount)
+}
+
+// downGateway is a payment gateway that cannot be reached.
+type downGateway struct{}
+
+func (downGateway) CreateCharge(orderID string, amount int) (gateway.Charge, error) {
+	return gateway.Charge{}, errors.New("gateway unavailable")
+}
+
+func (downGateway) GetStatus(orderID string) (gateway.TransactionStatus, error) {
+	return gateway.TransactionStatus{}, errors.New("gateway unavailable")
+}
+
+func TestDepositService_TopUpVoucher_GatewayDown(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{}, &model.Voucher{}, &model.VoucherRedemption{})
+	depositRepo := repository.NewDepositTransactionRepository(db)
+	uow := repository.NewUnitOfWork(db)
+
+	user := seedWallet(t, db, 0)
+	voucher := model.Voucher{Code: "TOPUP10", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeTopUp, Value: 10, MaxUsesPerUser: 1, Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+
+	_, err := service.NewDepositService(depositRepo, uow, downGateway{}, testServerKey).CreateTransaction(user.ID, 150000, "TOPUP10")
+	assert.Error(t, err)
+
+	var order model.DepositTransaction
+	assert.NoError(t, db.Where("user_id = ?", user.ID).First(&order).Error)
+	assert.Equal(t, model.DepositStatusFailure, order.Status)
+	var redemption model.VoucherRedemption
+	assert.NoError(t, db.Where("deposit_order_id = ?", order.OrderID).First(&redemption).Error)
+	assert.Equal(t, model.RedemptionStatusVoided, redemption.Status)
+
+	// The single use is free again once the gateway is back
+	fake := gateway.NewFakeGateway("http://localhost", "http://localhost/webhook/deposit", testServerKey)
+	_, err = service.NewDepositService(depositRepo, uow, fake, testServerKey).CreateTransaction(user.ID, 150000, "TOPUP10")
+	assert.NoError(t, err)
+}
+
+func TestRentalService_Checkout_VoucherAndPlanFollowCategoryTree(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Checkout{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.PricingPlan{}, &model.Voucher{}, &model.VoucherRedemption{})
+	uow := repository.NewUnitOfWork(db)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{})
+	categoryRepo := repository.NewCategoryRepository(db)
+	pricingPlanService := service.NewPricingPlanService(repository.NewPricingPlanRepository(db), repository.NewBookRepository(db), categoryRepo)
+
+	// The novel is filed under Biografi first and under Novel, a subcategory of Sastra, second
+	user, novel := seedUserAndBook(t, db, 100000, 2, 10000)
+	sastra := seedCategory(t, db, "Sastra", nil)
+	assert.NoError(t, db.Model(&model.Category{}).Where("slug = ?", "novel").Update("parent_id", sastra.ID).Error)
+	biografi := seedCategory(t, db, "Biografi", nil)
+	novelCategory, err := categoryRepo.GetBySlug("novel")
+	assert.NoError(t, err)
+	_, err = repository.NewBookRepository(db).Update(model.Book{Name: novel.Name, RentalCost: novel.RentalCost, Category: "Biografi",
+		Categories: []model.Category{biografi, novelCategory}}, novel.ID)
+	assert.NoError(t, err)
+
+	// Plans name a stored category, by any spelling of it
+	_, err = pricingPlanService.CreatePlan(model.PricingPlan{Category: "Sastra Lama", Name: "14 days", DurationDays: 14, Price: 18000})
+	assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+	plan, err := pricingPlanService.CreatePlan(model.PricingPlan{Category: "sastra", Name: "14 days", DurationDays: 14, Price: 18000})
+	assert.NoError(t, err)
+	assert.Equal(t, "Sastra", plan.Category)
+	plans, err := pricingPlanService.GetPlansForBook(novel.ID)
+	assert.NoError(t, err)
+	assert.Len(t, plans, 1)
+
+	voucher := model.Voucher{Code: "SASTRA50", Type: model.VoucherTypePercentage, Scope: model.VoucherScopeRental, Value: 50, Categories: "sastra", Active: true}
+	assert.NoError(t, db.Create(&voucher).Error)
+
+	checkout, err := rentalService.Checkout(user.ID, []service.CartItem{{BookID: novel.ID, Quantity: 1, PlanID: &plan.ID}}, "SASTRA50")
+	assert.NoError(t, err)
+	assert.Equal(t, 9000, checkout.Discount)
+	assert.Equal(t, 9000, checkout.TotalCost)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound            = errors.New("user not found")
+	ErrInvalidAdjustmentAmount = errors.New("amount must not be zero")
+	ErrInvalidReasonCode       = errors.New("unknown adjustment reason code")
+	ErrNoteRequired            = errors.New("a note is required")
+	ErrNegativeBalance         = errors.New("adjustment would make the balance negative")
+)
+
+var adjustmentReasonCodes = []string{
+	model.AdjustmentReasonCorrection, model.AdjustmentReasonGoodwill, model.AdjustmentReasonChargeback,
+	model.AdjustmentReasonMigration, model.AdjustmentReasonOther,
+}
+
+// counterAccounts is the library account on the other side of each kind of posting.
+var counterAccounts = map[string]string{
+	model.LedgerTypeOpeningBalance:    model.LedgerAccountAdjustment,
+	model.LedgerTypeTopUp:             model.LedgerAccountCash,
+	model.LedgerTypeRentalCharge:      model.LedgerAccountRevenue,
+	model.LedgerTypeExtensionCharge:   model.LedgerAccountRevenue,
+	model.LedgerTypeLateFee:           model.LedgerAccountRevenue,
+	model.LedgerTypeDamageFee:         model.LedgerAccountRevenue,
+	model.LedgerTypeReplacementFee:    model.LedgerAccountRevenue,
+	model.LedgerTypeRefund:            model.LedgerAccountRevenue,
+	model.LedgerTypeAdjustment:        model.LedgerAccountAdjustment,
+	model.LedgerTypeWithdrawalHold:    model.LedgerAccountWithdrawal,
+	model.LedgerTypeWithdrawalRelease: model.LedgerAccountWithdrawal,
+	model.LedgerTypeVoucherBonus:      model.LedgerAccountPromotion,
+	model.LedgerTypeMembershipFee:     model.LedgerAccountRevenue,
+}
+
+// Posting is one movement of money into (positive amount) or out of a user's wallet,
+// with the deposit order, rental, withdrawal or manual adjustment that caused it.
+type Posting struct {
+	Amount         int
+	Type           string
+	DepositOrderID *string
+	RentalID       *uint
+	WithdrawalID   *uint
+	AdjustmentID   *uint
+	Description    string
+}
+
+func newLedgerTransactionID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "LT-" + hex.EncodeToString(b), nil
+}
+
+// postEntries appends a posting for userID to the ledger as a balanced pair of entries:
+// the amount on account and its opposite on counter.
+func postEntries(repos repository.Repositories, userID uint, account string, counter string, posting Posting) error {
+	transactionID, err := newLedgerTransactionID()
+	if err != nil {
+		return err
+	}
+	leg := func(account string, amount int) model.LedgerEntry {
+		return model.LedgerEntry{
+			TransactionID:  transactionID,
+			UserID:         userID,
+			Account:        account,
+			Amount:         amount,
+			Type:           posting.Type,
+			DepositOrderID: posting.DepositOrderID,
+			RentalID:       posting.RentalID,
+			WithdrawalID:   posting.WithdrawalID,
+			AdjustmentID:   posting.AdjustmentID,
+			Description:    posting.Description,
+		}
+	}
+	return repos.Ledger.Create([]model.LedgerEntry{
+		leg(account, posting.Amount),
+		leg(counter, -posting.Amount),
+	})
+}
+
+// postToWallet is the only way a balance changes. It appends the posting to the ledger
+// as a balanced pair of entries, the user's wallet against the matching library account,
+// and updates the cached deposit of the user. The caller must hold the user row lock;
+// user.Deposit is kept current so several postings can be made in one transaction.
+func postToWallet(repos repository.Repositories, user *model.User, posting Posting) error {
+	if posting.Amount == 0 {
+		return nil
+	}
+	counter, ok := counterAccounts[posting.Type]
+	if !ok {
+		return errors.New("unknown ledger posting type " + posting.Type)
+	}
+
+	if err := postEntries(repos, user.ID, model.LedgerAccountWallet, counter, posting); err != nil {
+		return err
+	}
+
+	deposit := posting.Amount
+	if user.Deposit != nil {
+		deposit += *user.Deposit
+	}
+	if _, err := repos.User.UpdateDeposit(deposit, user.ID); err != nil {
+		return err
+	}
+	user.Deposit = &deposit
+	return nil
+}
+
+// WalletPage is one page of a user's wallet history.
+type WalletPage struct {
+	Entries []repository.WalletEntry
+	Balance int
+	Total   int64
+	Page    int
+	Limit   int
+}
+
+type WalletService interface {
+	GetTransactions(userID uint, page int, limit int) (WalletPage, error)
+	ReconcileBalances() (int, error)
+	AdjustBalance(adjustment model.BalanceAdjustment) (model.BalanceAdjustment, error)
+}
+
+type walletService struct {
+	repo repository.LedgerRepository
+	uow  repository.UnitOfWork
+}
+
+func NewWalletService(repo repository.LedgerRepository, uow repository.UnitOfWork) WalletService {
+	return &walletService{repo: repo, uow: uow}
+}
+
+func (s *walletService) GetTransactions(userID uint, page int, limit int) (WalletPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	entries, total, err := s.repo.GetWalletEntries(userID, page, limit)
+	if err != nil {
+		return WalletPage{}, err
+	}
+	balance, err := s.repo.Balance(userID)
+	if err != nil {
+		return WalletPage{}, err
+	}
+
+	return WalletPage{Entries: entries, Balance: balance, Total: total, Page: page, Limit: limit}, nil
+}
+
+// ReconcileBalances resets every cached deposit that drifted from the ledger to the
+// ledger balance. It returns how many users were corrected.
+func (s *walletService) ReconcileBalances() (int, error) {
+	mismatches, err := s.repo.GetMismatchedWallets()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mismatch := range mismatches {
+		corrected := false
+		err := s.uow.Do(func(repos repository.Repositories) error {
+			user, err := repos.User.GetByIDForUpdate(mismatch.UserID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			//Read again under the lock, a posting may have landed in between
+			balance, err := repos.Ledger.Balance(user.ID)
+			if err != nil {
+				return err
+			}
+			deposit := 0
+			if user.Deposit != nil {
+				deposit = *user.Deposit
+			}
+			if deposit == balance {
+				return nil
+			}
+
+			corrected = true
+			_, err = repos.User.UpdateDeposit(balance, user.ID)
+			return err
+		})
+		if err != nil {
+			return count, err
+		}
+		if corrected {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// AdjustBalance credits or debits a user's deposit by hand. The reason code and note
+// are mandatory and AdminID records who made the change. A debit may only take the
+// balance below zero when AllowNegative is set.
+func (s *walletService) AdjustBalance(adjustment model.BalanceAdjustment) (model.BalanceAdjustment, error) {
+	adjustment.ReasonCode = strings.TrimSpace(adjustment.ReasonCode)
+	adjustment.Note = strings.TrimSpace(adjustment.Note)
+	if adjustment.Amount == 0 {
+		return model.BalanceAdjustment{}, ErrInvalidAdjustmentAmount
+	}
+	if !containsString(adjustmentReasonCodes, adjustment.ReasonCode) {
+		return model.BalanceAdjustment{}, ErrInvalidReasonCode
+	}
+	if adjustment.Note == "" {
+		return model.BalanceAdjustment{}, ErrNoteRequired
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		user, err := repos.User.GetByIDForUpdate(adjustment.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		balance := adjustment.Amount
+		if user.Deposit != nil {
+			balance += *user.Deposit
+		}
+		if balance < 0 && !adjustment.AllowNegative {
+			return ErrNegativeBalance
+		}
+
+		adjustment.BalanceAfter = balance
+		adjustment, err = repos.Adjustment.Create(adjustment)
+		if err != nil {
+			return err
+		}
+
+		return postToWallet(repos, &user, Posting{
+			Amount:       adjustment.Amount,
+			Type:         model.LedgerTypeAdjustment,
+			AdjustmentID: &adjustment.ID,
+			Description:  "Adjustment (" + adjustment.ReasonCode + "): " + adjustment.Note,
+		})
+	})
+	if err != nil {
+		return model.BalanceAdjustment{}, err
+	}
+
+	return adjustment, nil
+}
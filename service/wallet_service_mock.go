@@ -0,0 +1,26 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletServiceMock struct {
+	mock.Mock
+}
+
+func (m *WalletServiceMock) GetTransactions(userID uint, page int, limit int) (WalletPage, error) {
+	args := m.Called(userID, page, limit)
+	return args.Get(0).(WalletPage), args.Error(1)
+}
+
+func (m *WalletServiceMock) ReconcileBalances() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *WalletServiceMock) AdjustBalance(adjustment model.BalanceAdjustment) (model.BalanceAdjustment, error) {
+	args := m.Called(adjustment)
+	return args.Get(0).(model.BalanceAdjustment), args.Error(1)
+}
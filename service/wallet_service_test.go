@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"pojok-baca-api/config"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletService_LedgerMatchesDeposit(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.DepositTransaction{}, &model.DepositNotification{}, &model.LedgerEntry{}, &model.Membership{})
+	uow := repository.NewUnitOfWork(db)
+	depositRepo := repository.NewDepositTransactionRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{LateFeePerDay: 2000})
+	depositService := service.NewDepositService(depositRepo, uow, nil, testServerKey)
+	walletService := service.NewWalletService(ledgerRepo, uow)
+
+	user, book := seedUserAndBook(t, db, 0, 1, 10000)
+
+	_, err := depositRepo.Create(&model.DepositTransaction{UserID: user.ID, OrderID: "ORDER-TEST-1", Deposit: 50000, Status: "pending"})
+	assert.NoError(t, err)
+	assert.NoError(t, depositService.HandleWebhook(signedNotification("ORDER-TEST-1", "200", "50000.00", "settlement")))
+
+	rental, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	// Due date passed one and a half days ago
+	dueDate := time.Now().Add(-36 * time.Hour)
+	assert.NoError(t, db.Model(&model.Rental{}).Where("id = ?", rental.ID).Update("return_date", dueDate).Error)
+	_, err = rentalService.ReturnRental(rental.ID, user.ID)
+	assert.NoError(t, err)
+
+	// Every posting is balanced, so all accounts together add up to zero
+	var total int
+	assert.NoError(t, db.Model(&model.LedgerEntry{}).Select("COALESCE(SUM(amount), 0)").Scan(&total).Error)
+	assert.Equal(t, 0, total)
+
+	page, err := walletService.GetTransactions(user.ID, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 50000-10000-4000, page.Balance)
+	assert.Equal(t, int64(3), page.Total)
+
+	// Newest first, each with the balance right after it
+	assert.Equal(t, model.LedgerTypeLateFee, page.Entries[0].Type)
+	assert.Equal(t, 36000, page.Entries[0].RunningBalance)
+	assert.Equal(t, model.LedgerTypeRentalCharge, page.Entries[1].Type)
+	assert.Equal(t, &rental.ID, page.Entries[1].RentalID)
+	assert.Equal(t, 40000, page.Entries[1].RunningBalance)
+	assert.Equal(t, model.LedgerTypeTopUp, page.Entries[2].Type)
+	assert.Equal(t, "ORDER-TEST-1", *page.Entries[2].DepositOrderID)
+	assert.Equal(t, 50000, page.Entries[2].RunningBalance)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, page.Balance, *checkedUser.Deposit)
+}
+
+func TestWalletService_ReconcileBalances(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.Author{}, &model.Category{}, &model.Book{}, &model.BookCopy{}, &model.Rental{}, &model.RentalEvent{}, &model.Reservation{}, &model.LedgerEntry{}, &model.Membership{})
+	uow := repository.NewUnitOfWork(db)
+	rentalService := service.NewRentalService(repository.NewRentalRepository(db), uow, config.RentalConfig{})
+	walletService := service.NewWalletService(repository.NewLedgerRepository(db), uow)
+
+	user, book := seedUserAndBook(t, db, 0, 1, 10000)
+	assert.NoError(t, repository.NewLedgerRepository(db).Create([]model.LedgerEntry{
+		{TransactionID: "LT-seed", UserID: user.ID, Account: model.LedgerAccountWallet, Amount: 20000, Type: model.LedgerTypeAdjustment},
+		{TransactionID: "LT-seed", UserID: user.ID, Account: model.LedgerAccountAdjustment, Amount: -20000, Type: model.LedgerTypeAdjustment},
+	}))
+	assert.NoError(t, db.Model(&model.User{}).Where("id = ?", user.ID).Update("deposit", 20000).Error)
+
+	_, err := rentalService.CreateRental(user.ID, book.ID, nil)
+	assert.NoError(t, err)
+
+	count, err := walletService.ReconcileBalances()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Someone edits the deposit directly
+	assert.NoError(t, db.Model(&model.User{}).Where("id = ?", user.ID).Update("deposit", 999999).Error)
+
+	count, err = walletService.ReconcileBalances()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var checkedUser model.User
+	assert.NoError(t, db.First(&checkedUser, user.ID).Error)
+	assert.Equal(t, 10000, *checkedUser.Deposit)
+}
+
+func TestWalletService_AdjustBalance(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.LedgerEntry{}, &model.BalanceAdjustment{})
+	walletService := service.NewWalletService(repository.NewLedgerRepository(db), repository.NewUnitOfWork(db))
+	user := seedWallet(t, db, 20000)
+
+	_, err := walletService.AdjustBalance(model.BalanceAdjustment{UserID: user.ID, AdminID: 1, Amount: 5000, ReasonCode: "oops", Note: "x"})
+	assert.ErrorIs(t, err, service.ErrInvalidReasonCode)
+	_, err = walletService.AdjustBalance(model.BalanceAdjustment{UserID: user.ID, AdminID: 1, Amount: 5000, ReasonCode: model.AdjustmentReasonGoodwill, Note: "  "})
+	assert.ErrorIs(t, err, service.ErrNoteRequired)
+	_, err = walletService.AdjustBalance(model.BalanceAdjustment{UserID: 999, AdminID: 1, Amount: 5000, ReasonCode: model.AdjustmentReasonGoodwill, Note: "Sorry"})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	credit, err := walletService.AdjustBalance(model.BalanceAdjustment{UserID: user.ID, AdminID: 1, Amount: 5000, ReasonCode: model.AdjustmentReasonGoodwill, Note: "Sorry for the delay"})
+	assert.NoError(t, err)
+	assert.Equal(t, 25000, credit.BalanceAfter)
+	assert.Equal(t, 25000, depositOf(t, db, user.ID))
+
+	// A debit past zero is refused unless explicitly allowed
+	_, err = walletService.AdjustBalance(model.BalanceAdjustment{UserID: user.ID, AdminID: 1, Amount: -30000, ReasonCode: model.AdjustmentReasonChargeback, Note: "Top up reversed"})
+	assert.ErrorIs(t, err, service.ErrNegativeBalance)
+	assert.Equal(t, 25000, depositOf(t, db, user.ID))
+
+	debit, err := walletService.AdjustBalance(model.BalanceAdjustment{UserID: user.ID, AdminID: 1, Amount: -30000, ReasonCode: model.AdjustmentReasonChargeback, Note: "Top up reversed", AllowNegative: true})
+	assert.NoError(t, err)
+	assert.Equal(t, -5000, debit.BalanceAfter)
+	assert.Equal(t, -5000, depositOf(t, db, user.ID))
+
+	var entries []model.LedgerEntry
+	assert.NoError(t, db.Where("adjustment_id = ?", debit.ID).Order("id").Find(&entries).Error)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, model.LedgerAccountWallet, entries[0].Account)
+	assert.Equal(t, -30000, entries[0].Amount)
+	assert.Equal(t, model.LedgerAccountAdjustment, entries[1].Account)
+	assert.Equal(t, model.LedgerTypeAdjustment, entries[1].Type)
+
+	balance, err := repository.NewLedgerRepository(db).Balance(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, -5000, balance)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWithdrawalNotFound          = errors.New("withdrawal not found")
+	ErrInvalidWithdrawalTransition = errors.New("invalid withdrawal status transition")
+	ErrInvalidWithdrawalAmount     = errors.New("amount must be greater than zero")
+	ErrWithdrawalInProgress        = errors.New("another withdrawal is still open")
+	ErrPayoutRefRequired           = errors.New("a payout reference is required")
+	ErrInvalidWithdrawalStatus     = errors.New("unknown withdrawal status")
+)
+
+var withdrawalStatuses = []string{
+	model.WithdrawalStatusPending, model.WithdrawalStatusApproved, model.WithdrawalStatusPaid, model.WithdrawalStatusRejected,
+}
+
+// withdrawalTransitions lists, for every withdrawal status, the statuses it may move
+// to. Paid and rejected are final.
+var withdrawalTransitions = map[string][]string{
+	model.WithdrawalStatusPending:  {model.WithdrawalStatusApproved, model.WithdrawalStatusRejected},
+	model.WithdrawalStatusApproved: {model.WithdrawalStatusPaid, model.WithdrawalStatusRejected},
+}
+
+func canTransitionWithdrawal(from, to string) bool {
+	for _, next := range withdrawalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WithdrawalPage is one page of withdrawals.
+type WithdrawalPage struct {
+	Withdrawals []model.Withdrawal
+	Total       int64
+	Page        int
+	Limit       int
+}
+
+type WithdrawalService interface {
+	RequestWithdrawal(userID uint, amount int, note string) (model.Withdrawal, error)
+	GetWithdrawals(userID uint, page int, limit int) (WithdrawalPage, error)
+	GetWithdrawal(userID uint, id uint) (model.Withdrawal, error)
+	ListWithdrawals(status string, page int, limit int) (WithdrawalPage, error)
+	ApproveWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error)
+	RejectWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error)
+	MarkWithdrawalPaid(id uint, adminID uint, payoutRef string) (model.Withdrawal, error)
+}
+
+type withdrawalService struct {
+	repo repository.WithdrawalRepository
+	uow  repository.UnitOfWork
+}
+
+func NewWithdrawalService(repo repository.WithdrawalRepository, uow repository.UnitOfWork) WithdrawalService {
+	return &withdrawalService{repo: repo, uow: uow}
+}
+
+// RequestWithdrawal asks for amount of the user's deposit back. Nothing is taken from
+// the balance until an admin approves it, and a user has at most one open request.
+func (s *withdrawalService) RequestWithdrawal(userID uint, amount int, note string) (model.Withdrawal, error) {
+	if amount <= 0 {
+		return model.Withdrawal{}, ErrInvalidWithdrawalAmount
+	}
+
+	var withdrawal model.Withdrawal
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		user, err := repos.User.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+
+		open, err := repos.Withdrawal.CountOpenByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrWithdrawalInProgress
+		}
+		if user.Deposit == nil || *user.Deposit < amount {
+			return ErrInsufficientDeposit
+		}
+
+		withdrawal, err = repos.Withdrawal.Create(model.Withdrawal{
+			UserID: user.ID,
+			Amount: amount,
+			Status: model.WithdrawalStatusPending,
+			Note:   strings.TrimSpace(note),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = repos.Withdrawal.CreateEvent(model.WithdrawalEvent{
+			WithdrawalID: withdrawal.ID,
+			ToStatus:     withdrawal.Status,
+			Reason:       withdrawal.Note,
+			ActorID:      user.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return model.Withdrawal{}, err
+	}
+
+	return s.repo.GetByID(withdrawal.ID)
+}
+
+func (s *withdrawalService) GetWithdrawals(userID uint, page int, limit int) (WithdrawalPage, error) {
+	return s.list(userID, "", page, limit)
+}
+
+// GetWithdrawal returns one withdrawal of a user with its history. Withdrawals of
+// other users are reported as not found.
+func (s *withdrawalService) GetWithdrawal(userID uint, id uint) (model.Withdrawal, error) {
+	withdrawal, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && withdrawal.UserID != userID) {
+		return model.Withdrawal{}, ErrWithdrawalNotFound
+	}
+	return withdrawal, err
+}
+
+func (s *withdrawalService) ListWithdrawals(status string, page int, limit int) (WithdrawalPage, error) {
+	if status != "" && !containsString(withdrawalStatuses, status) {
+		return WithdrawalPage{}, ErrInvalidWithdrawalStatus
+	}
+	return s.list(0, status, page, limit)
+}
+
+func (s *withdrawalService) list(userID uint, status string, page int, limit int) (WithdrawalPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	withdrawals, total, err := s.repo.List(userID, status, page, limit)
+	if err != nil {
+		return WithdrawalPage{}, err
+	}
+	return WithdrawalPage{Withdrawals: withdrawals, Total: total, Page: page, Limit: limit}, nil
+}
+
+// transition moves a locked withdrawal to status, runs apply for the money side of the
+// change and records who made it and why. Lock order is withdrawal, then user.
+func (s *withdrawalService) transition(id uint, adminID uint, reason string, status string,
+	apply func(repos repository.Repositories, withdrawal *model.Withdrawal) error) (model.Withdrawal, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		withdrawal, err := repos.Withdrawal.GetByIDForUpdate(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWithdrawalNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionWithdrawal(withdrawal.Status, status) {
+			return ErrInvalidWithdrawalTransition
+		}
+
+		event := model.WithdrawalEvent{
+			WithdrawalID: withdrawal.ID,
+			FromStatus:   withdrawal.Status,
+			ToStatus:     status,
+			Reason:       reason,
+			ActorID:      adminID,
+		}
+		if err := apply(repos, &withdrawal); err != nil {
+			return err
+		}
+
+		withdrawal.Status = status
+		if _, err := repos.Withdrawal.Update(withdrawal); err != nil {
+			return err
+		}
+		_, err = repos.Withdrawal.CreateEvent(event)
+		return err
+	})
+	if err != nil {
+		return model.Withdrawal{}, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// ApproveWithdrawal reserves the amount: it leaves the user's balance and is held until
+// the payout is recorded or the withdrawal is rejected.
+func (s *withdrawalService) ApproveWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error) {
+	return s.transition(id, adminID, strings.TrimSpace(reason), model.WithdrawalStatusApproved,
+		func(repos repository.Repositories, withdrawal *model.Withdrawal) error {
+			user, err := repos.User.GetByIDForUpdate(withdrawal.UserID)
+			if err != nil {
+				return err
+			}
+			if user.Deposit == nil || *user.Deposit < withdrawal.Amount {
+				return ErrInsufficientDeposit
+			}
+
+			return postToWallet(repos, &user, Posting{
+				Amount:       -withdrawal.Amount,
+				Type:         model.LedgerTypeWithdrawalHold,
+				WithdrawalID: &withdrawal.ID,
+				Description:  "Withdrawal approved",
+			})
+		})
+}
+
+// RejectWithdrawal turns a withdrawal down. A held amount goes back to the balance.
+func (s *withdrawalService) RejectWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.Withdrawal{}, ErrReasonRequired
+	}
+
+	return s.transition(id, adminID, reason, model.WithdrawalStatusRejected,
+		func(repos repository.Repositories, withdrawal *model.Withdrawal) error {
+			if withdrawal.Status != model.WithdrawalStatusApproved {
+				return nil
+			}
+
+			user, err := repos.User.GetByIDForUpdate(withdrawal.UserID)
+			if err != nil {
+				return err
+			}
+			return postToWallet(repos, &user, Posting{
+				Amount:       withdrawal.Amount,
+				Type:         model.LedgerTypeWithdrawalRelease,
+				WithdrawalID: &withdrawal.ID,
+				Description:  "Withdrawal rejected",
+			})
+		})
+}
+
+// MarkWithdrawalPaid records that the held amount was paid out by hand, with the
+// reference of the transfer. The balance was already debited on approval.
+func (s *withdrawalService) MarkWithdrawalPaid(id uint, adminID uint, payoutRef string) (model.Withdrawal, error) {
+	payoutRef = strings.TrimSpace(payoutRef)
+	if payoutRef == "" {
+		return model.Withdrawal{}, ErrPayoutRefRequired
+	}
+
+	return s.transition(id, adminID, payoutRef, model.WithdrawalStatusPaid,
+		func(repos repository.Repositories, withdrawal *model.Withdrawal) error {
+			withdrawal.PayoutRef = payoutRef
+			return postEntries(repos, withdrawal.UserID, model.LedgerAccountCash, model.LedgerAccountWithdrawal, Posting{
+				Amount:       withdrawal.Amount,
+				Type:         model.LedgerTypeWithdrawalPayout,
+				WithdrawalID: &withdrawal.ID,
+				Description:  "Withdrawal paid out, ref " + payoutRef,
+			})
+		})
+}
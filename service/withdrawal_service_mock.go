@@ -0,0 +1,46 @@
+package service
+
+import (
+	"pojok-baca-api/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WithdrawalServiceMock struct {
+	mock.Mock
+}
+
+func (m *WithdrawalServiceMock) RequestWithdrawal(userID uint, amount int, note string) (model.Withdrawal, error) {
+	args := m.Called(userID, amount, note)
+	return args.Get(0).(model.Withdrawal), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) GetWithdrawals(userID uint, page int, limit int) (WithdrawalPage, error) {
+	args := m.Called(userID, page, limit)
+	return args.Get(0).(WithdrawalPage), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) GetWithdrawal(userID uint, id uint) (model.Withdrawal, error) {
+	args := m.Called(userID, id)
+	return args.Get(0).(model.Withdrawal), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) ListWithdrawals(status string, page int, limit int) (WithdrawalPage, error) {
+	args := m.Called(status, page, limit)
+	return args.Get(0).(WithdrawalPage), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) ApproveWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error) {
+	args := m.Called(id, adminID, reason)
+	return args.Get(0).(model.Withdrawal), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) RejectWithdrawal(id uint, adminID uint, reason string) (model.Withdrawal, error) {
+	args := m.Called(id, adminID, reason)
+	return args.Get(0).(model.Withdrawal), args.Error(1)
+}
+
+func (m *WithdrawalServiceMock) MarkWithdrawalPaid(id uint, adminID uint, payoutRef string) (model.Withdrawal, error) {
+	args := m.Called(id, adminID, payoutRef)
+	return args.Get(0).(model.Withdrawal), args.Error(1)
+}
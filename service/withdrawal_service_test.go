@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"pojok-baca-api/model"
+	"pojok-baca-api/repository"
+	"pojok-baca-api/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// seedWallet creates a user whose deposit is backed by an opening balance in the ledger.
+func seedWallet(t *testing.T, db *gorm.DB, deposit int) model.User {
+	t.Helper()
+
+	user := model.User{Name: "Rina", Email: "rina@example.com", Password: "secret", Role: "user", Deposit: &deposit}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	err := repository.NewLedgerRepository(db).Create([]model.LedgerEntry{
+		{TransactionID: "LT-seed", UserID: user.ID, Account: model.LedgerAccountWallet, Amount: deposit, Type: model.LedgerTypeOpeningBalance},
+		{TransactionID: "LT-seed", UserID: user.ID, Account: model.LedgerAccountAdjustment, Amount: -deposit, Type: model.LedgerTypeOpeningBalance},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed ledger: %v", err)
+	}
+	return user
+}
+
+func depositOf(t *testing.T, db *gorm.DB, userID uint) int {
+	t.Helper()
+
+	var user model.User
+	if err := db.First(&user, userID).Error; err != nil {
+		t.Fatalf("failed to read user: %v", err)
+	}
+	return *user.Deposit
+}
+
+func TestWithdrawalService_ApproveAndPay(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.LedgerEntry{}, &model.Withdrawal{}, &model.WithdrawalEvent{})
+	withdrawalService := service.NewWithdrawalService(repository.NewWithdrawalRepository(db), repository.NewUnitOfWork(db))
+	user := seedWallet(t, db, 50000)
+
+	_, err := withdrawalService.RequestWithdrawal(user.ID, 60000, "")
+	assert.ErrorIs(t, err, service.ErrInsufficientDeposit)
+
+	withdrawal, err := withdrawalService.RequestWithdrawal(user.ID, 40000, "Moving out")
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalStatusPending, withdrawal.Status)
+	assert.Equal(t, 50000, depositOf(t, db, user.ID))
+
+	_, err = withdrawalService.RequestWithdrawal(user.ID, 10000, "")
+	assert.ErrorIs(t, err, service.ErrWithdrawalInProgress)
+
+	_, err = withdrawalService.MarkWithdrawalPaid(withdrawal.ID, 1, "TRF-1")
+	assert.ErrorIs(t, err, service.ErrInvalidWithdrawalTransition)
+
+	// Approval reserves the amount
+	withdrawal, err = withdrawalService.ApproveWithdrawal(withdrawal.ID, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalStatusApproved, withdrawal.Status)
+	assert.Equal(t, 10000, depositOf(t, db, user.ID))
+
+	withdrawal, err = withdrawalService.MarkWithdrawalPaid(withdrawal.ID, 1, "TRF-1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalStatusPaid, withdrawal.Status)
+	assert.Equal(t, "TRF-1", withdrawal.PayoutRef)
+	assert.Equal(t, 10000, depositOf(t, db, user.ID))
+
+	statuses := make([]string, 0, len(withdrawal.Events))
+	for _, event := range withdrawal.Events {
+		statuses = append(statuses, event.ToStatus)
+	}
+	assert.Equal(t, []string{model.WithdrawalStatusPending, model.WithdrawalStatusApproved, model.WithdrawalStatusPaid}, statuses)
+
+	// Nothing is left on hold once paid out
+	var held int
+	assert.NoError(t, db.Model(&model.LedgerEntry{}).Where("account = ?", model.LedgerAccountWithdrawal).
+		Select("COALESCE(SUM(amount), 0)").Scan(&held).Error)
+	assert.Equal(t, 0, held)
+}
+
+func TestWithdrawalService_RejectReleasesHold(t *testing.T) {
+	db := setupTestPostgresDB(t, &model.User{}, &model.LedgerEntry{}, &model.Withdrawal{}, &model.WithdrawalEvent{})
+	withdrawalService := service.NewWithdrawalService(repository.NewWithdrawalRepository(db), repository.NewUnitOfWork(db))
+	user := seedWallet(t, db, 50000)
+
+	withdrawal, err := withdrawalService.RequestWithdrawal(user.ID, 50000, "")
+	assert.NoError(t, err)
+	_, err = withdrawalService.ApproveWithdrawal(withdrawal.ID, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, depositOf(t, db, user.ID))
+
+	_, err = withdrawalService.RejectWithdrawal(withdrawal.ID, 1, "  ")
+	assert.ErrorIs(t, err, service.ErrReasonRequired)
+
+	withdrawal, err = withdrawalService.RejectWithdrawal(withdrawal.ID, 1, "Bank account closed")
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalStatusRejected, withdrawal.Status)
+	assert.Equal(t, 50000, depositOf(t, db, user.ID))
+
+	// A new request is possible once the old one is closed
+	_, err = withdrawalService.RequestWithdrawal(user.ID, 50000, "")
+	assert.NoError(t, err)
+}
@@ -7,7 +7,7 @@ export let options = {
 };
 
 export default function () {
-      const res = http.get('https://pojok-baca-api-fb30b0912dab.herokuapp.com/api/products');
+      const res = http.get('https://pojok-baca-api-fb30b0912dab.herokuapp.com/api/products?available=true&sort=newest&page=1&limit=20');
       check(res, {
             'status was 200': (r) => r.status === 200,
       });